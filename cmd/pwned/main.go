@@ -0,0 +1,52 @@
+// Package main provides command to build breached password data file
+// from HIBP "pwned passwords" SHA-1 dataset ordered by hash.
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/qsoulior/auth-server/pkg/log"
+	"github.com/qsoulior/auth-server/pkg/pwned"
+)
+
+// Main function.
+func main() {
+	var inPath, outPath string
+	var minCount uint
+	flag.StringVar(&inPath, "in", "", "text dataset path, stdin is used if empty")
+	flag.StringVar(&outPath, "out", "pwned.bin", "data file path")
+	flag.UintVar(&minCount, "min", 1, "min count of hash to be written")
+	flag.Parse()
+
+	logger := log.NewConsoleLogger()
+
+	var in io.Reader = os.Stdin
+	if inPath != "" {
+		file, err := os.Open(inPath)
+		if err != nil {
+			logger.Fatal("input error: %s", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		logger.Fatal("output error: %s", err)
+	}
+
+	records, err := pwned.Build(out, in, uint32(minCount))
+	if err != nil {
+		out.Close()
+		os.Remove(outPath)
+		logger.Fatal("build error: %s", err)
+	}
+
+	if err := out.Close(); err != nil {
+		logger.Fatal("output error: %s", err)
+	}
+
+	logger.Info("%d hashes written to %s", records, outPath)
+}
@@ -3,17 +3,27 @@ module github.com/qsoulior/auth-server
 go 1.21.1
 
 require (
+	github.com/beevik/etree v1.1.0
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/go-asn1-ber/asn1-ber v1.5.5
 	github.com/go-chi/chi/v5 v5.0.10
+	github.com/go-ldap/ldap/v3 v3.4.6
 	github.com/golang-jwt/jwt/v5 v5.0.0
 	github.com/jackc/pgx/v5 v5.4.3
 	github.com/rs/cors v1.10.0
+	github.com/russellhaering/goxmldsig v1.4.0
 	golang.org/x/crypto v0.13.0
+	golang.org/x/text v0.13.0
 )
 
 require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358 // indirect
+	github.com/google/uuid v1.3.1 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/jackc/puddle/v2 v2.2.1 // indirect
+	github.com/jonboulle/clockwork v0.2.2 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/sync v0.3.0 // indirect
-	golang.org/x/text v0.13.0 // indirect
+	golang.org/x/sys v0.12.0 // indirect
 )
@@ -9,6 +9,7 @@ import (
 	"github.com/qsoulior/auth-server/internal/usecase"
 	"github.com/qsoulior/auth-server/pkg/db"
 	"github.com/qsoulior/auth-server/pkg/log"
+	"github.com/qsoulior/auth-server/pkg/pwned"
 )
 
 // Run initializes application modules and runs server.
@@ -29,24 +30,115 @@ func Run(cfg *Config, logger log.Logger) error {
 	}
 	logger.Info("jwt module initialized")
 
+	// password hasher and policy initialization
+	hasher, err := NewHasher(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init password hasher: %w", err)
+	}
+	// breached passwords are checked only if data file is set
+	var dataset pwned.Dataset
+	if cfg.Pwned.FilePath != "" {
+		dataset, err = pwned.Open(cfg.Pwned.FilePath)
+		if err != nil {
+			return fmt.Errorf("failed to open pwned data file: %w", err)
+		}
+		defer dataset.Close()
+		logger.Info("pwned data file opened")
+	}
+
+	policy, err := NewPolicy(cfg, dataset)
+	if err != nil {
+		return fmt.Errorf("failed to init password policy: %w", err)
+	}
+
+	passwordAge, err := NewPasswordAge(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init password expiry: %w", err)
+	}
+	logger.Info("password hasher and policy initialized")
+
+	// username policy initialization
+	names, err := NewUsernamePolicy(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init username policy: %w", err)
+	}
+	logger.Info("username policy initialized")
+
+	// profile schema initialization
+	schema, err := NewProfileSchema(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init profile schema: %w", err)
+	}
+	logger.Info("profile schema initialized")
+
 	// repositories initialization
 	userRepo := repo.NewUserPostgres(postgres)
 	tokenRepo := repo.NewTokenPostgres(postgres)
 	roleRepo := repo.NewRolePostgres(postgres)
+	assertionRepo := repo.NewAssertionPostgres(postgres)
+	eventRepo := repo.NewEventPostgres(postgres)
+	resetRepo := repo.NewResetPostgres(postgres)
+	totpRepo := repo.NewTOTPPostgres(postgres)
+	challengeRepo := repo.NewChallengePostgres(postgres)
+	credentialRepo := repo.NewCredentialPostgres(postgres)
+	ceremonyRepo := repo.NewCeremonyPostgres(postgres)
+	recoveryRepo := repo.NewRecoveryPostgres(postgres)
+	attemptRepo := repo.NewAttemptPostgres(postgres)
+	historyRepo := repo.NewHistoryPostgres(postgres)
+	magicLinkRepo := repo.NewMagicLinkPostgres(postgres)
 	logger.Info("repositories initialized")
 
+	// credentials of not local users are verified by directory only if ldap url is set
+	var verifier usecase.Verifier = usecase.NewLocalVerifier(userRepo, hasher)
+	if cfg.LDAP.URL != "" {
+		authenticator, err := NewLDAP(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to init ldap module: %w", err)
+		}
+
+		verifier = usecase.NewSourceVerifier(
+			userRepo,
+			verifier,
+			usecase.NewLDAPVerifier(
+				usecase.LDAPRepos{userRepo, roleRepo},
+				usecase.LDAPParams{parseMapping(cfg.LDAP.RoleMapping)},
+				authenticator,
+				names,
+			),
+		)
+		logger.Info("ldap module initialized")
+	}
+
+	// totp module initialization
+	generator, err := NewTOTP(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init totp module: %w", err)
+	}
+	logger.Info("totp module initialized")
+
+	// mail module initialization
+	mailer, err := NewMailer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to init mail module: %w", err)
+	}
+	logger.Info("mail module initialized")
+
 	// use cases initialization
 	userUС, err := usecase.NewUser(
-		usecase.UserRepos{userRepo},
-		usecase.UserParams{cfg.Bcrypt.Cost},
+		usecase.UserRepos{userRepo, tokenRepo, roleRepo, historyRepo, eventRepo},
+		usecase.UserParams{cfg.Password.History, passwordAge, schema, cfg.Deletion.Grace},
+		hasher,
+		policy,
+		names,
+		verifier,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to init user usecase: %w", err)
 	}
 
 	tokenUС, err := usecase.NewToken(
-		usecase.TokenRepos{tokenRepo, roleRepo},
-		usecase.TokenParams{cfg.AT.Age, cfg.RT.Age, cfg.RT.Cap},
+		usecase.TokenRepos{tokenRepo, roleRepo, eventRepo, userRepo},
+		usecase.TokenParams{cfg.AT.Age, cfg.RT.Age, cfg.RT.Cap, cfg.RT.Grace, schema},
 		builder,
 	)
 	if err != nil {
@@ -54,10 +146,146 @@ func Run(cfg *Config, logger log.Logger) error {
 	}
 
 	authUС := usecase.NewAuth(parser)
+
+	roleUC := usecase.NewRole(usecase.RoleRepos{roleRepo, userRepo})
+
+	// webauthn credentials are second factor only if relying party ID is set
+	var mfaCredentialRepo repo.Credential
+	if cfg.WebAuthn.RPID != "" {
+		mfaCredentialRepo = credentialRepo
+	}
+
+	lockoutUC, err := usecase.NewLockout(
+		usecase.LockoutRepos{userRepo, attemptRepo},
+		usecase.LockoutParams{cfg.Lockout.Threshold, cfg.Lockout.Delay, cfg.Lockout.Duration},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init lockout usecase: %w", err)
+	}
+
+	mfaUC, err := usecase.NewMFA(
+		usecase.MFARepos{userRepo, totpRepo, challengeRepo, recoveryRepo, mfaCredentialRepo, attemptRepo},
+		usecase.MFAParams{cfg.MFA.Age, cfg.MFA.Limit, cfg.MFA.Window, cfg.MFA.Secret},
+		generator,
+		lockoutUC,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init mfa usecase: %w", err)
+	}
+
+	// webauthn is enabled only if relying party ID is set
+	var webAuthnUC usecase.WebAuthn
+	if cfg.WebAuthn.RPID != "" {
+		rp, err := NewWebAuthn(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to init webauthn module: %w", err)
+		}
+
+		webAuthnUC, err = usecase.NewWebAuthn(
+			usecase.WebAuthnRepos{userRepo, credentialRepo, ceremonyRepo, challengeRepo},
+			usecase.WebAuthnParams{cfg.WebAuthn.Timeout},
+			rp,
+			mfaUC,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to init webauthn usecase: %w", err)
+		}
+	}
+
+	// TOTP secrets stored before encryption are encrypted on start
+	sealed, err := mfaUC.SealSecrets()
+	if err != nil {
+		return fmt.Errorf("failed to seal totp secrets: %w", err)
+	}
+	if sealed > 0 {
+		logger.Info("%d totp secrets sealed", sealed)
+	}
+
+	// email is enabled only if verify url is set
+	var emailUC usecase.Email
+	if cfg.Email.VerifyURL != "" {
+		secret, err := NewEmailSecret(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to init email secret: %w", err)
+		}
+
+		emailUC, err = usecase.NewEmail(
+			usecase.EmailRepos{userRepo},
+			usecase.EmailParams{cfg.Email.VerifyURL, secret, cfg.Email.Age},
+			mailer,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to init email usecase: %w", err)
+		}
+	}
+
+	// password reset is enabled only if reset url is set
+	var resetUC usecase.Reset
+	if cfg.Reset.URL != "" {
+		resetUC, err = usecase.NewReset(
+			usecase.ResetRepos{userRepo, tokenRepo, resetRepo, historyRepo},
+			usecase.ResetParams{cfg.Reset.URL, cfg.Reset.Age, cfg.Password.History},
+			hasher,
+			policy,
+			usecase.NewAsyncNotifier(usecase.NewMailNotifier(mailer), logger),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to init reset usecase: %w", err)
+		}
+	}
+
+	// magic link login is enabled only if magic link url is set
+	var magicLinkUC usecase.MagicLink
+	if cfg.MagicLink.URL != "" {
+		magicLinkUC, err = usecase.NewMagicLink(
+			usecase.MagicLinkRepos{userRepo, magicLinkRepo, attemptRepo},
+			usecase.MagicLinkParams{cfg.MagicLink.URL, cfg.MagicLink.Age, cfg.MagicLink.Limit, cfg.MagicLink.Window},
+			usecase.NewMailNotifier(mailer),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to init magic link usecase: %w", err)
+		}
+	}
+
+	// saml is enabled only if service provider entity ID is set
+	var samlUC usecase.SAML
+	if cfg.SAML.EntityID != "" {
+		sp, err := NewSAML(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to init saml module: %w", err)
+		}
+
+		samlUC, err = usecase.NewSAML(
+			usecase.SAMLRepos{userRepo, roleRepo, assertionRepo},
+			usecase.SAMLParams{cfg.SAML.RedirectURL, cfg.SAML.NameAttr, cfg.SAML.RoleAttr, parseMapping(cfg.SAML.RoleMapping)},
+			sp,
+			names,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to init saml usecase: %w", err)
+		}
+	}
 	logger.Info("use cases initialized")
 
+	// deleted users purging
+	purger, err := NewPurger(cfg, userUС, logger)
+	if err != nil {
+		return fmt.Errorf("failed to init purger: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go purger.Run(ctx)
+	logger.Info("purger started")
+
+	// trusted proxies parsing
+	proxies, err := NewProxies(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to parse trusted proxies: %w", err)
+	}
+	logger.Info("trusted proxies parsed")
+
 	// server listening
-	server := NewServer(cfg, logger, userUС, tokenUС, authUС)
+	server := NewServer(cfg, logger, proxies, userUС, tokenUС, authUС, roleUC, mfaUC, lockoutUC, webAuthnUC, emailUC, resetUC, magicLinkUC, samlUC)
 	logger.Info("server created with address " + server.Addr)
 	return fmt.Errorf("server down: %w", server.ListenAndServe())
 }
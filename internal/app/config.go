@@ -1,6 +1,8 @@
 package app
 
 import (
+	"strings"
+
 	"github.com/qsoulior/auth-server/pkg/config"
 )
 
@@ -12,14 +14,32 @@ const (
 type (
 	// Config represents app configuration structure.
 	Config struct {
-		Name     string      `env:"APP_NAME" default:"auth"`
-		Env      Environment `env:"APP_ENV" default:"development"`
-		Key      KeyConfig
-		HTTP     HTTPConfig
-		Postgres PostgresConfig
-		AT       ATConfig
-		RT       RTConfig
-		Bcrypt   BcryptConfig
+		Name      string      `env:"APP_NAME" default:"auth"`
+		Env       Environment `env:"APP_ENV" default:"development"`
+		Key       KeyConfig
+		HTTP      HTTPConfig
+		Postgres  PostgresConfig
+		AT        ATConfig
+		RT        RTConfig
+		Hash      HashConfig
+		Bcrypt    BcryptConfig
+		Argon2    Argon2Config
+		Password  PasswordConfig
+		Username  UsernameConfig
+		Profile   ProfileConfig
+		Deletion  DeletionConfig
+		Pwned     PwnedConfig
+		Admin     AdminConfig
+		TOTP      TOTPConfig
+		MFA       MFAConfig
+		Lockout   LockoutConfig
+		WebAuthn  WebAuthnConfig
+		Mail      MailConfig
+		Email     EmailConfig
+		Reset     ResetConfig
+		MagicLink MagicLinkConfig
+		SAML      SAMLConfig
+		LDAP      LDAPConfig
 	}
 
 	Environment string
@@ -33,6 +53,7 @@ type (
 		Host           string   `env:"HTTP_HOST" default:"0.0.0.0"`
 		Port           string   `env:"HTTP_PORT" default:"3000"`
 		AllowedOrigins []string `env:"HTTP_ORIGINS" default:"*"`
+		Proxies        []string `env:"HTTP_PROXIES" default:""`
 	}
 
 	PostgresConfig struct {
@@ -45,13 +66,140 @@ type (
 	}
 
 	RTConfig struct {
-		Cap int `env:"RT_CAP" default:"10"`
-		Age int `env:"RT_AGE" default:"30"`
+		Cap   int `env:"RT_CAP" default:"10"`
+		Age   int `env:"RT_AGE" default:"30"`
+		Grace int `env:"RT_GRACE" default:"10"`
+	}
+
+	HashConfig struct {
+		Alg    string `env:"HASH_ALG" default:"argon2id"`
+		Pepper string `env:"HASH_PEPPER" default:""`
 	}
 
 	BcryptConfig struct {
 		Cost int `env:"BCRYPT_COST" default:"4"`
 	}
+
+	Argon2Config struct {
+		Memory  uint32 `env:"ARGON2_MEMORY" default:"65536"`
+		Time    uint32 `env:"ARGON2_TIME" default:"3"`
+		Threads uint8  `env:"ARGON2_THREADS" default:"2"`
+	}
+
+	PasswordConfig struct {
+		MinLength  int      `env:"PASSWORD_MIN_LENGTH" default:"8"`
+		MaxLength  int      `env:"PASSWORD_MAX_LENGTH" default:"128"`
+		Classes    []string `env:"PASSWORD_CLASSES" default:"lower,upper,digit,special"`
+		MinEntropy float64  `env:"PASSWORD_ENTROPY" default:"0"`
+		Blocklist  []string `env:"PASSWORD_BLOCKLIST" default:""`
+		History    int      `env:"PASSWORD_HISTORY" default:"5"`
+		Expiry     []string `env:"PASSWORD_EXPIRY" default:""`
+	}
+
+	UsernameConfig struct {
+		MinLength int      `env:"USERNAME_MIN_LENGTH" default:"4"`
+		MaxLength int      `env:"USERNAME_MAX_LENGTH" default:"20"`
+		Scripts   []string `env:"USERNAME_SCRIPTS" default:"Latin"`
+		Reserved  []string `env:"USERNAME_RESERVED" default:"admin,administrator,root,system,support"`
+	}
+
+	ProfileConfig struct {
+		Attributes []string `env:"PROFILE_ATTRIBUTES" default:""`
+	}
+
+	DeletionConfig struct {
+		Grace    int `env:"DELETION_GRACE" default:"30"`
+		Interval int `env:"DELETION_INTERVAL" default:"60"`
+	}
+
+	PwnedConfig struct {
+		FilePath string `env:"PWNED_FILE" default:""`
+		Count    int    `env:"PWNED_COUNT" default:"1"`
+	}
+
+	AdminConfig struct {
+		Role string `env:"ADMIN_ROLE" default:"admin"`
+	}
+
+	TOTPConfig struct {
+		Digits int `env:"TOTP_DIGITS" default:"6"`
+		Period int `env:"TOTP_PERIOD" default:"30"`
+		Skew   int `env:"TOTP_SKEW" default:"1"`
+	}
+
+	MFAConfig struct {
+		Age    int    `env:"MFA_AGE" default:"5"`
+		Limit  int    `env:"MFA_LIMIT" default:"10"`
+		Window int    `env:"MFA_WINDOW" default:"15"`
+		Secret string `env:"MFA_SECRET" default:""`
+	}
+
+	LockoutConfig struct {
+		Threshold int `env:"LOCKOUT_THRESHOLD" default:"10"`
+		Delay     int `env:"LOCKOUT_DELAY" default:"1"`
+		Duration  int `env:"LOCKOUT_DURATION" default:"15"`
+	}
+
+	WebAuthnConfig struct {
+		RPID    string   `env:"WEBAUTHN_RP_ID" default:""`
+		RPName  string   `env:"WEBAUTHN_RP_NAME" default:""`
+		Origins []string `env:"WEBAUTHN_ORIGINS" default:""`
+		Timeout int      `env:"WEBAUTHN_TIMEOUT" default:"300"`
+	}
+
+	MailConfig struct {
+		Host     string `env:"MAIL_HOST" default:""`
+		Port     int    `env:"MAIL_PORT" default:"587"`
+		Username string `env:"MAIL_USERNAME" default:""`
+		Password string `env:"MAIL_PASSWORD" default:""`
+		From     string `env:"MAIL_FROM" default:"auth@localhost"`
+		FilePath string `env:"MAIL_FILE" default:""`
+	}
+
+	EmailConfig struct {
+		VerifyURL string `env:"EMAIL_VERIFY_URL" default:""`
+		Secret    string `env:"EMAIL_SECRET" default:""`
+		Age       int    `env:"EMAIL_AGE" default:"24"`
+	}
+
+	ResetConfig struct {
+		URL string `env:"RESET_URL" default:""`
+		Age int    `env:"RESET_AGE" default:"30"`
+	}
+
+	MagicLinkConfig struct {
+		URL    string `env:"MAGIC_LINK_URL" default:""`
+		Age    int    `env:"MAGIC_LINK_AGE" default:"10"`
+		Limit  int    `env:"MAGIC_LINK_LIMIT" default:"5"`
+		Window int    `env:"MAGIC_LINK_WINDOW" default:"60"`
+	}
+
+	SAMLConfig struct {
+		EntityID    string   `env:"SAML_ENTITY_ID" default:""`
+		ACSURL      string   `env:"SAML_ACS_URL" default:""`
+		RedirectURL string   `env:"SAML_REDIRECT_URL" default:""`
+		IDPEntityID string   `env:"SAML_IDP_ENTITY_ID" default:""`
+		IDPSSOURL   string   `env:"SAML_IDP_SSO_URL" default:""`
+		IDPCertPath string   `env:"SAML_IDP_CERT" default:""`
+		NameAttr    string   `env:"SAML_NAME_ATTR" default:""`
+		RoleAttr    string   `env:"SAML_ROLE_ATTR" default:""`
+		RoleMapping []string `env:"SAML_ROLE_MAPPING" default:""`
+	}
+
+	LDAPConfig struct {
+		URL          string   `env:"LDAP_URL" default:""`
+		StartTLS     bool     `env:"LDAP_STARTTLS" default:"false"`
+		BindDN       string   `env:"LDAP_BIND_DN" default:""`
+		BindPassword string   `env:"LDAP_BIND_PASSWORD" default:""`
+		UserDN       string   `env:"LDAP_USER_DN" default:""`
+		UserBase     string   `env:"LDAP_USER_BASE" default:""`
+		UserFilter   string   `env:"LDAP_USER_FILTER" default:"(uid=%s)"`
+		NameAttr     string   `env:"LDAP_NAME_ATTR" default:"uid"`
+		GroupBase    string   `env:"LDAP_GROUP_BASE" default:""`
+		GroupFilter  string   `env:"LDAP_GROUP_FILTER" default:"(member=%s)"`
+		GroupAttr    string   `env:"LDAP_GROUP_ATTR" default:"cn"`
+		RoleMapping  []string `env:"LDAP_ROLE_MAPPING" default:""`
+	}
 )
 
 // NewConfig reads variables from file or environment
@@ -70,3 +218,30 @@ func NewConfig(path string) (*Config, error) {
 	}
 	return cfg, nil
 }
+
+// parseMapping parses entries in "value:title" format
+// and skips entries without separator.
+// It returns map of external values to role titles.
+func parseMapping(entries []string) map[string]string {
+	mapping := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, ":")
+		if i < 1 || i == len(entry)-1 {
+			continue
+		}
+		mapping[entry[:i]] = entry[i+1:]
+	}
+	return mapping
+}
+
+// parseList skips empty entries of list.
+// It returns slice of non-empty entries.
+func parseList(entries []string) []string {
+	var list []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"github.com/qsoulior/auth-server/pkg/ldap"
+)
+
+// NewLDAP creates LDAP authenticator.
+// It returns error if configuration is incorrect.
+func NewLDAP(cfg *Config) (ldap.Authenticator, error) {
+	return ldap.NewAuthenticator(ldap.Params{
+		URL:          cfg.LDAP.URL,
+		StartTLS:     cfg.LDAP.StartTLS,
+		BindDN:       cfg.LDAP.BindDN,
+		BindPassword: cfg.LDAP.BindPassword,
+		UserDN:       cfg.LDAP.UserDN,
+		UserBase:     cfg.LDAP.UserBase,
+		UserFilter:   cfg.LDAP.UserFilter,
+		NameAttr:     cfg.LDAP.NameAttr,
+		GroupBase:    cfg.LDAP.GroupBase,
+		GroupFilter:  cfg.LDAP.GroupFilter,
+		GroupAttr:    cfg.LDAP.GroupAttr,
+	})
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+
+	"github.com/qsoulior/auth-server/pkg/mail"
+)
+
+// NewMailer creates SMTP mailer if mail host is set.
+// Otherwise, it creates mailer writing messages to file or stdout.
+// It returns error if file open failed or configuration is incorrect.
+func NewMailer(cfg *Config) (mail.Mailer, error) {
+	if cfg.Mail.Host != "" {
+		return mail.NewSMTP(mail.SMTPParams{
+			Host:     cfg.Mail.Host,
+			Port:     cfg.Mail.Port,
+			Username: cfg.Mail.Username,
+			Password: cfg.Mail.Password,
+			From:     cfg.Mail.From,
+		})
+	}
+
+	if cfg.Mail.FilePath == "" {
+		return mail.NewWriter(os.Stdout, cfg.Mail.From), nil
+	}
+
+	file, err := os.OpenFile(cfg.Mail.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("mail file: %w", err)
+	}
+
+	return mail.NewWriter(file, cfg.Mail.From), nil
+}
+
+// NewEmailSecret returns secret used to sign email links.
+// If secret isn't set, random secret is generated,
+// so links become invalid after restart.
+func NewEmailSecret(cfg *Config) ([]byte, error) {
+	if cfg.Email.Secret != "" {
+		return []byte(cfg.Email.Secret), nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/qsoulior/auth-server/pkg/password"
+	"github.com/qsoulior/auth-server/pkg/pwned"
+)
+
+// NewHasher creates password hasher using configured algorithm.
+// Pepper is disabled if it isn't set.
+// It returns error if configuration is incorrect.
+func NewHasher(cfg *Config) (password.Hasher, error) {
+	return password.NewHasher(password.Params{
+		Algorithm: cfg.Hash.Alg,
+		Cost:      cfg.Bcrypt.Cost,
+		Memory:    cfg.Argon2.Memory,
+		Time:      cfg.Argon2.Time,
+		Threads:   cfg.Argon2.Threads,
+		Pepper:    []byte(cfg.Hash.Pepper),
+	})
+}
+
+// NewPolicy creates password policy.
+// Empty entries of classes and blocklist are skipped.
+// Breached passwords are rejected only if dataset isn't nil.
+// It returns error if configuration is incorrect.
+func NewPolicy(cfg *Config, dataset pwned.Dataset) (password.Policy, error) {
+	return password.NewPolicy(password.PolicyParams{
+		MinLength:   cfg.Password.MinLength,
+		MaxLength:   cfg.Password.MaxLength,
+		Classes:     parseList(cfg.Password.Classes),
+		MinEntropy:  cfg.Password.MinEntropy,
+		Blocklist:   parseList(cfg.Password.Blocklist),
+		BreachCount: cfg.Pwned.Count,
+	}, dataset)
+}
+
+// NewPasswordAge parses password expiry entries in "role:days" format,
+// "*" role applies to all users.
+// It returns map of role titles to password ages in days
+// or error if number of days isn't integer.
+func NewPasswordAge(cfg *Config) (map[string]int, error) {
+	mapping := parseMapping(cfg.Password.Expiry)
+	ages := make(map[string]int, len(mapping))
+	for title, value := range mapping {
+		age, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("password expiry of %q is invalid: %w", title, err)
+		}
+		ages[title] = age
+	}
+	return ages, nil
+}
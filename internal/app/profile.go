@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/qsoulior/auth-server/pkg/profile"
+)
+
+// defaultAttributeLength is max length of string attribute if it isn't set.
+const defaultAttributeLength = 256
+
+// NewProfileSchema parses profile attribute entries in
+// "name:type[:max_length][:required][:editable][:claim]" format.
+// Empty entries are skipped, max length of string attributes is 256 by default.
+// It returns attribute schema or error if configuration is incorrect.
+func NewProfileSchema(cfg *Config) (profile.Schema, error) {
+	schema := make(profile.Schema)
+	for _, entry := range parseList(cfg.Profile.Attributes) {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("profile attribute %q has no type", entry)
+		}
+
+		name := parts[0]
+		if _, ok := schema[name]; ok {
+			return nil, fmt.Errorf("profile attribute %q is duplicated", name)
+		}
+
+		field := profile.Field{Type: parts[1]}
+		if field.Type == profile.TypeString || field.Type == profile.TypeURL {
+			field.MaxLength = defaultAttributeLength
+		}
+
+		for i, option := range parts[2:] {
+			switch option {
+			case "required":
+				field.Required = true
+			case "editable":
+				field.Editable = true
+			case "claim":
+				field.Claim = true
+			default:
+				length, err := strconv.Atoi(option)
+				if err != nil || i != 0 {
+					return nil, fmt.Errorf("profile attribute %q has unknown option %q", name, option)
+				}
+				field.MaxLength = length
+			}
+		}
+
+		schema[name] = field
+	}
+
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/usecase"
+	"github.com/qsoulior/auth-server/pkg/log"
+)
+
+// Purger represents background job that deletes users
+// whose deletion grace period has passed.
+type Purger struct {
+	userUC   usecase.User
+	interval time.Duration
+	logger   log.Logger
+}
+
+// NewPurger creates a new Purger running every DELETION_INTERVAL minutes.
+// It returns pointer to a Purger instance or error if interval is out of range [1,1440].
+func NewPurger(cfg *Config, userUC usecase.User, logger log.Logger) (*Purger, error) {
+	if cfg.Deletion.Interval < 1 || cfg.Deletion.Interval > 1440 {
+		return nil, errors.New("deletion interval is out of allowed range [1,1440]")
+	}
+
+	return &Purger{userUC, time.Duration(cfg.Deletion.Interval) * time.Minute, logger}, nil
+}
+
+// Run purges users immediately and then once per interval until ctx is done.
+// Errors are logged, so that the next run can retry.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.userUC.Purge()
+		if err != nil {
+			p.logger.Error("failed to purge deleted users: %s", err)
+		} else if n > 0 {
+			p.logger.Info("%d deleted users purged", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/qsoulior/auth-server/pkg/saml"
+)
+
+// NewSAML reads identity provider certificate and creates SAML service provider.
+// It returns error if certificate read failed or configuration is incorrect.
+func NewSAML(cfg *Config) (saml.ServiceProvider, error) {
+	cert, err := saml.ReadCertificate(cfg.SAML.IDPCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("idp certificate: %w", err)
+	}
+
+	sp, err := saml.NewServiceProvider(saml.Params{
+		EntityID:       cfg.SAML.EntityID,
+		ACSURL:         cfg.SAML.ACSURL,
+		IDPEntityID:    cfg.SAML.IDPEntityID,
+		IDPSSOURL:      cfg.SAML.IDPSSOURL,
+		IDPCertificate: cert,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service provider: %w", err)
+	}
+
+	return sp, nil
+}
@@ -2,10 +2,11 @@ package app
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	api "github.com/qsoulior/auth-server/internal/controller/http"
 	v1 "github.com/qsoulior/auth-server/internal/controller/http/v1"
 	"github.com/qsoulior/auth-server/internal/usecase"
@@ -13,27 +14,56 @@ import (
 	"github.com/rs/cors"
 )
 
+// NewProxies parses addresses and networks of trusted proxies in CIDR notation,
+// single address is parsed as network containing only this address.
+// It returns slice of networks or error if any entry is invalid.
+func NewProxies(cfg *Config) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, entry := range parseList(cfg.HTTP.Proxies) {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy network %q", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
 // NewServer creates mux and http.Server instance, appends middlewares and mounts controllers.
+// Forwarded client IP is trusted only if request is received from one of proxies.
 // It returns pointer to a http.Server instance.
-func NewServer(cfg *Config, logger log.Logger, user usecase.User, token usecase.Token, auth usecase.Auth) *http.Server {
+func NewServer(cfg *Config, logger log.Logger, proxies []*net.IPNet, user usecase.User, token usecase.Token, auth usecase.Auth, role usecase.Role, mfa usecase.MFA, lockout usecase.Lockout, webAuthn usecase.WebAuthn, email usecase.Email, reset usecase.Reset, magicLink usecase.MagicLink, saml usecase.SAML) *http.Server {
 	mux := chi.NewMux()
 
-	mux.Use(middleware.RealIP)
+	mux.Use(api.RealIPMiddleware(proxies))
 	mux.Use(api.LoggerMiddleware(logger))
 	mux.Use(api.RecovererMiddleware(logger))
 	c := cors.New(cors.Options{
 		AllowedOrigins:   cfg.HTTP.AllowedOrigins,
-		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: true,
 	})
 	mux.Use(c.Handler)
-	mux.Use(api.ContentTypeMiddleware("application/json"))
+	mux.Use(api.ContentTypeMiddleware("application/json", "application/x-www-form-urlencoded"))
 
 	mux.NotFound(api.NotFound)
 	mux.MethodNotAllowed(api.MethodNotAllowed)
 
-	mux.Mount("/v1", v1.Mux(user, token, auth, logger))
+	mux.Mount("/v1", v1.Mux(user, token, auth, role, mfa, lockout, webAuthn, email, reset, magicLink, saml, cfg.Admin.Role, logger))
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf("%s:%s", cfg.HTTP.Host, cfg.HTTP.Port),
@@ -0,0 +1,19 @@
+package app
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/totp"
+)
+
+// NewTOTP creates one-time password generator
+// using application name as issuer.
+// It returns error if configuration is incorrect.
+func NewTOTP(cfg *Config) (totp.Generator, error) {
+	return totp.NewGenerator(totp.Params{
+		Issuer: cfg.Name,
+		Digits: cfg.TOTP.Digits,
+		Period: time.Duration(cfg.TOTP.Period) * time.Second,
+		Skew:   cfg.TOTP.Skew,
+	})
+}
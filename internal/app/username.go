@@ -0,0 +1,17 @@
+package app
+
+import (
+	"github.com/qsoulior/auth-server/pkg/username"
+)
+
+// NewUsernamePolicy creates username policy.
+// Empty entries of scripts and reserved names are skipped.
+// It returns error if configuration is incorrect.
+func NewUsernamePolicy(cfg *Config) (username.Policy, error) {
+	return username.NewPolicy(username.Params{
+		MinLength: cfg.Username.MinLength,
+		MaxLength: cfg.Username.MaxLength,
+		Scripts:   parseList(cfg.Username.Scripts),
+		Reserved:  parseList(cfg.Username.Reserved),
+	})
+}
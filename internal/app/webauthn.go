@@ -0,0 +1,30 @@
+package app
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/webauthn"
+)
+
+// NewWebAuthn creates WebAuthn relying party.
+// Application name is used if relying party name isn't set
+// and https origin of relying party ID is used if origins aren't set.
+// It returns error if configuration is incorrect.
+func NewWebAuthn(cfg *Config) (webauthn.RelyingParty, error) {
+	name := cfg.WebAuthn.RPName
+	if name == "" {
+		name = cfg.Name
+	}
+
+	origins := parseList(cfg.WebAuthn.Origins)
+	if len(origins) == 0 {
+		origins = []string{"https://" + cfg.WebAuthn.RPID}
+	}
+
+	return webauthn.NewRelyingParty(webauthn.Params{
+		RPID:    cfg.WebAuthn.RPID,
+		RPName:  name,
+		Origins: origins,
+		Timeout: time.Duration(cfg.WebAuthn.Timeout) * time.Second,
+	})
+}
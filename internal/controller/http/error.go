@@ -20,6 +20,32 @@ func ErrorJSON(w http.ResponseWriter, error string, code int) {
 	})
 }
 
+// RulesErrorJSON writes error with names of failed rules
+// in JSON format and status code to response.
+func RulesErrorJSON(w http.ResponseWriter, error string, rules []string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"status": http.StatusText(code),
+		"error":  error,
+		"rules":  rules,
+	})
+}
+
+// FieldsErrorJSON writes error with reasons of invalid fields
+// in JSON format and status code to response.
+func FieldsErrorJSON(w http.ResponseWriter, error string, fields map[string]string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"status": http.StatusText(code),
+		"error":  error,
+		"fields": fields,
+	})
+}
+
 // MethodNotAllowed writes MethodNotAllowed error to response.
 func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
 	ErrorJSON(w, r.Method+" not allowed", http.StatusMethodNotAllowed)
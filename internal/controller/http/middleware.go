@@ -2,6 +2,7 @@
 package http
 
 import (
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -44,6 +45,60 @@ func ContentTypeMiddleware(contentTypes ...string) Middleware {
 	}
 }
 
+// trusted reports whether ip belongs to one of proxies networks.
+func trusted(proxies []*net.IPNet, ip net.IP) bool {
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedIP reads client IP from X-Forwarded-For header from right to left
+// skipping trusted proxies, so that client cannot spoof its address
+// by adding entries, or from X-Real-IP header if X-Forwarded-For isn't set.
+// It returns IP string or empty string if headers are invalid.
+func forwardedIP(header http.Header, proxies []*net.IPNet) string {
+	entries := strings.Split(strings.Join(header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(entries) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(entries[i]))
+		if ip == nil {
+			break
+		}
+		if !trusted(proxies, ip) || i == 0 {
+			return ip.String()
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// RealIPMiddleware creates a middleware that sets request's remote address
+// to client IP from forwarded headers only if request is received from
+// one of trusted proxies, otherwise peer address is left as is.
+// It returns Middleware instance.
+func RealIPMiddleware(proxies []*net.IPNet) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+
+			if ip := net.ParseIP(host); ip != nil && trusted(proxies, ip) {
+				if client := forwardedIP(r.Header, proxies); client != "" {
+					r.RemoteAddr = client
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // loggerWriter is http.ResponseWriter wrapper that stores
 // response size and HTTP status code.
 type loggerWriter struct {
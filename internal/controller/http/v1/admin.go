@@ -0,0 +1,364 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/usecase"
+)
+
+// admin represents controllers grouped by admin route.
+type admin struct {
+	userUC    usecase.User
+	mfaUC     usecase.MFA
+	lockoutUC usecase.Lockout
+}
+
+// ListUsers reads filter and cursor from URL query
+// and calls User.List to get page of users.
+func (a *admin) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := usecase.UserFilter{
+		NamePrefix: query.Get("name"),
+		Role:       query.Get("role"),
+		Status:     query.Get("status"),
+		Cursor:     query.Get("cursor"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			api.ErrorJSON(w, usecase.ErrLimitInvalid.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	users, cursor, err := a.userUC.List(filter)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	items := make([]map[string]any, len(users))
+	for i, user := range users {
+		items[i] = map[string]any{
+			"id":       user.ID,
+			"username": user.Name,
+			"status":   user.Status,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"users":       items,
+		"next_cursor": cursor,
+	})
+}
+
+// GetUser reads user ID from URL
+// and calls User.Get to get user data by ID.
+func (a *admin) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := a.userUC.Get(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"id":                   user.ID,
+		"username":             user.Name,
+		"email":                user.Email,
+		"email_verified":       user.EmailVerified,
+		"password_changed_at":  user.PasswordChangedAt,
+		"must_change_password": user.MustChangePassword,
+		"attributes":           user.Attributes,
+		"status":               user.Status,
+		"status_reason":        user.StatusReason,
+		"suspended_until":      user.SuspendedUntil,
+		"deleted_at":           user.DeletedAt,
+	})
+}
+
+// UpdateUser reads user ID from URL and name from request's body,
+// then calls User.UpdateName to update user's name by ID.
+func (a *admin) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = a.userUC.UpdateName(userID, body.Name)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateProfile reads user ID from URL and attributes patch from request's body,
+// then calls User.ForceUpdateProfile to update user's attributes by ID.
+func (a *admin) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var patch map[string]any
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&patch)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	attributes, err := a.userUC.ForceUpdateProfile(userID, patch)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			attributesErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"attributes": attributes,
+	})
+}
+
+// ResetPassword reads user ID from URL and new password from request's body,
+// then calls User.ResetPassword to update user's password by ID.
+func (a *admin) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		NewPassword string `json:"new_password"`
+	}
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = a.userUC.ResetPassword(userID, []byte(body.NewPassword))
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			passwordErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExpirePassword reads user ID from URL and calls User.ExpirePassword
+// to require user to change password on next login.
+func (a *admin) ExpirePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = a.userUC.ExpirePassword(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateStatus reads user ID from URL and status, end of suspension and reason
+// from request's body, then calls User.UpdateStatus to change user's status by ID.
+func (a *admin) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Status string     `json:"status"`
+		Until  *time.Time `json:"until"`
+		Reason string     `json:"reason"`
+	}
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = a.userUC.UpdateStatus(userID, body.Status, body.Until, body.Reason)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetMFA reads user ID from URL
+// and calls MFA.Reset to disable user's MFA.
+func (a *admin) ResetMFA(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = a.mfaUC.Reset(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unlock reads user ID from URL
+// and calls Lockout.Unlock to forget failed login attempts of user.
+func (a *admin) Unlock(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = a.lockoutUC.Unlock(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreUser reads user ID from URL
+// and calls User.ForceRestore to cancel user's deletion request.
+func (a *admin) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = a.userUC.ForceRestore(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUser reads user ID from URL and calls User.ForceDelete
+// to mark user as pending deletion by ID or User.ForcePurge
+// to delete user immediately if purge query parameter is true.
+func (a *admin) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("purge") == "true" {
+		err = a.userUC.ForcePurge(userID)
+	} else {
+		err = a.userUC.ForceDelete(userID)
+	}
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrUserNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
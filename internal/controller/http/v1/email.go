@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/usecase"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// email represents controllers grouped by user email route.
+type email struct {
+	emailUC usecase.Email
+}
+
+// Update gets user ID from request's context and email
+// from request's body, then calls Email.Update to update user's email
+// and send verification link.
+func (e *email) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = e.emailUC.Update(userID, body.Email)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Verify reads token from request's body
+// and calls Email.Verify to mark email as verified.
+func (e *email) Verify(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = e.emailUC.Verify(body.Token)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/usecase"
+)
+
+// magicLink represents controllers grouped by magic link route.
+type magicLink struct {
+	magicLinkUC usecase.MagicLink
+	userUC      usecase.User
+	tokenUC     usecase.Token
+	mfaUC       usecase.MFA
+}
+
+// Send reads email and fingerprint from request
+// and calls MagicLink.Send to send login link.
+// Response doesn't reveal whether user exists.
+func (m *magicLink) Send(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	retryAfter, err := m.magicLinkUC.Send(body.Email, readFingerprint(r))
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrTooManyRequests {
+				writeRetryAfter(w, retryAfter)
+				api.ErrorJSON(w, e.Err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Consume reads token and fingerprint from request, calls MagicLink.Consume
+// to verify login link and Token.Create to create new access and refresh tokens.
+// If user has enabled MFA, challenge is returned instead of tokens.
+// If user's password is expired, restricted access token is returned instead.
+func (m *magicLink) Consume(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token   string `json:"token"`
+		Session bool   `json:"session"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	fingerprint := readFingerprint(r)
+
+	userID, err := m.magicLinkUC.Consume(body.Token, fingerprint)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	issueTokens(w, m.userUC, m.tokenUC, m.mfaUC, userID, fingerprint, body.Session)
+}
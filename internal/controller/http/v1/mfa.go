@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/usecase"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// mfa represents controllers grouped by user MFA route.
+type mfa struct {
+	mfaUC usecase.MFA
+}
+
+// readCode reads one-time code from request's body.
+// It returns error if body is invalid.
+func readCode(r *http.Request) (string, error) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	d := json.NewDecoder(r.Body)
+	if err := d.Decode(&body); err != nil {
+		return "", err
+	}
+
+	return body.Code, nil
+}
+
+// Enroll gets user ID from request's context
+// and calls MFA.Enroll to generate a new TOTP secret.
+func (m *mfa) Enroll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	secret, uri, err := m.mfaUC.Enroll(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"secret": secret,
+		"uri":    uri,
+	})
+}
+
+// Confirm gets user ID from request's context and code from request's body,
+// then calls MFA.Confirm to enable MFA.
+func (m *mfa) Confirm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	code, err := readCode(r)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = m.mfaUC.Confirm(userID, code)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GenerateRecovery gets user ID from request's context
+// and calls MFA.GenerateRecovery to replace recovery codes with a new set.
+func (m *mfa) GenerateRecovery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	codes, err := m.mfaUC.GenerateRecovery(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"codes": codes,
+	})
+}
+
+// Disable gets user ID from request's context and code from request's body,
+// then calls MFA.Disable to disable MFA.
+func (m *mfa) Disable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	code, err := readCode(r)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = m.mfaUC.Disable(userID, code)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -10,6 +10,7 @@ import (
 )
 
 // AuthMiddleware creates a middleware that verifies access token.
+// Restricted access tokens are rejected.
 // It returns api.Middleware instance.
 func AuthMiddleware(auth usecase.Auth, logger log.Logger) api.Middleware {
 	return func(next http.Handler) http.Handler {
@@ -23,6 +24,10 @@ func AuthMiddleware(auth usecase.Auth, logger log.Logger) api.Middleware {
 			userID, roleTitles, err := auth.Verify(token, fingerprint)
 			if err != nil {
 				api.HandleError(err, func(e *usecase.Error) {
+					if e.Err == usecase.ErrTokenRestricted {
+						api.ErrorJSON(w, e.Err.Error(), http.StatusForbidden)
+						return
+					}
 					api.ErrorJSON(w, e.Err.Error(), http.StatusUnauthorized)
 				})
 				return
@@ -33,3 +38,50 @@ func AuthMiddleware(auth usecase.Auth, logger log.Logger) api.Middleware {
 		})
 	}
 }
+
+// ScopeMiddleware creates a middleware that verifies access token
+// which is unrestricted or restricted to scope.
+// It returns api.Middleware instance.
+func ScopeMiddleware(auth usecase.Auth, scope string) api.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := readAccessToken(r)
+			if err != nil {
+				api.ErrorJSON(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			fingerprint := readFingerprint(r)
+			userID, err := auth.VerifyScope(token, fingerprint, scope)
+			if err != nil {
+				api.HandleError(err, func(e *usecase.Error) {
+					if e.Err == usecase.ErrTokenRestricted {
+						api.ErrorJSON(w, e.Err.Error(), http.StatusForbidden)
+						return
+					}
+					api.ErrorJSON(w, e.Err.Error(), http.StatusUnauthorized)
+				})
+				return
+			}
+			ctx := context.WithValue(r.Context(), "userID", userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RoleMiddleware creates a middleware that verifies user has a role with title.
+// It must be used after AuthMiddleware that stores user's roles in context.
+// It returns api.Middleware instance.
+func RoleMiddleware(title string) api.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			roleTitles, _ := r.Context().Value("roleTitles").([]string)
+			for _, roleTitle := range roleTitles {
+				if roleTitle == title {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			api.ErrorJSON(w, "role is required", http.StatusForbidden)
+		})
+	}
+}
@@ -5,23 +5,39 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	api "github.com/qsoulior/auth-server/internal/controller/http"
 	"github.com/qsoulior/auth-server/internal/entity"
 	"github.com/qsoulior/auth-server/internal/usecase"
 	"github.com/qsoulior/auth-server/pkg/log"
-	"github.com/qsoulior/auth-server/pkg/uuid"
 )
 
 // Mux creates a new mux and mounts controllers.
+// Role and admin controllers are available only to users with adminRole.
+// WebAuthn, email, password reset, magic link and SAML controllers are mounted
+// only if webAuthnUC, emailUC, resetUC, magicLinkUC and samlUC aren't nil.
 // It returns pointer to a chi.Mux instance.
-func Mux(userUC usecase.User, tokenUC usecase.Token, authUC usecase.Auth, logger log.Logger) http.Handler {
-	user := user{userUC}
-	token := token{userUC, tokenUC}
+func Mux(userUC usecase.User, tokenUC usecase.Token, authUC usecase.Auth, roleUC usecase.Role, mfaUC usecase.MFA, lockoutUC usecase.Lockout, webAuthnUC usecase.WebAuthn, emailUC usecase.Email, resetUC usecase.Reset, magicLinkUC usecase.MagicLink, samlUC usecase.SAML, adminRole string, logger log.Logger) http.Handler {
+	user := user{userUC, mfaUC, lockoutUC}
+	token := token{userUC, tokenUC, mfaUC, lockoutUC}
+	role := role{roleUC}
+	admin := admin{userUC, mfaUC, lockoutUC}
+	mfa := mfa{mfaUC}
+	webAuthn := webAuthn{webAuthnUC, userUC, tokenUC}
+	email := email{emailUC}
+	password := password{userUC, resetUC}
+	magicLink := magicLink{magicLinkUC, userUC, tokenUC, mfaUC}
+	saml := saml{samlUC, userUC, tokenUC, mfaUC}
 	auth := AuthMiddleware(authUC, logger)
+	passwordScope := ScopeMiddleware(authUC, entity.ScopePassword)
+	adminOnly := RoleMiddleware(adminRole)
 
 	mux := chi.NewMux()
 	mux.Route("/", func(r chi.Router) {
@@ -29,14 +45,85 @@ func Mux(userUC usecase.User, tokenUC usecase.Token, authUC usecase.Auth, logger
 			r.Post("/", user.Create)
 			r.With(auth).Get("/", user.Get)
 			r.With(auth).Delete("/", user.Delete)
-			r.With(auth).Put("/password", user.UpdatePassword)
+			r.Post("/restore", user.Restore)
+			r.With(auth).Get("/export", user.Export)
+			r.With(passwordScope).Put("/password", user.UpdatePassword)
+			r.With(auth).Put("/name", user.UpdateName)
+			r.With(auth).Patch("/profile", user.UpdateProfile)
+			r.With(auth).Post("/mfa", mfa.Enroll)
+			r.With(auth).Post("/mfa/confirm", mfa.Confirm)
+			r.With(auth).Post("/mfa/recovery", mfa.GenerateRecovery)
+			r.With(auth).Delete("/mfa", mfa.Disable)
+			if webAuthnUC != nil {
+				r.With(auth).Get("/webauthn", webAuthn.ListCredentials)
+				r.With(auth).Post("/webauthn/register/begin", webAuthn.BeginRegistration)
+				r.With(auth).Post("/webauthn/register/finish", webAuthn.FinishRegistration)
+				r.With(auth).Delete("/webauthn/{credentialID}", webAuthn.DeleteCredential)
+			}
+			if emailUC != nil {
+				r.With(auth).Put("/email", email.Update)
+				r.Post("/email/verify", email.Verify)
+			}
+		})
+		r.Route("/password", func(r chi.Router) {
+			r.Post("/strength", password.Strength)
+			if resetUC != nil {
+				r.Post("/forgot", password.Forgot)
+				r.Post("/reset", password.Reset)
+			}
 		})
 		r.Route("/token", func(r chi.Router) {
 			r.Post("/", token.Create)
+			r.Post("/mfa", token.CompleteMFA)
 			r.Post("/refresh", token.Refresh)
 			r.Post("/revoke", token.Revoke)
 			r.Post("/revoke-all", token.RevokeAll)
+			if webAuthnUC != nil {
+				r.Post("/webauthn/begin", webAuthn.BeginLogin)
+				r.Post("/webauthn/finish", webAuthn.FinishLogin)
+			}
+			if magicLinkUC != nil {
+				r.Post("/magic-link", magicLink.Send)
+				r.Post("/magic-link/consume", magicLink.Consume)
+			}
+		})
+		r.Route("/roles", func(r chi.Router) {
+			r.Use(auth, adminOnly)
+			r.Get("/", role.List)
+			r.Post("/", role.Create)
+			r.Get("/{roleID}", role.Get)
+			r.Put("/{roleID}", role.Update)
+			r.Delete("/{roleID}", role.Delete)
+			r.Get("/{roleID}/users", role.ListUsers)
+		})
+		r.Route("/users/{userID}/roles", func(r chi.Router) {
+			r.Use(auth, adminOnly)
+			r.Get("/", role.ListByUser)
+			r.Put("/{roleID}", role.Assign)
+			r.Delete("/{roleID}", role.Unassign)
 		})
+		r.Route("/admin/users", func(r chi.Router) {
+			r.Use(auth, adminOnly)
+			r.Get("/", admin.ListUsers)
+			r.Get("/{userID}", admin.GetUser)
+			r.Put("/{userID}", admin.UpdateUser)
+			r.Patch("/{userID}/profile", admin.UpdateProfile)
+			r.Delete("/{userID}", admin.DeleteUser)
+			r.Post("/{userID}/restore", admin.RestoreUser)
+			r.Put("/{userID}/password", admin.ResetPassword)
+			r.Delete("/{userID}/mfa", admin.ResetMFA)
+			r.Delete("/{userID}/lockout", admin.Unlock)
+			r.Post("/{userID}/password/expire", admin.ExpirePassword)
+			r.Put("/{userID}/status", admin.UpdateStatus)
+		})
+		if samlUC != nil {
+			r.Route("/saml", func(r chi.Router) {
+				r.Get("/metadata", saml.Metadata)
+				r.Get("/login", saml.Login)
+				r.Post("/acs", saml.ACS)
+				r.Post("/token", saml.Token)
+			})
+		}
 	})
 
 	return mux
@@ -55,21 +142,18 @@ func readAccessToken(r *http.Request) (entity.AccessToken, error) {
 
 // readRefreshToken reads refresh token from request's cookie.
 // It returns error if cookie is empty.
-func readRefreshToken(r *http.Request) (uuid.UUID, error) {
-	var (
-		data  string
-		token uuid.UUID
-	)
+func readRefreshToken(r *http.Request) (string, error) {
+	var token string
 
 	if cookie, err := r.Cookie("refresh_token"); err != http.ErrNoCookie {
-		data = cookie.Value
+		token = cookie.Value
 	}
 
-	if data == "" {
-		return token, errors.New("token is empty")
+	if token == "" {
+		return "", errors.New("token is empty")
 	}
 
-	return uuid.FromString(data)
+	return token, nil
 }
 
 // readFingerprint reads headers from request and creates fingerprint using them.
@@ -78,6 +162,73 @@ func readFingerprint(r *http.Request) []byte {
 	return []byte(fmt.Sprintf("%s:%s:%s:%s", r.Header.Get("Sec-CH-UA"), r.Header.Get("User-Agent"), r.Header.Get("Accept-Language"), r.Header.Get("Upgrade-Insecure-Requests")))
 }
 
+// passwordErrorJSON writes use case error and status code to response.
+// Password policy error is written with names of failed rules.
+func passwordErrorJSON(w http.ResponseWriter, e *usecase.Error, code int) {
+	var pErr *usecase.PasswordError
+	if errors.As(e.Err, &pErr) {
+		api.RulesErrorJSON(w, pErr.Error(), pErr.Rules, code)
+		return
+	}
+	api.ErrorJSON(w, e.Err.Error(), code)
+}
+
+// attributesErrorJSON writes use case error and status code to response.
+// Attributes error is written with reasons of invalid attributes,
+// password policy error is written with names of failed rules.
+func attributesErrorJSON(w http.ResponseWriter, e *usecase.Error, code int) {
+	var aErr *usecase.AttributesError
+	if errors.As(e.Err, &aErr) {
+		api.FieldsErrorJSON(w, aErr.Error(), aErr.Fields, code)
+		return
+	}
+	passwordErrorJSON(w, e, code)
+}
+
+// blocked reports whether use case error is caused by user's status.
+func blocked(e *usecase.Error) bool {
+	return e.Err == usecase.ErrUserDisabled || e.Err == usecase.ErrUserSuspended || e.Err == usecase.ErrUserPending || e.Err == usecase.ErrUserDeleted
+}
+
+// statusErrorJSON writes use case error and status code to response.
+// Error caused by user's status is written with Forbidden status code.
+func statusErrorJSON(w http.ResponseWriter, e *usecase.Error, code int) {
+	if blocked(e) {
+		code = http.StatusForbidden
+	}
+	api.ErrorJSON(w, e.Err.Error(), code)
+}
+
+// limitErrorJSON writes use case error and status code to response.
+// Error caused by too many attempts or requests is written with
+// Too Many Requests status code, error caused by user's status
+// is written with Forbidden status code.
+func limitErrorJSON(w http.ResponseWriter, e *usecase.Error, code int) {
+	if e.Err == usecase.ErrTooManyAttempts || e.Err == usecase.ErrTooManyRequests {
+		code = http.StatusTooManyRequests
+	}
+	statusErrorJSON(w, e, code)
+}
+
+// readIP reads client IP from request's remote address
+// that is peer address or address forwarded by trusted proxy.
+// It returns IP string without port.
+func readIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeRetryAfter writes number of seconds until next attempt
+// to response's Retry-After header if duration is positive.
+func writeRetryAfter(w http.ResponseWriter, d time.Duration) {
+	if d > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+	}
+}
+
 // writeAccessToken writes an access token to response body.
 func writeAccessToken(w http.ResponseWriter, token entity.AccessToken) {
 	e := json.NewEncoder(w)
@@ -91,7 +242,7 @@ func writeRefreshToken(w http.ResponseWriter, token *entity.RefreshToken) {
 	cookie := &http.Cookie{
 		Name:     "refresh_token",
 		Path:     "/v1/token",
-		Value:    token.ID.String(),
+		Value:    token.Token,
 		Secure:   true,
 		HttpOnly: true,
 		SameSite: http.SameSiteNoneMode,
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/usecase"
+)
+
+// password represents controllers grouped by password route.
+type password struct {
+	userUC  usecase.User
+	resetUC usecase.Reset
+}
+
+// Forgot reads email from request's body
+// and calls Reset.Forgot to send reset link.
+// Response is the same whether or not user exists.
+func (p *password) Forgot(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = p.resetUC.Forgot(body.Email)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Reset reads token and new password from request's body
+// and calls Reset.Reset to update user's password.
+func (p *password) Reset(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = p.resetUC.Reset(body.Token, []byte(body.Password))
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			passwordErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Strength reads password and optional name from request's body
+// and calls User.Strength to estimate password strength
+// the same way registration checks it.
+func (p *password) Strength(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	strength, rules, err := p.userUC.Strength([]byte(body.Password), body.Name)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	if rules == nil {
+		rules = []string{}
+	}
+	if strength.Suggestions == nil {
+		strength.Suggestions = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"score":              strength.Score,
+		"guesses_log10":      math.Log10(strength.Guesses),
+		"crack_time_seconds": strength.CrackTime,
+		"crack_time_display": strength.CrackTimeDisplay(),
+		"warning":            strength.Warning,
+		"suggestions":        strength.Suggestions,
+		"valid":              len(rules) == 0,
+		"rules":              rules,
+	})
+}
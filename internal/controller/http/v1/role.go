@@ -0,0 +1,263 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/usecase"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// role represents controllers grouped by role route.
+type role struct {
+	roleUC usecase.Role
+}
+
+// Create reads role from request
+// and calls Role.Create to create a new role.
+func (ro *role) Create(w http.ResponseWriter, r *http.Request) {
+	var data entity.Role
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&data)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	role, err := ro.roleUC.Create(data)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	e := json.NewEncoder(w)
+	e.Encode(role)
+}
+
+// List calls Role.List to get all roles.
+func (ro *role) List(w http.ResponseWriter, r *http.Request) {
+	roles, err := ro.roleUC.List()
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(roles)
+}
+
+// Get reads role ID from URL
+// and calls Role.Get to get role by ID.
+func (ro *role) Get(w http.ResponseWriter, r *http.Request) {
+	roleID, err := readRoleID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := ro.roleUC.Get(roleID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(role)
+}
+
+// Update reads role ID from URL and role from request,
+// then calls Role.Update to update role's title and description by ID.
+func (ro *role) Update(w http.ResponseWriter, r *http.Request) {
+	roleID, err := readRoleID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data entity.Role
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&data)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+	data.ID = roleID
+
+	role, err := ro.roleUC.Update(data)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			if e.Err == usecase.ErrRoleNotExist {
+				api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+				return
+			}
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(role)
+}
+
+// Delete reads role ID from URL
+// and calls Role.Delete to delete role by ID.
+func (ro *role) Delete(w http.ResponseWriter, r *http.Request) {
+	roleID, err := readRoleID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = ro.roleUC.Delete(roleID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Assign reads role ID and user ID from URL
+// and calls Role.Assign to assign role to user.
+func (ro *role) Assign(w http.ResponseWriter, r *http.Request) {
+	roleID, err := readRoleID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = ro.roleUC.Assign(roleID, userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unassign reads role ID and user ID from URL
+// and calls Role.Unassign to unassign role from user.
+func (ro *role) Unassign(w http.ResponseWriter, r *http.Request) {
+	roleID, err := readRoleID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = ro.roleUC.Unassign(roleID, userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListByUser reads user ID from URL
+// and calls Role.ListByUser to get roles assigned to user.
+func (ro *role) ListByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := readUserID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	roles, err := ro.roleUC.ListByUser(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(roles)
+}
+
+// ListUsers reads role ID from URL
+// and calls Role.ListUsers to get users the role is assigned to.
+func (ro *role) ListUsers(w http.ResponseWriter, r *http.Request) {
+	roleID, err := readRoleID(r)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, err := ro.roleUC.ListUsers(roleID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	members := make([]map[string]any, len(users))
+	for i, user := range users {
+		members[i] = map[string]any{
+			"id":       user.ID,
+			"username": user.Name,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(members)
+}
+
+// readRoleID reads role ID from URL parameter.
+// It returns error if ID is invalid.
+func readRoleID(r *http.Request) (uuid.UUID, error) {
+	roleID, err := uuid.FromString(chi.URLParam(r, "roleID"))
+	if err != nil {
+		return uuid.UUID{}, usecase.ErrRoleIDInvalid
+	}
+	return roleID, nil
+}
+
+// readUserID reads user ID from URL parameter.
+// It returns error if ID is invalid.
+func readUserID(r *http.Request) (uuid.UUID, error) {
+	userID, err := uuid.FromString(chi.URLParam(r, "userID"))
+	if err != nil {
+		return uuid.UUID{}, usecase.ErrUserIDInvalid
+	}
+	return userID, nil
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/usecase"
+)
+
+// samlRequestAge is lifetime of cookie storing authentication request ID.
+const samlRequestAge = 5 * time.Minute
+
+// saml represents controllers grouped by SAML route.
+type saml struct {
+	samlUC  usecase.SAML
+	userUC  usecase.User
+	tokenUC usecase.Token
+	mfaUC   usecase.MFA
+}
+
+// Metadata calls SAML.Metadata use case
+// and writes service provider metadata to response.
+func (s *saml) Metadata(w http.ResponseWriter, r *http.Request) {
+	metadata, err := s.samlUC.Metadata()
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(metadata)
+}
+
+// Login calls SAML.Request use case to create authentication request,
+// stores request ID in cookie and redirects user to identity provider.
+func (s *saml) Login(w http.ResponseWriter, r *http.Request) {
+	requestID, url, err := s.samlUC.Request(r.URL.Query().Get("state"))
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	writeSAMLRequest(w, requestID)
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// ACS reads SAML response from request's form and request ID from cookie,
+// calls SAML.Login use case to validate assertion and redirects user
+// to application with one-time code.
+func (s *saml) ACS(w http.ResponseWriter, r *http.Request) {
+	var requestID string
+	if cookie, err := r.Cookie("saml_request"); err == nil {
+		requestID = cookie.Value
+	}
+
+	url, err := s.samlUC.Login(r.PostFormValue("SAMLResponse"), requestID, r.PostFormValue("RelayState"))
+	deleteSAMLRequest(w)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	http.Redirect(w, r, url, http.StatusSeeOther)
+}
+
+// Token reads one-time code and fingerprint from request, calls SAML.Exchange
+// use case to consume code and Token.Create use case to create
+// new access and refresh tokens.
+// If user has enabled MFA, challenge is returned instead of tokens.
+func (s *saml) Token(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Code    string `json:"code"`
+		Session bool   `json:"session"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&data)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	fingerprint := readFingerprint(r)
+
+	userID, err := s.samlUC.Exchange(data.Code)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	issueTokens(w, s.userUC, s.tokenUC, s.mfaUC, userID, fingerprint, data.Session)
+}
+
+// writeSAMLRequest writes authentication request ID to response cookie.
+// Cookie is sent cross-site because identity provider posts response to ACS.
+func writeSAMLRequest(w http.ResponseWriter, requestID string) {
+	cookie := &http.Cookie{
+		Name:     "saml_request",
+		Path:     "/v1/saml",
+		Value:    requestID,
+		MaxAge:   int(samlRequestAge.Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteNoneMode,
+	}
+
+	http.SetCookie(w, cookie)
+}
+
+// deleteSAMLRequest writes an expired authentication request ID to response cookie.
+func deleteSAMLRequest(w http.ResponseWriter) {
+	cookie := &http.Cookie{
+		Name:     "saml_request",
+		Path:     "/v1/saml",
+		Expires:  time.Unix(0, 0),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteNoneMode,
+	}
+
+	http.SetCookie(w, cookie)
+}
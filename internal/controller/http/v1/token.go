@@ -2,22 +2,30 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	api "github.com/qsoulior/auth-server/internal/controller/http"
 	"github.com/qsoulior/auth-server/internal/entity"
 	"github.com/qsoulior/auth-server/internal/usecase"
+	"github.com/qsoulior/auth-server/pkg/uuid"
 )
 
 // token represents controllers grouped by token route.
 type token struct {
-	userUC  usecase.User
-	tokenUC usecase.Token
+	userUC    usecase.User
+	tokenUC   usecase.Token
+	mfaUC     usecase.MFA
+	lockoutUC usecase.Lockout
 }
 
 // Create reads user data and fingerprint from request, calls User.Verify
 // use case to authenticate user and Token.Create use case to create
 // new access and refresh tokens.
+// Failed attempts are recorded by Lockout use case and
+// locked name or client IP is rejected with Retry-After header.
+// If user has enabled MFA, challenge is returned instead of tokens.
+// If user's password is expired, restricted access token is returned instead.
 func (t *token) Create(w http.ResponseWriter, r *http.Request) {
 	var data struct {
 		Name     string `json:"name"`
@@ -32,16 +40,116 @@ func (t *token) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fingerprint := readFingerprint(r)
+	ip := readIP(r)
+
+	retryAfter, err := t.lockoutUC.Check(data.Name, ip)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			writeRetryAfter(w, retryAfter)
+			api.ErrorJSON(w, e.Err.Error(), http.StatusTooManyRequests)
+		})
+		return
+	}
 
 	userID, err := t.userUC.Verify(entity.User{Name: data.Name, Password: []byte(data.Password)})
 	if err != nil {
+		if errors.Is(err, usecase.ErrPasswordIncorrect) || errors.Is(err, usecase.ErrUserNotExist) {
+			retryAfter, fErr := t.lockoutUC.Fail(data.Name, ip)
+			if fErr != nil {
+				err = fErr
+			}
+			writeRetryAfter(w, retryAfter)
+		} else if rErr := t.lockoutUC.Release(data.Name, ip); rErr != nil {
+			err = rErr
+		}
+		api.HandleError(err, func(e *usecase.Error) {
+			statusErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	// password is correct, so attempt isn't counted against username and client IP
+	if err := t.lockoutUC.Release(data.Name, ip); err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
 			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
 		})
 		return
 	}
 
-	accessToken, refreshToken, err := t.tokenUC.Create(userID, fingerprint, data.Session)
+	issueTokens(w, t.userUC, t.tokenUC, t.mfaUC, userID, fingerprint, data.Session)
+}
+
+// CompleteMFA reads challenge, code and fingerprint from request,
+// calls MFA.Complete use case to verify the second factor
+// or MFA.Recover use case if recovery code is used instead,
+// then calls Token.Create use case to create new access and refresh tokens.
+// Incorrect codes are recorded as failed attempts of user's name,
+// challenge of locked name is rejected.
+// If user's password is expired, restricted access token is returned instead.
+func (t *token) CompleteMFA(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Challenge    string `json:"challenge"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&data)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	fingerprint := readFingerprint(r)
+
+	var challenge *entity.Challenge
+	if data.RecoveryCode != "" {
+		challenge, err = t.mfaUC.Recover(data.Challenge, data.RecoveryCode)
+	} else {
+		challenge, err = t.mfaUC.Complete(data.Challenge, data.Code)
+	}
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			limitErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	createTokens(w, t.userUC, t.tokenUC, challenge.UserID, fingerprint, challenge.Session)
+}
+
+// issueTokens calls MFA.Challenge use case to check user's status
+// and writes challenge to response if user has enabled MFA,
+// otherwise it calls createTokens. Every login path completing the first step
+// of authentication uses it, so that the same checks are applied.
+func issueTokens(w http.ResponseWriter, userUC usecase.User, tokenUC usecase.Token, mfaUC usecase.MFA, userID uuid.UUID, fingerprint []byte, session bool) {
+	challenge, err := mfaUC.Challenge(userID, session)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			limitErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	if challenge != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		e := json.NewEncoder(w)
+		e.Encode(map[string]any{
+			"challenge":  challenge.Token,
+			"methods":    challenge.Methods,
+			"expires_at": challenge.ExpiresAt,
+		})
+		return
+	}
+
+	createTokens(w, userUC, tokenUC, userID, fingerprint, session)
+}
+
+// createTokens calls User.PasswordExpired use case and Token.CreateRestricted
+// to create access token only valid for password change if password is expired,
+// otherwise it calls Token.Create to create new access and refresh tokens.
+func createTokens(w http.ResponseWriter, userUC usecase.User, tokenUC usecase.Token, userID uuid.UUID, fingerprint []byte, session bool) {
+	expired, err := userUC.PasswordExpired(userID)
 	if err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
 			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
@@ -49,6 +157,33 @@ func (t *token) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if expired {
+		accessToken, err := tokenUC.CreateRestricted(userID, fingerprint)
+		if err != nil {
+			api.HandleError(err, func(e *usecase.Error) {
+				statusErrorJSON(w, e, http.StatusBadRequest)
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		e := json.NewEncoder(w)
+		e.Encode(map[string]any{
+			"access_token": accessToken,
+			"scope":        entity.ScopePassword,
+		})
+		return
+	}
+
+	accessToken, refreshToken, err := tokenUC.Create(userID, fingerprint, session)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			statusErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
 	writeRefreshToken(w, refreshToken)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -58,20 +193,20 @@ func (t *token) Create(w http.ResponseWriter, r *http.Request) {
 // Refresh reads refresh token and fingerprint from request
 // and calls Token.Refresh use case to create new access and refresh tokens.
 func (t *token) Refresh(w http.ResponseWriter, r *http.Request) {
-	tokenID, err := readRefreshToken(r)
+	currentToken, err := readRefreshToken(r)
 	if err != nil {
 		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	fingerprint := readFingerprint(r)
 
-	accessToken, refreshToken, err := t.tokenUC.Refresh(tokenID, fingerprint)
+	accessToken, refreshToken, err := t.tokenUC.Refresh(currentToken, fingerprint)
 	if err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
-			if e.Err == usecase.ErrTokenExpired {
+			if e.Err == usecase.ErrTokenExpired || e.Err == usecase.ErrTokenReused || blocked(e) {
 				deleteRefreshToken(w)
 			}
-			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+			statusErrorJSON(w, e, http.StatusBadRequest)
 		})
 		return
 	}
@@ -85,20 +220,20 @@ func (t *token) Refresh(w http.ResponseWriter, r *http.Request) {
 // Revoke reads refresh token and fingerprint from request
 // and calls Token.Delete to delete refresh token.
 func (t *token) Revoke(w http.ResponseWriter, r *http.Request) {
-	tokenID, err := readRefreshToken(r)
+	currentToken, err := readRefreshToken(r)
 	if err != nil {
 		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	fingerprint := readFingerprint(r)
 
-	err = t.tokenUC.Delete(tokenID, fingerprint)
+	err = t.tokenUC.Delete(currentToken, fingerprint)
 	if err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
-			if e.Err == usecase.ErrTokenExpired {
+			if e.Err == usecase.ErrTokenExpired || e.Err == usecase.ErrTokenReused || blocked(e) {
 				deleteRefreshToken(w)
 			}
-			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+			statusErrorJSON(w, e, http.StatusBadRequest)
 		})
 		return
 	}
@@ -110,20 +245,20 @@ func (t *token) Revoke(w http.ResponseWriter, r *http.Request) {
 // RevokeAll reads refresh token and fingerprint from request
 // and calls Token.DeleteAll to delete all user refresh tokens.
 func (t *token) RevokeAll(w http.ResponseWriter, r *http.Request) {
-	tokenID, err := readRefreshToken(r)
+	currentToken, err := readRefreshToken(r)
 	if err != nil {
 		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	fingerprint := readFingerprint(r)
 
-	err = t.tokenUC.DeleteAll(tokenID, fingerprint)
+	err = t.tokenUC.DeleteAll(currentToken, fingerprint)
 	if err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
-			if e.Err == usecase.ErrTokenExpired {
+			if e.Err == usecase.ErrTokenExpired || e.Err == usecase.ErrTokenReused || blocked(e) {
 				deleteRefreshToken(w)
 			}
-			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+			statusErrorJSON(w, e, http.StatusBadRequest)
 		})
 		return
 	}
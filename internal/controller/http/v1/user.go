@@ -2,7 +2,9 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	api "github.com/qsoulior/auth-server/internal/controller/http"
 	"github.com/qsoulior/auth-server/internal/entity"
@@ -12,7 +14,9 @@ import (
 
 // user represents controllers grouped by user route.
 type user struct {
-	userUC usecase.User
+	userUC    usecase.User
+	mfaUC     usecase.MFA
+	lockoutUC usecase.Lockout
 }
 
 // Create reads user from request
@@ -29,7 +33,7 @@ func (u *user) Create(w http.ResponseWriter, r *http.Request) {
 	_, err = u.userUC.Create(user)
 	if err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
-			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+			attributesErrorJSON(w, e, http.StatusBadRequest)
 		})
 		return
 	}
@@ -37,8 +41,8 @@ func (u *user) Create(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 }
 
-// Get gets user ID from request's context
-// and calls User.Get to get user data by ID.
+// Get gets user ID from request's context, calls User.Get to get user data by ID
+// and MFA.CountRecovery to count remaining recovery codes.
 func (u *user) Get(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID, _ := ctx.Value("userID").(uuid.UUID)
@@ -51,17 +55,29 @@ func (u *user) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recoveryCodes, err := u.mfaUC.CountRecovery(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	e := json.NewEncoder(w)
 	e.Encode(map[string]any{
-		"id":       user.ID,
-		"username": user.Name,
+		"id":             user.ID,
+		"username":       user.Name,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"recovery_codes": recoveryCodes,
+		"attributes":     user.Attributes,
 	})
 }
 
 // Delete gets user ID from request's context and current password
-// from request's body, then calls User.Delete to delete user by ID.
+// from request's body, then calls User.Delete to mark user as pending deletion by ID.
 func (u *user) Delete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID, _ := ctx.Value("userID").(uuid.UUID)
@@ -87,6 +103,134 @@ func (u *user) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Restore reads user's name and password from request
+// and calls User.Restore to cancel user's deletion request.
+// Failed attempts are recorded by Lockout use case the same way as login.
+func (u *user) Restore(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	ip := readIP(r)
+
+	retryAfter, err := u.lockoutUC.Check(body.Name, ip)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			writeRetryAfter(w, retryAfter)
+			api.ErrorJSON(w, e.Err.Error(), http.StatusTooManyRequests)
+		})
+		return
+	}
+
+	err = u.userUC.Restore(entity.User{Name: body.Name, Password: []byte(body.Password)})
+	if err != nil {
+		if errors.Is(err, usecase.ErrPasswordIncorrect) || errors.Is(err, usecase.ErrUserNotExist) {
+			retryAfter, fErr := u.lockoutUC.Fail(body.Name, ip)
+			if fErr != nil {
+				err = fErr
+			}
+			writeRetryAfter(w, retryAfter)
+		} else if rErr := u.lockoutUC.Release(body.Name, ip); rErr != nil {
+			err = rErr
+		}
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	if err := u.lockoutUC.Release(body.Name, ip); err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	if err := u.lockoutUC.Succeed(body.Name); err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Export gets user ID from request's context and calls User.Export
+// to get all data stored about user. Password hashes, token hashes
+// and fingerprints aren't exported.
+func (u *user) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	data, err := u.userUC.Export(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	roles := make([]string, len(data.Roles))
+	for i, role := range data.Roles {
+		roles[i] = role.Title
+	}
+
+	sessions := make([]map[string]any, len(data.Sessions))
+	for i, session := range data.Sessions {
+		sessions[i] = map[string]any{
+			"id":         session.ID,
+			"expires_at": session.ExpiresAt,
+			"session":    session.Session,
+		}
+	}
+
+	events := make([]map[string]any, len(data.Events))
+	for i, event := range data.Events {
+		events[i] = map[string]any{
+			"type":       event.Type,
+			"details":    event.Details,
+			"created_at": event.CreatedAt,
+		}
+	}
+
+	passwordChanges := make([]time.Time, len(data.History))
+	for i, entry := range data.History {
+		passwordChanges[i] = entry.CreatedAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="export.json"`)
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"user": map[string]any{
+			"id":                   data.User.ID,
+			"username":             data.User.Name,
+			"email":                data.User.Email,
+			"email_verified":       data.User.EmailVerified,
+			"password_changed_at":  data.User.PasswordChangedAt,
+			"must_change_password": data.User.MustChangePassword,
+			"attributes":           data.User.Attributes,
+			"status":               data.User.Status,
+			"status_reason":        data.User.StatusReason,
+			"suspended_until":      data.User.SuspendedUntil,
+			"deleted_at":           data.User.DeletedAt,
+		},
+		"roles":            roles,
+		"sessions":         sessions,
+		"events":           events,
+		"password_changes": passwordChanges,
+	})
+}
+
 // UpdatePassword gets user ID from request's context and passwords
 // from request's body, then calls User.UpdatePassword to
 // update user's password by ID.
@@ -106,6 +250,34 @@ func (u *user) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = u.userUC.UpdatePassword(userID, []byte(body.CurrentPassword), []byte(body.NewPassword))
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			passwordErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateName gets user ID from request's context and name
+// from request's body, then calls User.UpdateName to
+// update user's name by ID.
+func (u *user) UpdateName(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	err = u.userUC.UpdateName(userID, body.Name)
 	if err != nil {
 		api.HandleError(err, func(e *usecase.Error) {
 			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
@@ -115,3 +287,34 @@ func (u *user) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// UpdateProfile gets user ID from request's context and attributes patch
+// from request's body, then calls User.UpdateProfile to
+// update user's attributes by ID.
+func (u *user) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	var patch map[string]any
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&patch)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	attributes, err := u.userUC.UpdateProfile(userID, patch)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			attributesErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"attributes": attributes,
+	})
+}
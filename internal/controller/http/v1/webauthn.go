@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	api "github.com/qsoulior/auth-server/internal/controller/http"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/usecase"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+	"github.com/qsoulior/auth-server/pkg/webauthn"
+)
+
+// webAuthn represents controllers grouped by WebAuthn routes.
+type webAuthn struct {
+	webAuthnUC usecase.WebAuthn
+	userUC     usecase.User
+	tokenUC    usecase.Token
+}
+
+// readCeremonyID parses ceremony ID from string.
+// It returns error if ID is invalid.
+func readCeremonyID(s string) (uuid.UUID, error) {
+	ceremonyID, err := uuid.FromString(s)
+	if err != nil {
+		return uuid.UUID{}, usecase.ErrCeremonyIncorrect
+	}
+	return ceremonyID, nil
+}
+
+// credentialJSON converts credential to map encoded in response.
+func credentialJSON(credential entity.Credential) map[string]any {
+	return map[string]any{
+		"id":           base64.RawURLEncoding.EncodeToString(credential.ID),
+		"name":         credential.Name,
+		"created_at":   credential.CreatedAt,
+		"last_used_at": credential.LastUsedAt,
+	}
+}
+
+// writeCeremony writes ceremony ID and options to response body.
+func writeCeremony(w http.ResponseWriter, ceremony *entity.Ceremony, options any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(map[string]any{
+		"ceremony":   ceremony.ID,
+		"public_key": options,
+	})
+}
+
+// BeginRegistration gets user ID from request's context
+// and calls WebAuthn.BeginRegistration to create registration ceremony.
+func (wa *webAuthn) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	ceremony, options, err := wa.webAuthnUC.BeginRegistration(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	writeCeremony(w, ceremony, options)
+}
+
+// FinishRegistration gets user ID from request's context, ceremony ID,
+// credential name and registration response from request's body,
+// then calls WebAuthn.FinishRegistration to store a new credential.
+func (wa *webAuthn) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	var body struct {
+		Ceremony   string                        `json:"ceremony"`
+		Name       string                        `json:"name"`
+		Credential webauthn.RegistrationResponse `json:"credential"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	ceremonyID, err := readCeremonyID(body.Ceremony)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	credential, err := wa.webAuthnUC.FinishRegistration(userID, ceremonyID, body.Name, body.Credential)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	e := json.NewEncoder(w)
+	e.Encode(credentialJSON(*credential))
+}
+
+// ListCredentials gets user ID from request's context
+// and calls WebAuthn.ListCredentials to get user's credentials.
+func (wa *webAuthn) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	credentials, err := wa.webAuthnUC.ListCredentials(userID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	items := make([]map[string]any, len(credentials))
+	for i, credential := range credentials {
+		items[i] = credentialJSON(credential)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	e := json.NewEncoder(w)
+	e.Encode(items)
+}
+
+// DeleteCredential gets user ID from request's context and credential ID from URL,
+// then calls WebAuthn.DeleteCredential to delete user's credential.
+func (wa *webAuthn) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("userID").(uuid.UUID)
+
+	credentialID, err := base64.RawURLEncoding.DecodeString(chi.URLParam(r, "credentialID"))
+	if err != nil {
+		api.ErrorJSON(w, usecase.ErrCredentialNotExist.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = wa.webAuthnUC.DeleteCredential(userID, credentialID)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusNotFound)
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginLogin reads MFA challenge and session flag from request's body
+// and calls WebAuthn.BeginLogin to create authentication ceremony.
+// Passkey is used without password if challenge is empty.
+func (wa *webAuthn) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Challenge string `json:"challenge"`
+		Session   bool   `json:"session"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	ceremony, options, err := wa.webAuthnUC.BeginLogin(body.Challenge, body.Session)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			api.ErrorJSON(w, e.Err.Error(), http.StatusBadRequest)
+		})
+		return
+	}
+
+	writeCeremony(w, ceremony, options)
+}
+
+// FinishLogin reads ceremony ID, authentication response and fingerprint from request,
+// calls WebAuthn.FinishLogin to authenticate user
+// and Token.Create to create new access and refresh tokens.
+// If user's password is expired, restricted access token is returned instead.
+func (wa *webAuthn) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Ceremony   string                          `json:"ceremony"`
+		Credential webauthn.AuthenticationResponse `json:"credential"`
+	}
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&body)
+	if err != nil {
+		api.DecodingError(w)
+		return
+	}
+
+	ceremonyID, err := readCeremonyID(body.Ceremony)
+	if err != nil {
+		api.ErrorJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fingerprint := readFingerprint(r)
+
+	ceremony, err := wa.webAuthnUC.FinishLogin(ceremonyID, body.Credential)
+	if err != nil {
+		api.HandleError(err, func(e *usecase.Error) {
+			limitErrorJSON(w, e, http.StatusBadRequest)
+		})
+		return
+	}
+
+	// passkey verified with user verification is second factor itself,
+	// so challenge isn't created for passwordless login
+	createTokens(w, wa.userUC, wa.tokenUC, *ceremony.UserID, fingerprint, ceremony.Session)
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// Assertion entity.
+// It represents consumed SAML assertion and one-time login code issued for it.
+type Assertion struct {
+	ID            string    `json:"id"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Code          []byte    `json:"-"`
+	CodeExpiresAt time.Time `json:"-"`
+	UserID        uuid.UUID `json:"-"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// Attempt entity.
+// It represents failed authentication attempts by username or client IP.
+// Key is prefixed with type of attempted subject, e.g. "name:" or "ip:".
+type Attempt struct {
+	Key         string    `json:"key"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// Types of WebAuthn ceremonies.
+const (
+	CeremonyRegistration   = "registration"
+	CeremonyAuthentication = "authentication"
+)
+
+// Credential entity.
+// It represents WebAuthn public key credential (passkey) registered by user.
+type Credential struct {
+	ID         []byte     `json:"id"`
+	PublicKey  []byte     `json:"-"`
+	SignCount  int64      `json:"-"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	UserID     uuid.UUID  `json:"-"`
+}
+
+// Ceremony entity.
+// It represents pending WebAuthn ceremony and its challenge.
+// UserID is nil if authentication ceremony isn't bound to user,
+// ChallengeID is set if ceremony completes MFA challenge.
+type Ceremony struct {
+	ID          uuid.UUID  `json:"id"`
+	Challenge   []byte     `json:"-"`
+	Type        string     `json:"type"`
+	Session     bool       `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	UserID      *uuid.UUID `json:"-"`
+	ChallengeID *uuid.UUID `json:"-"`
+}
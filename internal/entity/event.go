@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// Types of security events.
+const (
+	EventTokenReused   = "token_reused"
+	EventStatusChanged = "status_changed"
+	EventDeleted       = "deleted"
+	EventRestored      = "restored"
+	EventPurged        = "purged"
+)
+
+// Event entity.
+// It represents security event related to user.
+// Only purge event is kept after user is purged.
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `json:"-"`
+}
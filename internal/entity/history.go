@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// PasswordHistory entity.
+// It represents hash of password user has set
+// that can't be used again.
+type PasswordHistory struct {
+	ID        uuid.UUID `json:"id"`
+	Hash      []byte    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uuid.UUID `json:"-"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// MagicLink entity.
+// It represents single-use login link token stored as hash
+// that is bound to fingerprint of client requested it.
+type MagicLink struct {
+	ID          uuid.UUID `json:"id"`
+	Hash        []byte    `json:"-"`
+	Fingerprint []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      uuid.UUID `json:"-"`
+}
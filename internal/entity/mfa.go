@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// TOTP entity.
+// It represents user's time-based one-time password secret.
+// LastCounter is time step of the last accepted code.
+// Sealed is set if secret is encrypted, it is unset only for secrets
+// stored before encryption was introduced.
+type TOTP struct {
+	UserID      uuid.UUID `json:"-"`
+	Secret      []byte    `json:"-"`
+	Confirmed   bool      `json:"confirmed"`
+	LastCounter int64     `json:"-"`
+	Sealed      bool      `json:"-"`
+}
+
+// Methods of second step of authentication.
+const (
+	MethodTOTP     = "totp"
+	MethodWebAuthn = "webauthn"
+	MethodRecovery = "recovery"
+)
+
+// Challenge entity.
+// It represents pending second step of authentication
+// that must be completed before tokens are created.
+// Methods are second factors enabled by user.
+type Challenge struct {
+	ID        uuid.UUID `json:"id"`
+	Hash      []byte    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Session   bool      `json:"-"`
+	Attempts  int       `json:"-"`
+	UserID    uuid.UUID `json:"-"`
+	Token     string    `json:"-" db:"-"`
+	Methods   []string  `json:"-" db:"-"`
+}
+
+// RecoveryCode entity.
+// It represents single-use code hashed using HMAC-SHA256
+// that can be used in place of second factor.
+type RecoveryCode struct {
+	ID     uuid.UUID `json:"id"`
+	Hash   []byte    `json:"-"`
+	UserID uuid.UUID `json:"-"`
+}
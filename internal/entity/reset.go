@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// ResetToken entity.
+// It represents single-use password reset token stored as hash.
+type ResetToken struct {
+	ID        uuid.UUID `json:"id"`
+	Hash      []byte    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserID    uuid.UUID `json:"-"`
+}
@@ -10,13 +10,27 @@ import (
 // Access token entity.
 type AccessToken string
 
+// ScopePassword is scope of restricted access token
+// that is only valid for password change.
+const ScopePassword = "password"
+
 // Refresh token entity.
+// Only Hash of opaque token is stored, Token is set only when token is issued.
+// Tokens created by rotation share FamilyID with the original one.
+// UsedAt, ReplacedBy and Successor encrypted with token are set when token is rotated,
+// used token is kept until it expires.
 type RefreshToken struct {
-	ID          uuid.UUID `json:"id"`
-	ExpiresAt   time.Time `json:"expires_at"`
-	Fingerprint []byte    `json:"fingerprint"`
-	Session     bool      `json:"session"`
-	UserID      uuid.UUID `json:"-"`
+	ID          uuid.UUID  `json:"id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	Fingerprint []byte     `json:"fingerprint"`
+	Session     bool       `json:"session"`
+	UserID      uuid.UUID  `json:"-"`
+	FamilyID    uuid.UUID  `json:"-"`
+	UsedAt      *time.Time `json:"-"`
+	ReplacedBy  *uuid.UUID `json:"-"`
+	Hash        []byte     `json:"-"`
+	Successor   []byte     `json:"-"`
+	Token       string     `json:"-" db:"-"`
 }
 
 // UnmarshalJSON sets *t fields to values from JSON bytes.
@@ -2,23 +2,62 @@ package entity
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/qsoulior/auth-server/pkg/uuid"
 )
 
+// Statuses of user account.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusDisabled  = "disabled"
+	StatusPending   = "pending"
+)
+
+// Sources of user account.
+// SourceExternal marks accounts provisioned by identity provider
+// before their source was recorded, they aren't linked to any identity provider.
+const (
+	SourceLocal    = "local"
+	SourceSAML     = "saml"
+	SourceLDAP     = "ldap"
+	SourceExternal = "external"
+)
+
 // User entity.
+// Email is empty if it isn't set.
+// MustChangePassword is set if user must change password on next login.
+// NameKey is name with folded case that is unique among users.
+// Attributes are custom profile attributes validated against schema.
+// Status is one of account statuses, StatusReason is set by administrator who changed it.
+// SuspendedUntil is nil unless user is suspended.
+// DeletedAt is set if user requested deletion and is nil otherwise.
+// Source is one of account sources, only accounts from the same source can be linked.
 type User struct {
-	ID       uuid.UUID `json:"id"`
-	Name     string    `json:"name"`
-	Password []byte    `json:"password"`
+	ID                 uuid.UUID      `json:"id"`
+	Name               string         `json:"name"`
+	Password           []byte         `json:"password"`
+	Email              string         `json:"email"`
+	EmailVerified      bool           `json:"email_verified"`
+	PasswordChangedAt  time.Time      `json:"password_changed_at"`
+	MustChangePassword bool           `json:"must_change_password"`
+	NameKey            string         `json:"-"`
+	Attributes         map[string]any `json:"attributes"`
+	Status             string         `json:"status"`
+	StatusReason       string         `json:"status_reason"`
+	SuspendedUntil     *time.Time     `json:"suspended_until"`
+	DeletedAt          *time.Time     `json:"deleted_at"`
+	Source             string         `json:"source"`
 }
 
 // UnmarshalJSON sets *u fields to values from JSON bytes.
 // It sets Password to bytes instead of a string.
 func (u *User) UnmarshalJSON(b []byte) error {
 	var v struct {
-		Name     string
-		Password string
+		Name       string
+		Password   string
+		Attributes map[string]any
 	}
 	if err := json.Unmarshal(b, &v); err != nil {
 		return err
@@ -26,5 +65,6 @@ func (u *User) UnmarshalJSON(b []byte) error {
 
 	u.Name = v.Name
 	u.Password = []byte(v.Password)
+	u.Attributes = v.Attributes
 	return nil
 }
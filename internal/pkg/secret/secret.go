@@ -0,0 +1,123 @@
+// Package secret provides functions to generate opaque secrets,
+// hash them, encrypt data with them and sign data.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/qsoulior/auth-server/internal/pkg/hash"
+)
+
+var (
+	ErrCiphertextInvalid = errors.New("ciphertext is invalid")
+	ErrSignatureInvalid  = errors.New("signature is invalid")
+)
+
+// New generates random secret of size bytes.
+// It returns secret encoded in base64url without padding.
+func New(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash hashes secret using SHA-256 algorithm.
+// It returns hash.Hash instance.
+func Hash(s string) hash.Hash {
+	h := sha256.Sum256([]byte(s))
+	return h[:]
+}
+
+// key derives encryption key from secret using HMAC-SHA256,
+// so that key cannot be computed from secret's hash.
+func key(s string) []byte {
+	mac := hmac.New(sha256.New, []byte(s))
+	mac.Write([]byte("seal"))
+	return mac.Sum(nil)
+}
+
+// Seal encrypts plaintext using AES-GCM with key derived from secret.
+// It returns nonce followed by ciphertext.
+func Seal(s string, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key(s))
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts ciphertext sealed with the same secret.
+// It returns plaintext or error if secret is incorrect or ciphertext is modified.
+func Open(s string, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key(s))
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrCiphertextInvalid
+	}
+
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// Sign signs payload using HMAC-SHA256 with key.
+// It returns payload and signature encoded in base64url and separated by dot.
+func Sign(key []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify verifies signature of signed payload using key.
+// It returns payload or ErrSignatureInvalid if signature is incorrect.
+func Verify(key []byte, signed string) ([]byte, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(signed, ".")
+	if !ok {
+		return nil, ErrSignatureInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrSignatureInvalid
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrSignatureInvalid
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrSignatureInvalid
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+)
+
+// assertionPostgres implements Assertion interface.
+// It represents repository to interact with Postgres.
+type assertionPostgres struct {
+	*db.Postgres
+}
+
+// NewAssertionPostgres creates a new assertionPostgres.
+// It returns pointer to an assertionPostgres instance.
+func NewAssertionPostgres(db *db.Postgres) *assertionPostgres {
+	return &assertionPostgres{db}
+}
+
+// Create creates a new assertion without login code and user.
+// It returns ErrExists if assertion with the same ID was consumed before.
+func (a *assertionPostgres) Create(ctx context.Context, id string, expiresAt time.Time) error {
+	const query = `INSERT INTO assertion(id, expires_at) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	tag, err := a.Pool.Exec(ctx, query, id, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrExists
+	}
+
+	return nil
+}
+
+// UpdateCode sets login code issued for assertion and user authenticated by it.
+// It returns pointer to an entity.Assertion instance
+// or nil if assertion doesn't exist.
+func (a *assertionPostgres) UpdateCode(ctx context.Context, data entity.Assertion) (*entity.Assertion, error) {
+	const query = `UPDATE assertion SET code = $2, code_expires_at = $3, user_id = $4 WHERE id = $1 RETURNING *`
+
+	rows, err := a.Pool.Query(ctx, query, data.ID, data.Code, data.CodeExpiresAt, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Assertion])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &assertion, nil
+}
+
+// ConsumeByCode gets an assertion by login code and clears the code.
+// It returns pointer to an entity.Assertion instance
+// or nil if code is incorrect or already consumed.
+func (a *assertionPostgres) ConsumeByCode(ctx context.Context, code []byte) (*entity.Assertion, error) {
+	const query = `UPDATE assertion SET code = NULL WHERE code = $1 RETURNING *`
+
+	rows, err := a.Pool.Query(ctx, query, code)
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Assertion])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &assertion, nil
+}
+
+// DeleteExpired deletes assertions that can no longer be replayed.
+func (a *assertionPostgres) DeleteExpired(ctx context.Context) error {
+	const query = `DELETE FROM assertion WHERE expires_at < $1`
+
+	if _, err := a.Pool.Exec(ctx, query, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+)
+
+// attemptPostgres implements Attempt interface.
+// It represents repository to interact with Postgres.
+type attemptPostgres struct {
+	*db.Postgres
+}
+
+// NewAttemptPostgres creates a new attemptPostgres.
+// It returns pointer to an attemptPostgres instance.
+func NewAttemptPostgres(db *db.Postgres) *attemptPostgres {
+	return &attemptPostgres{db}
+}
+
+// GetByKeys gets attempts by keys.
+// It returns slice of entity.Attempt instances.
+func (a *attemptPostgres) GetByKeys(ctx context.Context, keys []string) ([]entity.Attempt, error) {
+	const query = `SELECT * FROM attempt WHERE key = ANY($1)`
+
+	rows, err := a.Pool.Query(ctx, query, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.Attempt])
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// AddFailure increments number of failures by key.
+// Number of failures starts over if the last failure was before since.
+// It returns pointer to an updated entity.Attempt instance.
+func (a *attemptPostgres) AddFailure(ctx context.Context, key string, since time.Time) (*entity.Attempt, error) {
+	const query = `INSERT INTO attempt(key, failures, locked_until, updated_at) VALUES ($1, 1, $2, $2) ON CONFLICT (key) DO UPDATE SET failures = CASE WHEN attempt.updated_at < $3 THEN 1 ELSE attempt.failures + 1 END, updated_at = $2 RETURNING *`
+
+	rows, err := a.Pool.Query(ctx, query, key, time.Now(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Attempt])
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// Acquire increments number of attempts by key only if key isn't locked
+// and number of attempts is less than threshold.
+// Attempt is checked and counted by single statement, so that concurrent
+// attempts cannot exceed threshold. Key isn't locked by counted attempt,
+// it is locked by Lock only if attempt failed.
+// Number of attempts starts over if the last attempt was before since.
+// It returns pointer to an updated entity.Attempt instance
+// or ErrNoRows if key is locked or threshold is reached.
+func (a *attemptPostgres) Acquire(ctx context.Context, key string, threshold int, since time.Time) (*entity.Attempt, error) {
+	const query = `INSERT INTO attempt(key, failures, locked_until, updated_at) VALUES ($1, 1, $3, $3) ON CONFLICT (key) DO UPDATE SET failures = CASE WHEN attempt.updated_at < $4 THEN 1 ELSE attempt.failures + 1 END, updated_at = $3 WHERE attempt.locked_until <= $3 AND (attempt.updated_at < $4 OR attempt.failures < $2) RETURNING *`
+
+	rows, err := a.Pool.Query(ctx, query, key, threshold, time.Now(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Attempt])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// Release decrements number of attempts by key
+// after attempt counted by Acquire turned out not to be failed.
+func (a *attemptPostgres) Release(ctx context.Context, key string) error {
+	const query = `UPDATE attempt SET failures = failures - 1 WHERE key = $1 AND failures > 0`
+
+	if _, err := a.Pool.Exec(ctx, query, key); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Lock sets time until which attempts are rejected by key.
+func (a *attemptPostgres) Lock(ctx context.Context, key string, until time.Time) error {
+	const query = `UPDATE attempt SET locked_until = GREATEST(locked_until, $2) WHERE key = $1`
+
+	if _, err := a.Pool.Exec(ctx, query, key, until); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByKey deletes attempts by key.
+func (a *attemptPostgres) DeleteByKey(ctx context.Context, key string) error {
+	const query = `DELETE FROM attempt WHERE key = $1`
+
+	if _, err := a.Pool.Exec(ctx, query, key); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes attempts that aren't locked and were last updated before since.
+func (a *attemptPostgres) DeleteExpired(ctx context.Context, since time.Time) error {
+	const query = `DELETE FROM attempt WHERE updated_at < $1 AND locked_until < $2`
+
+	if _, err := a.Pool.Exec(ctx, query, since, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// credentialPostgres implements Credential interface.
+// It represents repository to interact with Postgres.
+type credentialPostgres struct {
+	*db.Postgres
+}
+
+// NewCredentialPostgres creates a new credentialPostgres.
+// It returns pointer to a credentialPostgres instance.
+func NewCredentialPostgres(db *db.Postgres) *credentialPostgres {
+	return &credentialPostgres{db}
+}
+
+// Create creates a new credential.
+// It returns pointer to an entity.Credential instance
+// or ErrExists if credential with the same ID is already registered.
+func (c *credentialPostgres) Create(ctx context.Context, data entity.Credential) (*entity.Credential, error) {
+	const query = `INSERT INTO credential(id, public_key, sign_count, name, created_at, user_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING RETURNING *`
+
+	rows, err := c.Pool.Query(ctx, query, data.ID, data.PublicKey, data.SignCount, data.Name, data.CreatedAt, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Credential])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExists
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+// GetByID gets a credential by ID.
+// It returns pointer to an entity.Credential instance
+// or nil if id is incorrect.
+func (c *credentialPostgres) GetByID(ctx context.Context, id []byte) (*entity.Credential, error) {
+	const query = `SELECT * FROM credential WHERE id = $1`
+
+	rows, err := c.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Credential])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+// GetByUser gets credentials by user ID.
+// It returns slice of entity.Credential instances ordered by creation time.
+func (c *credentialPostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.Credential, error) {
+	const query = `SELECT * FROM credential WHERE user_id = $1 ORDER BY created_at`
+
+	rows, err := c.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.Credential])
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// UpdateSignCount sets sign counter and usage time by credential ID
+// only if sign counter wasn't changed since it was got.
+// It returns ErrNoRows if credential was used concurrently.
+func (c *credentialPostgres) UpdateSignCount(ctx context.Context, id []byte, prev int64, next int64) error {
+	const query = `UPDATE credential SET sign_count = $3, last_used_at = $4 WHERE id = $1 AND sign_count = $2`
+
+	tag, err := c.Pool.Exec(ctx, query, id, prev, next, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteByID deletes a credential by ID.
+func (c *credentialPostgres) DeleteByID(ctx context.Context, id []byte) error {
+	const query = `DELETE FROM credential WHERE id = $1`
+
+	if _, err := c.Pool.Exec(ctx, query, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByUser deletes user-related credentials by user ID.
+func (c *credentialPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	const query = `DELETE FROM credential WHERE user_id = $1`
+
+	if _, err := c.Pool.Exec(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ceremonyPostgres implements Ceremony interface.
+// It represents repository to interact with Postgres.
+type ceremonyPostgres struct {
+	*db.Postgres
+}
+
+// NewCeremonyPostgres creates a new ceremonyPostgres.
+// It returns pointer to a ceremonyPostgres instance.
+func NewCeremonyPostgres(db *db.Postgres) *ceremonyPostgres {
+	return &ceremonyPostgres{db}
+}
+
+// Create creates a new ceremony.
+// It returns pointer to an entity.Ceremony instance.
+func (c *ceremonyPostgres) Create(ctx context.Context, data entity.Ceremony) (*entity.Ceremony, error) {
+	const query = `INSERT INTO ceremony(challenge, type, session, expires_at, user_id, challenge_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *`
+
+	rows, err := c.Pool.Query(ctx, query, data.Challenge, data.Type, data.Session, data.ExpiresAt, data.UserID, data.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	ceremony, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Ceremony])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ceremony, nil
+}
+
+// ConsumeByID deletes a ceremony by ID and returns it.
+// It returns pointer to an entity.Ceremony instance
+// or nil if id is incorrect or ceremony is already consumed.
+func (c *ceremonyPostgres) ConsumeByID(ctx context.Context, id uuid.UUID) (*entity.Ceremony, error) {
+	const query = `DELETE FROM ceremony WHERE id = $1 RETURNING *`
+
+	rows, err := c.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ceremony, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Ceremony])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ceremony, nil
+}
+
+// DeleteExpired deletes expired ceremonies.
+func (c *ceremonyPostgres) DeleteExpired(ctx context.Context) error {
+	const query = `DELETE FROM ceremony WHERE expires_at < $1`
+
+	if _, err := c.Pool.Exec(ctx, query, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,8 +2,17 @@ package repo
 
 import (
 	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
 	ErrNoRows = errors.New("no rows in result set")
+	ErrExists = errors.New("row already exists")
 )
+
+// isUniqueViolation reports whether err is caused by violation of unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
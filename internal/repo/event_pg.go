@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// eventPostgres implements Event interface.
+// It represents repository to interact with Postgres.
+type eventPostgres struct {
+	*db.Postgres
+}
+
+// NewEventPostgres creates a new eventPostgres.
+// It returns pointer to an eventPostgres instance.
+func NewEventPostgres(db *db.Postgres) *eventPostgres {
+	return &eventPostgres{db}
+}
+
+// Create creates a new event.
+// It returns pointer to an entity.Event instance
+// or nil if data is incorrect.
+func (e *eventPostgres) Create(ctx context.Context, data entity.Event) (*entity.Event, error) {
+	const query = `INSERT INTO event(type, details, created_at, user_id) VALUES ($1, $2, $3, $4) RETURNING *`
+
+	rows, err := e.Pool.Query(ctx, query, data.Type, data.Details, data.CreatedAt, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Event])
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// GetByUser gets events by user ID.
+// It returns slice of entity.Event instances ordered by creation time.
+func (e *eventPostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.Event, error) {
+	const query = `SELECT * FROM event WHERE user_id = $1 ORDER BY created_at`
+
+	rows, err := e.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.Event])
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// historyPostgres implements History interface.
+// It represents repository to interact with Postgres.
+type historyPostgres struct {
+	*db.Postgres
+}
+
+// NewHistoryPostgres creates a new historyPostgres.
+// It returns pointer to a historyPostgres instance.
+func NewHistoryPostgres(db *db.Postgres) *historyPostgres {
+	return &historyPostgres{db}
+}
+
+// Create creates a new password history entry
+// and deletes user's entries except the last size ones.
+func (h *historyPostgres) Create(ctx context.Context, data entity.PasswordHistory, size int) error {
+	const (
+		insertQuery = `INSERT INTO history(hash, created_at, user_id) VALUES ($1, $2, $3)`
+		deleteQuery = `DELETE FROM history WHERE user_id = $1 AND id NOT IN (SELECT id FROM history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2)`
+	)
+
+	return pgx.BeginFunc(ctx, h.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insertQuery, data.Hash, data.CreatedAt, data.UserID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteQuery, data.UserID, size); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetByUser gets the last limit password history entries by user ID.
+// It returns slice of entity.PasswordHistory instances ordered from newest.
+func (h *historyPostgres) GetByUser(ctx context.Context, userID uuid.UUID, limit int) ([]entity.PasswordHistory, error) {
+	const query = `SELECT * FROM history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	rows, err := h.Pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.PasswordHistory])
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
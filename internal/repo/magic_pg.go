@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// magicLinkPostgres implements MagicLink interface.
+// It represents repository to interact with Postgres.
+type magicLinkPostgres struct {
+	*db.Postgres
+}
+
+// NewMagicLinkPostgres creates a new magicLinkPostgres.
+// It returns pointer to a magicLinkPostgres instance.
+func NewMagicLinkPostgres(db *db.Postgres) *magicLinkPostgres {
+	return &magicLinkPostgres{db}
+}
+
+// Create creates a new magic link token.
+// It returns pointer to an entity.MagicLink instance.
+func (m *magicLinkPostgres) Create(ctx context.Context, data entity.MagicLink) (*entity.MagicLink, error) {
+	const query = `INSERT INTO magic_link(hash, fingerprint, expires_at, user_id) VALUES ($1, $2, $3, $4) RETURNING *`
+
+	rows, err := m.Pool.Query(ctx, query, data.Hash, data.Fingerprint, data.ExpiresAt, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.MagicLink])
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ConsumeByHash deletes a magic link token by hash and returns it.
+// It returns pointer to an entity.MagicLink instance
+// or nil if hash is incorrect or token is already consumed.
+func (m *magicLinkPostgres) ConsumeByHash(ctx context.Context, hash []byte) (*entity.MagicLink, error) {
+	const query = `DELETE FROM magic_link WHERE hash = $1 RETURNING *`
+
+	rows, err := m.Pool.Query(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.MagicLink])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// DeleteByUser deletes user-related magic link tokens by user ID.
+func (m *magicLinkPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	const query = `DELETE FROM magic_link WHERE user_id = $1`
+
+	if _, err := m.Pool.Exec(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes expired magic link tokens.
+func (m *magicLinkPostgres) DeleteExpired(ctx context.Context) error {
+	const query = `DELETE FROM magic_link WHERE expires_at < $1`
+
+	if _, err := m.Pool.Exec(ctx, query, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// totpPostgres implements TOTP interface.
+// It represents repository to interact with Postgres.
+type totpPostgres struct {
+	*db.Postgres
+}
+
+// NewTOTPPostgres creates a new totpPostgres.
+// It returns pointer to a totpPostgres instance.
+func NewTOTPPostgres(db *db.Postgres) *totpPostgres {
+	return &totpPostgres{db}
+}
+
+// Create creates a new unconfirmed TOTP secret
+// or replaces existing unconfirmed secret of user.
+// It returns pointer to an entity.TOTP instance
+// or ErrExists if user's secret is already confirmed.
+func (t *totpPostgres) Create(ctx context.Context, data entity.TOTP) (*entity.TOTP, error) {
+	const query = `INSERT INTO totp(user_id, secret, sealed) VALUES ($1, $2, $3) ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, sealed = EXCLUDED.sealed, last_counter = 0 WHERE NOT totp.confirmed RETURNING *`
+
+	rows, err := t.Pool.Query(ctx, query, data.UserID, data.Secret, data.Sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	totp, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.TOTP])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrExists
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// GetByUser gets TOTP secret by user ID.
+// It returns pointer to an entity.TOTP instance
+// or nil if user has no secret.
+func (t *totpPostgres) GetByUser(ctx context.Context, userID uuid.UUID) (*entity.TOTP, error) {
+	const query = `SELECT * FROM totp WHERE user_id = $1`
+
+	rows, err := t.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totp, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.TOTP])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// GetUnsealed gets TOTP secrets that aren't encrypted.
+// It returns slice of entity.TOTP instances.
+func (t *totpPostgres) GetUnsealed(ctx context.Context) ([]entity.TOTP, error) {
+	const query = `SELECT * FROM totp WHERE NOT sealed`
+
+	rows, err := t.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	totps, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.TOTP])
+	if err != nil {
+		return nil, err
+	}
+
+	return totps, nil
+}
+
+// Seal replaces TOTP secret that isn't encrypted with encrypted one by user ID.
+// It returns ErrNoRows if secret was already encrypted or replaced.
+func (t *totpPostgres) Seal(ctx context.Context, userID uuid.UUID, secret []byte) error {
+	const query = `UPDATE totp SET secret = $2, sealed = TRUE WHERE user_id = $1 AND NOT sealed`
+
+	tag, err := t.Pool.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// UseCounter sets counter of the last accepted code by user ID
+// only if counter is greater than previous one.
+// It returns ErrNoRows if counter was already used.
+func (t *totpPostgres) UseCounter(ctx context.Context, userID uuid.UUID, counter int64) error {
+	const query = `UPDATE totp SET last_counter = $2 WHERE user_id = $1 AND last_counter < $2`
+
+	tag, err := t.Pool.Exec(ctx, query, userID, counter)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// Confirm marks TOTP secret as confirmed by user ID.
+func (t *totpPostgres) Confirm(ctx context.Context, userID uuid.UUID) error {
+	const query = `UPDATE totp SET confirmed = TRUE WHERE user_id = $1`
+
+	if _, err := t.Pool.Exec(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByUser deletes TOTP secret by user ID.
+func (t *totpPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	const query = `DELETE FROM totp WHERE user_id = $1`
+
+	if _, err := t.Pool.Exec(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// challengePostgres implements Challenge interface.
+// It represents repository to interact with Postgres.
+type challengePostgres struct {
+	*db.Postgres
+}
+
+// NewChallengePostgres creates a new challengePostgres.
+// It returns pointer to a challengePostgres instance.
+func NewChallengePostgres(db *db.Postgres) *challengePostgres {
+	return &challengePostgres{db}
+}
+
+// Create creates a new challenge.
+// It returns pointer to an entity.Challenge instance.
+func (c *challengePostgres) Create(ctx context.Context, data entity.Challenge) (*entity.Challenge, error) {
+	const query = `INSERT INTO challenge(hash, expires_at, session, user_id) VALUES ($1, $2, $3, $4) RETURNING *`
+
+	rows, err := c.Pool.Query(ctx, query, data.Hash, data.ExpiresAt, data.Session, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Challenge])
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// GetByHash gets a challenge by hash.
+// It returns pointer to an entity.Challenge instance
+// or nil if hash is incorrect.
+func (c *challengePostgres) GetByHash(ctx context.Context, hash []byte) (*entity.Challenge, error) {
+	const query = `SELECT * FROM challenge WHERE hash = $1`
+
+	rows, err := c.Pool.Query(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.Challenge])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// AddAttempt increments number of failed attempts by challenge ID.
+// It returns updated number of attempts.
+func (c *challengePostgres) AddAttempt(ctx context.Context, id uuid.UUID) (int, error) {
+	const query = `UPDATE challenge SET attempts = attempts + 1 WHERE id = $1 RETURNING attempts`
+
+	var attempts int
+	err := c.Pool.QueryRow(ctx, query, id).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNoRows
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// DeleteByID deletes a challenge by ID.
+// It returns ErrNoRows if challenge was already deleted.
+func (c *challengePostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
+	const query = `DELETE FROM challenge WHERE id = $1`
+
+	tag, err := c.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes expired challenges.
+func (c *challengePostgres) DeleteExpired(ctx context.Context) error {
+	const query = `DELETE FROM challenge WHERE expires_at < $1`
+
+	if _, err := c.Pool.Exec(ctx, query, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// recoveryPostgres implements Recovery interface.
+// It represents repository to interact with Postgres.
+type recoveryPostgres struct {
+	*db.Postgres
+}
+
+// NewRecoveryPostgres creates a new recoveryPostgres.
+// It returns pointer to a recoveryPostgres instance.
+func NewRecoveryPostgres(db *db.Postgres) *recoveryPostgres {
+	return &recoveryPostgres{db}
+}
+
+// Replace deletes user's recovery codes and creates new ones
+// with hashes in a single transaction.
+func (r *recoveryPostgres) Replace(ctx context.Context, userID uuid.UUID, hashes [][]byte) error {
+	const (
+		deleteQuery = `DELETE FROM recovery WHERE user_id = $1`
+		insertQuery = `INSERT INTO recovery(hash, user_id) VALUES ($1, $2)`
+	)
+
+	return pgx.BeginFunc(ctx, r.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteQuery, userID); err != nil {
+			return err
+		}
+
+		for _, hash := range hashes {
+			if _, err := tx.Exec(ctx, insertQuery, hash, userID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetByUser gets recovery codes by user ID.
+// It returns slice of entity.RecoveryCode instances.
+func (r *recoveryPostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.RecoveryCode, error) {
+	const query = `SELECT * FROM recovery WHERE user_id = $1`
+
+	rows, err := r.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.RecoveryCode])
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// CountByUser counts recovery codes by user ID.
+// It returns number of remaining codes.
+func (r *recoveryPostgres) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	const query = `SELECT COUNT(*) FROM recovery WHERE user_id = $1`
+
+	var count int
+	if err := r.Pool.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteByID deletes a recovery code by ID.
+// It returns ErrNoRows if code was already deleted.
+func (r *recoveryPostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
+	const query = `DELETE FROM recovery WHERE id = $1`
+
+	tag, err := r.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteByUser deletes user-related recovery codes by user ID.
+func (r *recoveryPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	const query = `DELETE FROM recovery WHERE user_id = $1`
+
+	if _, err := r.Pool.Exec(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
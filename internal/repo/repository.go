@@ -3,6 +3,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/qsoulior/auth-server/internal/entity"
 	"github.com/qsoulior/auth-server/pkg/uuid"
@@ -19,10 +20,28 @@ type Role interface {
 	// It returns pointer to an entity.Role instance.
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Role, error)
 
+	// GetByTitle gets a role by unique title.
+	// It returns pointer to an entity.Role instance.
+	GetByTitle(ctx context.Context, title string) (*entity.Role, error)
+
 	// GetByUser gets roles by user ID.
 	// It returns slice of entity.Role instances.
 	GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.Role, error)
 
+	// GetAll gets all roles ordered by title.
+	// It returns slice of entity.Role instances.
+	GetAll(ctx context.Context) ([]entity.Role, error)
+
+	// Update updates role's title and description by ID.
+	// It returns pointer to an entity.Role instance.
+	Update(ctx context.Context, data entity.Role) (*entity.Role, error)
+
+	// AddUser assigns a role to user by role ID and user ID.
+	AddUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
+	// RemoveUser unassigns a role from user by role ID and user ID.
+	RemoveUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
 	// DeleteByID deletes a role by ID.
 	DeleteByID(ctx context.Context, id uuid.UUID) error
 
@@ -30,6 +49,17 @@ type Role interface {
 	DeleteByUser(ctx context.Context, userID uuid.UUID) error
 }
 
+// UserFilter represents filter and page of users.
+// Empty fields aren't used in filter.
+// Users are ordered by name and page starts after user with name After.
+type UserFilter struct {
+	NamePrefix string
+	Role       string
+	Status     string
+	After      string
+	Limit      int
+}
+
 // User is interface implemented by types
 // that can interact with user entity.
 type User interface {
@@ -41,15 +71,59 @@ type User interface {
 	// It returns pointer to an entity.User instance.
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
 
-	// GetByName gets a user by unique name.
+	// GetByName gets a user by unique name with folded case.
 	// It returns pointer to an entity.User instance.
-	GetByName(ctx context.Context, name string) (*entity.User, error)
+	GetByName(ctx context.Context, nameKey string) (*entity.User, error)
+
+	// GetByEmail gets a user by unique verified email.
+	// It returns pointer to an entity.User instance.
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+
+	// GetByRole gets users by role ID.
+	// It returns slice of entity.User instances.
+	GetByRole(ctx context.Context, roleID uuid.UUID) ([]entity.User, error)
 
-	// UpdatePassword updates user's password by user ID.
+	// GetByFilter gets page of users matching filter.
+	// It returns slice of entity.User instances.
+	GetByFilter(ctx context.Context, filter UserFilter) ([]entity.User, error)
+
+	// UpdateName updates user's name and name with folded case by user ID.
+	UpdateName(ctx context.Context, id uuid.UUID, name string, nameKey string) error
+
+	// UpdateEmail updates user's email by user ID and marks it as unverified.
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string) error
+
+	// VerifyEmail marks user's email as verified by user ID
+	// if user's email is still equal to email.
+	// It returns ErrExists if email is already verified by another user.
+	VerifyEmail(ctx context.Context, id uuid.UUID, email string) error
+
+	// UpdatePassword updates user's password by user ID
+	// and clears required password change.
 	UpdatePassword(ctx context.Context, id uuid.UUID, password []byte) error
 
-	// DeleteByID deletes a user by ID.
+	// UpdateHash updates user's password hash by user ID
+	// without changing time of password change.
+	UpdateHash(ctx context.Context, id uuid.UUID, password []byte) error
+
+	// UpdateAttributes updates user's custom attributes by user ID.
+	UpdateAttributes(ctx context.Context, id uuid.UUID, attributes map[string]any) error
+
+	// UpdateStatus updates user's status, reason and end of suspension by user ID.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string, reason string, until *time.Time) error
+
+	// UpdateDeletedAt sets or clears time of user's deletion request by user ID.
+	UpdateDeletedAt(ctx context.Context, id uuid.UUID, deletedAt *time.Time) error
+
+	// RequirePasswordChange marks that user must change password by user ID.
+	RequirePasswordChange(ctx context.Context, id uuid.UUID) error
+
+	// DeleteByID deletes a user by ID with user's events.
 	DeleteByID(ctx context.Context, id uuid.UUID) error
+
+	// DeleteRequested deletes users who requested deletion before time with their events.
+	// It returns slice of deleted users IDs.
+	DeleteRequested(ctx context.Context, before time.Time) ([]uuid.UUID, error)
 }
 
 // Token is interface implemented by types
@@ -63,13 +137,266 @@ type Token interface {
 	// It returns pointer to an entity.RefreshToken instance.
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.RefreshToken, error)
 
-	// GetByUser gets refresh tokens by user ID.
+	// GetByHash gets a refresh token by unique hash of opaque token.
+	// It returns pointer to an entity.RefreshToken instance.
+	GetByHash(ctx context.Context, hash []byte) (*entity.RefreshToken, error)
+
+	// GetByUser gets unused refresh tokens by user ID.
 	// It returns slice of entity.RefreshToken instances.
 	GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.RefreshToken, error)
 
+	// MarkUsed sets usage time, successor ID and encrypted successor
+	// of unused refresh token by ID, so that it cannot be rotated twice.
+	// It returns pointer to an entity.RefreshToken instance.
+	MarkUsed(ctx context.Context, id uuid.UUID, replacedBy uuid.UUID, successor []byte) (*entity.RefreshToken, error)
+
 	// DeleteByID deletes a refresh token by ID.
 	DeleteByID(ctx context.Context, id uuid.UUID) error
 
+	// DeleteByFamily deletes refresh tokens by family ID.
+	DeleteByFamily(ctx context.Context, familyID uuid.UUID) error
+
 	// DeleteByUser deletes user-related refresh tokens by user ID.
 	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteExpired deletes expired refresh tokens.
+	DeleteExpired(ctx context.Context) error
+}
+
+// Assertion is interface implemented by types
+// that can interact with SAML assertion entity.
+type Assertion interface {
+	// Create creates a new assertion without login code and user,
+	// so that assertion is reserved before user is provisioned.
+	// It returns ErrExists if assertion with the same ID was consumed before.
+	Create(ctx context.Context, id string, expiresAt time.Time) error
+
+	// UpdateCode sets login code issued for assertion and user authenticated by it.
+	// It returns pointer to an entity.Assertion instance.
+	UpdateCode(ctx context.Context, data entity.Assertion) (*entity.Assertion, error)
+
+	// ConsumeByCode gets an assertion by login code and clears the code,
+	// so that it cannot be used twice.
+	// It returns pointer to an entity.Assertion instance.
+	ConsumeByCode(ctx context.Context, code []byte) (*entity.Assertion, error)
+
+	// DeleteExpired deletes assertions that can no longer be replayed.
+	DeleteExpired(ctx context.Context) error
+}
+
+// Event is interface implemented by types
+// that can interact with event entity.
+type Event interface {
+	// Create creates a new event.
+	// It returns pointer to an entity.Event instance.
+	Create(ctx context.Context, data entity.Event) (*entity.Event, error)
+
+	// GetByUser gets events by user ID.
+	// It returns slice of entity.Event instances.
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.Event, error)
+}
+
+// Reset is interface implemented by types
+// that can interact with password reset token entity.
+type Reset interface {
+	// Create creates a new reset token.
+	// It returns pointer to an entity.ResetToken instance.
+	Create(ctx context.Context, data entity.ResetToken) (*entity.ResetToken, error)
+
+	// GetByHash gets a reset token by hash without consuming it.
+	// It returns pointer to an entity.ResetToken instance.
+	GetByHash(ctx context.Context, hash []byte) (*entity.ResetToken, error)
+
+	// ConsumeByHash deletes a reset token by hash,
+	// so that it cannot be used twice.
+	// It returns pointer to an entity.ResetToken instance.
+	ConsumeByHash(ctx context.Context, hash []byte) (*entity.ResetToken, error)
+
+	// DeleteByUser deletes user-related reset tokens by user ID.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteExpired deletes expired reset tokens.
+	DeleteExpired(ctx context.Context) error
+}
+
+// TOTP is interface implemented by types
+// that can interact with TOTP secret entity.
+type TOTP interface {
+	// Create creates a new unconfirmed TOTP secret
+	// or replaces existing unconfirmed secret of user.
+	// It returns ErrExists if user's secret is already confirmed.
+	Create(ctx context.Context, data entity.TOTP) (*entity.TOTP, error)
+
+	// GetByUser gets TOTP secret by user ID.
+	// It returns pointer to an entity.TOTP instance.
+	GetByUser(ctx context.Context, userID uuid.UUID) (*entity.TOTP, error)
+
+	// GetUnsealed gets TOTP secrets that aren't encrypted.
+	// It returns slice of entity.TOTP instances.
+	GetUnsealed(ctx context.Context) ([]entity.TOTP, error)
+
+	// Seal replaces TOTP secret that isn't encrypted with encrypted one by user ID.
+	Seal(ctx context.Context, userID uuid.UUID, secret []byte) error
+
+	// UseCounter sets counter of the last accepted code by user ID
+	// only if counter is greater than previous one,
+	// so that the same code cannot be used twice.
+	UseCounter(ctx context.Context, userID uuid.UUID, counter int64) error
+
+	// Confirm marks TOTP secret as confirmed by user ID.
+	Confirm(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteByUser deletes TOTP secret by user ID.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Challenge is interface implemented by types
+// that can interact with authentication challenge entity.
+type Challenge interface {
+	// Create creates a new challenge.
+	// It returns pointer to an entity.Challenge instance.
+	Create(ctx context.Context, data entity.Challenge) (*entity.Challenge, error)
+
+	// GetByHash gets a challenge by unique hash of challenge token.
+	// It returns pointer to an entity.Challenge instance.
+	GetByHash(ctx context.Context, hash []byte) (*entity.Challenge, error)
+
+	// AddAttempt increments number of failed attempts by challenge ID.
+	// It returns updated number of attempts.
+	AddAttempt(ctx context.Context, id uuid.UUID) (int, error)
+
+	// DeleteByID deletes a challenge by ID,
+	// so that it cannot be completed twice.
+	DeleteByID(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired deletes expired challenges.
+	DeleteExpired(ctx context.Context) error
+}
+
+// Credential is interface implemented by types
+// that can interact with WebAuthn credential entity.
+type Credential interface {
+	// Create creates a new credential.
+	// It returns ErrExists if credential with the same ID is already registered.
+	Create(ctx context.Context, data entity.Credential) (*entity.Credential, error)
+
+	// GetByID gets a credential by ID.
+	// It returns pointer to an entity.Credential instance.
+	GetByID(ctx context.Context, id []byte) (*entity.Credential, error)
+
+	// GetByUser gets credentials by user ID.
+	// It returns slice of entity.Credential instances.
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.Credential, error)
+
+	// UpdateSignCount sets sign counter from prev to next and usage time by credential ID,
+	// so that the same assertion cannot be accepted concurrently.
+	UpdateSignCount(ctx context.Context, id []byte, prev int64, next int64) error
+
+	// DeleteByID deletes a credential by ID.
+	DeleteByID(ctx context.Context, id []byte) error
+
+	// DeleteByUser deletes user-related credentials by user ID.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Ceremony is interface implemented by types
+// that can interact with WebAuthn ceremony entity.
+type Ceremony interface {
+	// Create creates a new ceremony.
+	// It returns pointer to an entity.Ceremony instance.
+	Create(ctx context.Context, data entity.Ceremony) (*entity.Ceremony, error)
+
+	// ConsumeByID deletes a ceremony by ID,
+	// so that it cannot be completed twice.
+	// It returns pointer to an entity.Ceremony instance.
+	ConsumeByID(ctx context.Context, id uuid.UUID) (*entity.Ceremony, error)
+
+	// DeleteExpired deletes expired ceremonies.
+	DeleteExpired(ctx context.Context) error
+}
+
+// Recovery is interface implemented by types
+// that can interact with recovery code entity.
+type Recovery interface {
+	// Replace deletes user's recovery codes and creates new ones with hashes,
+	// so that the old set cannot be used.
+	Replace(ctx context.Context, userID uuid.UUID, hashes [][]byte) error
+
+	// GetByUser gets recovery codes by user ID.
+	// It returns slice of entity.RecoveryCode instances.
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.RecoveryCode, error)
+
+	// CountByUser counts recovery codes by user ID.
+	// It returns number of remaining codes.
+	CountByUser(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// DeleteByID deletes a recovery code by ID,
+	// so that it cannot be used twice.
+	DeleteByID(ctx context.Context, id uuid.UUID) error
+
+	// DeleteByUser deletes user-related recovery codes by user ID.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Attempt is interface implemented by types
+// that can interact with failed authentication attempt entity.
+type Attempt interface {
+	// GetByKeys gets attempts by keys.
+	// It returns slice of entity.Attempt instances.
+	GetByKeys(ctx context.Context, keys []string) ([]entity.Attempt, error)
+
+	// AddFailure increments number of failures by key
+	// starting over if the last failure was before since.
+	// It returns pointer to an updated entity.Attempt instance.
+	AddFailure(ctx context.Context, key string, since time.Time) (*entity.Attempt, error)
+
+	// Acquire increments number of attempts by key only if key isn't locked
+	// and number of attempts is less than threshold,
+	// starting over if the last attempt was before since.
+	// It returns pointer to an updated entity.Attempt instance
+	// or ErrNoRows if key is locked or threshold is reached.
+	Acquire(ctx context.Context, key string, threshold int, since time.Time) (*entity.Attempt, error)
+
+	// Release decrements number of attempts by key.
+	Release(ctx context.Context, key string) error
+
+	// Lock sets time until which attempts are rejected by key.
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// DeleteByKey deletes attempts by key.
+	DeleteByKey(ctx context.Context, key string) error
+
+	// DeleteExpired deletes attempts that aren't locked and were last updated before since.
+	DeleteExpired(ctx context.Context, since time.Time) error
+}
+
+// History is interface implemented by types
+// that can interact with password history entity.
+type History interface {
+	// Create creates a new password history entry
+	// and deletes user's entries except the last size ones.
+	Create(ctx context.Context, data entity.PasswordHistory, size int) error
+
+	// GetByUser gets the last limit password history entries by user ID.
+	// It returns slice of entity.PasswordHistory instances.
+	GetByUser(ctx context.Context, userID uuid.UUID, limit int) ([]entity.PasswordHistory, error)
+}
+
+// MagicLink is interface implemented by types
+// that can interact with magic link token entity.
+type MagicLink interface {
+	// Create creates a new magic link token.
+	// It returns pointer to an entity.MagicLink instance.
+	Create(ctx context.Context, data entity.MagicLink) (*entity.MagicLink, error)
+
+	// ConsumeByHash deletes a magic link token by hash,
+	// so that it cannot be used twice.
+	// It returns pointer to an entity.MagicLink instance.
+	ConsumeByHash(ctx context.Context, hash []byte) (*entity.MagicLink, error)
+
+	// DeleteByUser deletes user-related magic link tokens by user ID.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteExpired deletes expired magic link tokens.
+	DeleteExpired(ctx context.Context) error
 }
@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/db"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// resetPostgres implements Reset interface.
+// It represents repository to interact with Postgres.
+type resetPostgres struct {
+	*db.Postgres
+}
+
+// NewResetPostgres creates a new resetPostgres.
+// It returns pointer to a resetPostgres instance.
+func NewResetPostgres(db *db.Postgres) *resetPostgres {
+	return &resetPostgres{db}
+}
+
+// Create creates a new reset token.
+// It returns pointer to an entity.ResetToken instance.
+func (r *resetPostgres) Create(ctx context.Context, data entity.ResetToken) (*entity.ResetToken, error) {
+	const query = `INSERT INTO reset(hash, expires_at, user_id) VALUES ($1, $2, $3) RETURNING *`
+
+	rows, err := r.Pool.Query(ctx, query, data.Hash, data.ExpiresAt, data.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.ResetToken])
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetByHash gets a reset token by hash without consuming it.
+// It returns pointer to an entity.ResetToken instance
+// or nil if hash is incorrect.
+func (r *resetPostgres) GetByHash(ctx context.Context, hash []byte) (*entity.ResetToken, error) {
+	const query = `SELECT * FROM reset WHERE hash = $1`
+
+	rows, err := r.Pool.Query(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.ResetToken])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ConsumeByHash deletes a reset token by hash and returns it.
+// It returns pointer to an entity.ResetToken instance
+// or nil if hash is incorrect or token is already consumed.
+func (r *resetPostgres) ConsumeByHash(ctx context.Context, hash []byte) (*entity.ResetToken, error) {
+	const query = `DELETE FROM reset WHERE hash = $1 RETURNING *`
+
+	rows, err := r.Pool.Query(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[entity.ResetToken])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeleteByUser deletes user-related reset tokens by user ID.
+func (r *resetPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	const query = `DELETE FROM reset WHERE user_id = $1`
+
+	if _, err := r.Pool.Exec(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes expired reset tokens.
+func (r *resetPostgres) DeleteExpired(ctx context.Context) error {
+	const query = `DELETE FROM reset WHERE expires_at < $1`
+
+	if _, err := r.Pool.Exec(ctx, query, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -57,6 +57,26 @@ func (r *rolePostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.Role,
 	return &role, nil
 }
 
+// GetByTitle gets a role by unique title.
+// It returns pointer to an entity.Role instance
+// or nil if title is incorrect.
+func (r *rolePostgres) GetByTitle(ctx context.Context, title string) (*entity.Role, error) {
+	const query = `SELECT * FROM role WHERE title = $1`
+
+	var role entity.Role
+	err := r.Pool.QueryRow(ctx, query, title).Scan(&role.ID, &role.Title, &role.Description)
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
 // GetByUser gets roles by user ID.
 // It returns slice of entity.Role instances.
 func (r *rolePostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.Role, error) {
@@ -75,6 +95,67 @@ func (r *rolePostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]entit
 	return roles, nil
 }
 
+// GetAll gets all roles ordered by title.
+// It returns slice of entity.Role instances.
+func (r *rolePostgres) GetAll(ctx context.Context) ([]entity.Role, error) {
+	const query = `SELECT * FROM role ORDER BY title`
+
+	rows, err := r.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.Role])
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Update updates role's title and description by ID.
+// It returns pointer to an entity.Role instance
+// or nil if id is incorrect.
+func (r *rolePostgres) Update(ctx context.Context, data entity.Role) (*entity.Role, error) {
+	const query = `UPDATE role SET title = $2, description = $3 WHERE id = $1 RETURNING *`
+
+	var role entity.Role
+	err := r.Pool.QueryRow(ctx, query, data.ID, data.Title, data.Description).Scan(&role.ID, &role.Title, &role.Description)
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// AddUser assigns a role to user by role ID and user ID.
+// It does nothing if role is already assigned.
+func (r *rolePostgres) AddUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	const query = `INSERT INTO user_role(role_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	if _, err := r.Pool.Exec(ctx, query, id, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveUser unassigns a role from user by role ID and user ID.
+func (r *rolePostgres) RemoveUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	const query = `DELETE FROM user_role WHERE role_id = $1 AND user_id = $2`
+
+	if _, err := r.Pool.Exec(ctx, query, id, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DeleteByID deletes a role by ID.
 func (r *rolePostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
 	const query = `DELETE FROM role WHERE id = $1`
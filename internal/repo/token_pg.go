@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/qsoulior/auth-server/internal/entity"
@@ -26,9 +27,9 @@ func NewTokenPostgres(db *db.Postgres) *tokenPostgres {
 // It returns pointer to an entity.RefreshToken instance
 // or nil if data is incorrect.
 func (t *tokenPostgres) Create(ctx context.Context, data entity.RefreshToken) (*entity.RefreshToken, error) {
-	const query = `INSERT INTO token(expires_at, fingerprint, is_session, user_id) VALUES ($1, $2, $3, $4) RETURNING *`
+	const query = `INSERT INTO token(expires_at, fingerprint, is_session, user_id, family_id, hash) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *`
 
-	rows, err := t.Pool.Query(ctx, query, data.ExpiresAt, data.Fingerprint, data.Session, data.UserID)
+	rows, err := t.Pool.Query(ctx, query, data.ExpiresAt, data.Fingerprint, data.Session, data.UserID, data.FamilyID, data.Hash)
 	if err != nil {
 		return nil, err
 	}
@@ -64,10 +65,33 @@ func (t *tokenPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.Refr
 	return &token, nil
 }
 
-// GetByUser gets refresh tokens by user ID.
+// GetByHash gets a refresh token by hash of opaque token.
+// It returns pointer to an entity.RefreshToken instance
+// or nil if hash is incorrect.
+func (t *tokenPostgres) GetByHash(ctx context.Context, hash []byte) (*entity.RefreshToken, error) {
+	const query = `SELECT * FROM token WHERE hash = $1`
+
+	rows, err := t.Pool.Query(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pgx.CollectOneRow[entity.RefreshToken](rows, pgx.RowToStructByPos[entity.RefreshToken])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetByUser gets unused refresh tokens by user ID.
 // It returns slice of entity.RefreshToken instances.
 func (t *tokenPostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]entity.RefreshToken, error) {
-	const query = `SELECT * FROM token WHERE user_id = $1 ORDER BY expires_at`
+	const query = `SELECT * FROM token WHERE user_id = $1 AND used_at IS NULL ORDER BY expires_at`
 
 	rows, err := t.Pool.Query(ctx, query, userID)
 	if err != nil {
@@ -82,6 +106,29 @@ func (t *tokenPostgres) GetByUser(ctx context.Context, userID uuid.UUID) ([]enti
 	return tokens, nil
 }
 
+// MarkUsed sets usage time, successor ID and encrypted successor of unused refresh token by ID.
+// It returns pointer to an entity.RefreshToken instance
+// or nil if id is incorrect or token is already used.
+func (t *tokenPostgres) MarkUsed(ctx context.Context, id uuid.UUID, replacedBy uuid.UUID, successor []byte) (*entity.RefreshToken, error) {
+	const query = `UPDATE token SET used_at = $2, replaced_by = $3, successor = $4 WHERE id = $1 AND used_at IS NULL RETURNING *`
+
+	rows, err := t.Pool.Query(ctx, query, id, time.Now(), replacedBy, successor)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := pgx.CollectOneRow[entity.RefreshToken](rows, pgx.RowToStructByPos[entity.RefreshToken])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
 // DeleteByID deletes a refresh token by ID.
 func (t *tokenPostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
 	const query = `DELETE FROM token WHERE id = $1`
@@ -93,6 +140,17 @@ func (t *tokenPostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// DeleteByFamily deletes refresh tokens by family ID.
+func (t *tokenPostgres) DeleteByFamily(ctx context.Context, familyID uuid.UUID) error {
+	const query = `DELETE FROM token WHERE family_id = $1`
+
+	if _, err := t.Pool.Exec(ctx, query, familyID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DeleteByUser deletes user-related refresh tokens by user ID.
 func (t *tokenPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
 	const query = `DELETE FROM token WHERE user_id = $1`
@@ -103,3 +161,14 @@ func (t *tokenPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) erro
 
 	return nil
 }
+
+// DeleteExpired deletes expired refresh tokens.
+func (t *tokenPostgres) DeleteExpired(ctx context.Context) error {
+	const query = `DELETE FROM token WHERE expires_at < $1`
+
+	if _, err := t.Pool.Exec(ctx, query, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,9 @@ package repo
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/qsoulior/auth-server/internal/entity"
@@ -9,6 +12,9 @@ import (
 	"github.com/qsoulior/auth-server/pkg/uuid"
 )
 
+// likeEscaper escapes wildcard characters of LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // userPostgres implements User interface.
 // It represents repository to interact with Postgres.
 type userPostgres struct {
@@ -21,14 +27,22 @@ func NewUserPostgres(db *db.Postgres) *userPostgres {
 	return &userPostgres{db}
 }
 
+// userFields gets pointers to user fields in order of table columns.
+// It returns slice of pointers to scan row into.
+func userFields(user *entity.User) []any {
+	return []any{&user.ID, &user.Name, &user.Password, &user.Email, &user.EmailVerified, &user.PasswordChangedAt, &user.MustChangePassword, &user.NameKey, &user.Attributes, &user.Status, &user.StatusReason, &user.SuspendedUntil, &user.DeletedAt, &user.Source}
+}
+
 // Create creates a new user.
+// Attributes are set to empty object if they are nil,
+// source is set to local if it is empty.
 // It returns pointer to an entity.User instance
 // or nil if data is incorrect.
 func (u *userPostgres) Create(ctx context.Context, data entity.User) (*entity.User, error) {
-	const query = `INSERT INTO "user"(name, password) VALUES ($1, $2) RETURNING *`
+	const query = `INSERT INTO "user"(name, name_key, password, attributes, source) VALUES ($1, $2, $3, COALESCE($4, '{}'::jsonb), COALESCE(NULLIF($5, ''), 'local')) RETURNING *`
 
 	var user entity.User
-	err := u.Pool.QueryRow(ctx, query, data.Name, data.Password).Scan(&user.ID, &user.Name, &user.Password)
+	err := u.Pool.QueryRow(ctx, query, data.Name, data.NameKey, data.Password, data.Attributes, data.Source).Scan(userFields(&user)...)
 
 	if err != nil {
 		return nil, err
@@ -44,7 +58,7 @@ func (u *userPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.User,
 	const query = `SELECT * FROM "user" WHERE id = $1`
 
 	var user entity.User
-	err := u.Pool.QueryRow(ctx, query, id).Scan(&user.ID, &user.Name, &user.Password)
+	err := u.Pool.QueryRow(ctx, query, id).Scan(userFields(&user)...)
 
 	if err == pgx.ErrNoRows {
 		return nil, ErrNoRows
@@ -57,14 +71,14 @@ func (u *userPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.User,
 	return &user, nil
 }
 
-// GetByName gets a user by unique name.
+// GetByName gets a user by unique name with folded case.
 // It returns pointer to an entity.User instance
 // or nil if name is incorrect.
-func (u *userPostgres) GetByName(ctx context.Context, name string) (*entity.User, error) {
-	const query = `SELECT * FROM "user" WHERE name = $1`
+func (u *userPostgres) GetByName(ctx context.Context, nameKey string) (*entity.User, error) {
+	const query = `SELECT * FROM "user" WHERE name_key = $1`
 
 	var user entity.User
-	err := u.Pool.QueryRow(ctx, query, name).Scan(&user.ID, &user.Name, &user.Password)
+	err := u.Pool.QueryRow(ctx, query, nameKey).Scan(userFields(&user)...)
 
 	if err == pgx.ErrNoRows {
 		return nil, ErrNoRows
@@ -77,8 +91,155 @@ func (u *userPostgres) GetByName(ctx context.Context, name string) (*entity.User
 	return &user, nil
 }
 
-// UpdatePassword updates user's password by user ID.
+// GetByEmail gets a user by unique verified email.
+// Unverified emails aren't unique and aren't matched.
+// It returns pointer to an entity.User instance
+// or nil if email is incorrect.
+func (u *userPostgres) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	const query = `SELECT * FROM "user" WHERE email = $1 AND email_verified`
+
+	var user entity.User
+	err := u.Pool.QueryRow(ctx, query, email).Scan(userFields(&user)...)
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrNoRows
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetByRole gets users by role ID.
+// It returns slice of entity.User instances ordered by name.
+func (u *userPostgres) GetByRole(ctx context.Context, roleID uuid.UUID) ([]entity.User, error) {
+	const query = `SELECT "user".* FROM (SELECT * FROM user_role WHERE role_id = $1) AS user_role JOIN "user" ON user_role.user_id = "user".id ORDER BY name`
+
+	rows, err := u.Pool.Query(ctx, query, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.User])
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetByFilter gets page of users matching filter.
+// Name prefix with folded case is matched literally and users are ordered by name.
+// It returns slice of entity.User instances.
+func (u *userPostgres) GetByFilter(ctx context.Context, filter UserFilter) ([]entity.User, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if filter.After != "" {
+		args = append(args, filter.After)
+		conditions = append(conditions, fmt.Sprintf("name > $%d", len(args)))
+	}
+
+	if filter.NamePrefix != "" {
+		args = append(args, likeEscaper.Replace(filter.NamePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("name_key LIKE $%d", len(args)))
+	}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM user_role JOIN role ON user_role.role_id = role.id WHERE user_role.user_id = "user".id AND role.title = $%d)`, len(args)))
+	}
+
+	query := `SELECT * FROM "user"`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY name LIMIT $%d", len(args))
+
+	rows, err := u.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entity.User])
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// UpdateName updates user's name and name with folded case by user ID.
+func (u *userPostgres) UpdateName(ctx context.Context, id uuid.UUID, name string, nameKey string) error {
+	const query = `UPDATE "user" SET name = $2, name_key = $3 WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id, name, nameKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateEmail updates user's email by user ID and marks it as unverified.
+func (u *userPostgres) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	const query = `UPDATE "user" SET email = $2, email_verified = FALSE WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id, email); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// VerifyEmail marks user's email as verified by user ID
+// if user's email is still equal to email.
+// It returns ErrNoRows if user doesn't exist or email was changed
+// and ErrExists if email is already verified by another user.
+func (u *userPostgres) VerifyEmail(ctx context.Context, id uuid.UUID, email string) error {
+	const query = `UPDATE "user" SET email_verified = TRUE WHERE id = $1 AND email = $2`
+
+	tag, err := u.Pool.Exec(ctx, query, id, email)
+	if isUniqueViolation(err) {
+		return ErrExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdatePassword updates user's password by user ID,
+// sets time of password change and clears required password change.
 func (u *userPostgres) UpdatePassword(ctx context.Context, id uuid.UUID, password []byte) error {
+	const query = `UPDATE "user" SET password = $2, password_changed_at = $3, must_change_password = FALSE WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id, password, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateHash updates user's password hash by user ID
+// without changing time of password change.
+func (u *userPostgres) UpdateHash(ctx context.Context, id uuid.UUID, password []byte) error {
 	const query = `UPDATE "user" SET password = $2 WHERE id = $1`
 
 	if _, err := u.Pool.Exec(ctx, query, id, password); err != nil {
@@ -88,9 +249,53 @@ func (u *userPostgres) UpdatePassword(ctx context.Context, id uuid.UUID, passwor
 	return nil
 }
 
-// DeleteByID deletes a user by ID.
+// UpdateAttributes updates user's custom attributes by user ID.
+func (u *userPostgres) UpdateAttributes(ctx context.Context, id uuid.UUID, attributes map[string]any) error {
+	const query = `UPDATE "user" SET attributes = $2 WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id, attributes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStatus updates user's status, reason and end of suspension by user ID.
+func (u *userPostgres) UpdateStatus(ctx context.Context, id uuid.UUID, status string, reason string, until *time.Time) error {
+	const query = `UPDATE "user" SET status = $2, status_reason = $3, suspended_until = $4 WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id, status, reason, until); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateDeletedAt sets or clears time of user's deletion request by user ID.
+func (u *userPostgres) UpdateDeletedAt(ctx context.Context, id uuid.UUID, deletedAt *time.Time) error {
+	const query = `UPDATE "user" SET deleted_at = $2 WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id, deletedAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RequirePasswordChange marks that user must change password by user ID.
+func (u *userPostgres) RequirePasswordChange(ctx context.Context, id uuid.UUID) error {
+	const query = `UPDATE "user" SET must_change_password = TRUE WHERE id = $1`
+
+	if _, err := u.Pool.Exec(ctx, query, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByID deletes a user by ID with user's events.
 func (u *userPostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
-	const query = `DELETE FROM "user" WHERE id = $1`
+	const query = `WITH deleted AS (DELETE FROM "user" WHERE id = $1 RETURNING id) DELETE FROM event WHERE user_id IN (SELECT id FROM deleted)`
 
 	if _, err := u.Pool.Exec(ctx, query, id); err != nil {
 		return err
@@ -98,3 +303,21 @@ func (u *userPostgres) DeleteByID(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// DeleteRequested deletes users who requested deletion before time with their events.
+// It returns slice of deleted users IDs.
+func (u *userPostgres) DeleteRequested(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	const query = `WITH deleted AS (DELETE FROM "user" WHERE deleted_at < $1 RETURNING id), events AS (DELETE FROM event WHERE user_id IN (SELECT id FROM deleted)) SELECT id FROM deleted`
+
+	rows, err := u.Pool.Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
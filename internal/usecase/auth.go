@@ -19,24 +19,58 @@ func NewAuth(jwt jwt.Parser) *auth {
 	return &auth{jwt}
 }
 
-// Verify verifies user's fingerprint, parses access token,
-// and retrieves user ID and roles from it.
-// It returns user ID and roles if token is correct and not expired.
-func (a *auth) Verify(token entity.AccessToken, fp []byte) (uuid.UUID, []string, error) {
+// parse parses access token and verifies user's fingerprint.
+// It returns pointer to a jwt.Claims instance and user ID
+// if token is correct and not expired.
+func (a *auth) parse(token entity.AccessToken, fp []byte) (*jwt.Claims, uuid.UUID, error) {
 	claims, err := a.jwt.Parse(string(token))
 	if err != nil {
-		return uuid.UUID{}, nil, NewError(ErrTokenInvalid, true)
+		return nil, uuid.UUID{}, NewError(ErrTokenInvalid, true)
 	}
 
 	userID, err := uuid.FromString(claims.Subject)
 	if err != nil {
-		return uuid.UUID{}, nil, NewError(ErrUserIDInvalid, true)
+		return nil, uuid.UUID{}, NewError(ErrUserIDInvalid, true)
 	}
 
 	fpObj := fingerprint.New(userID, fp)
 	if err := fpObj.Verify(hash.FromHexString(claims.Fingerprint)); err != nil {
-		return uuid.UUID{}, nil, NewError(err, true)
+		return nil, uuid.UUID{}, NewError(err, true)
+	}
+
+	return claims, userID, nil
+}
+
+// Verify verifies user's fingerprint, parses access token,
+// and retrieves user ID and roles from it.
+// Restricted tokens aren't accepted.
+// It returns user ID and roles if token is correct and not expired.
+func (a *auth) Verify(token entity.AccessToken, fp []byte) (uuid.UUID, []string, error) {
+	claims, userID, err := a.parse(token, fp)
+	if err != nil {
+		return uuid.UUID{}, nil, err
+	}
+
+	if claims.Scope != "" {
+		return uuid.UUID{}, nil, NewError(ErrTokenRestricted, true)
 	}
 
 	return userID, claims.Roles, nil
 }
+
+// VerifyScope verifies user's fingerprint, parses access token,
+// and retrieves user ID from it.
+// Both unrestricted tokens and tokens restricted to scope are accepted.
+// It returns user ID if token is correct and not expired.
+func (a *auth) VerifyScope(token entity.AccessToken, fp []byte, scope string) (uuid.UUID, error) {
+	claims, userID, err := a.parse(token, fp)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if claims.Scope != "" && claims.Scope != scope {
+		return uuid.UUID{}, NewError(ErrTokenRestricted, true)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/mail"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// normalizeEmail trims spaces and lowercases email address.
+// Address must not contain display name and be longer than 254 characters.
+// It returns normalized address or error if address is invalid.
+func normalizeEmail(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if len(address) > 254 {
+		return "", NewError(ErrEmailInvalid, true)
+	}
+
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return "", NewError(ErrEmailInvalid, true)
+	}
+
+	return strings.ToLower(address), nil
+}
+
+// EmailRepos represents repositories the email use case interacts with.
+type EmailRepos struct {
+	User repo.User
+}
+
+// EmailParams represents parameters for email use case.
+// VerifyURL is URL of page that receives verification token in "token" query parameter.
+type EmailParams struct {
+	VerifyURL string
+	Secret    []byte
+	Age       int
+}
+
+// Validate checks that URL is set, secret is long enough and age is positive.
+// It returns error if at least one of parameters is invalid.
+func (p EmailParams) Validate() error {
+	if p.VerifyURL == "" {
+		return ErrVerifyURLEmpty
+	}
+	if len(p.Secret) < 32 {
+		return ErrSecretInvalid
+	}
+	if p.Age < 1 {
+		return ErrEmailAgeInvalid
+	}
+	return nil
+}
+
+// emailToken represents payload of signed verification token.
+type emailToken struct {
+	UserID    uuid.UUID `json:"sub"`
+	Email     string    `json:"email"`
+	ExpiresAt int64     `json:"exp"`
+}
+
+// email implements Email interface.
+type email struct {
+	repos  EmailRepos
+	params EmailParams
+	mailer mail.Mailer
+}
+
+// NewEmail validates parameters and creates a new email use case.
+// It returns pointer to an email instance or nil if parameters are invalid.
+func NewEmail(repos EmailRepos, params EmailParams, mailer mail.Mailer) (*email, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &email{repos, params, mailer}, nil
+}
+
+// send signs verification token and sends link containing it to address.
+func (e *email) send(name string, userID uuid.UUID, address string) error {
+	age := time.Duration(e.params.Age) * time.Hour
+	payload, err := json.Marshal(emailToken{userID, address, time.Now().Add(age).Unix()})
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	verifyURL, err := url.Parse(e.params.VerifyURL)
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	query := verifyURL.Query()
+	query.Set("token", secret.Sign(e.params.Secret, payload))
+	verifyURL.RawQuery = query.Encode()
+
+	msg := mail.Message{
+		To:      address,
+		Subject: "Verify your email",
+		Body:    fmt.Sprintf("Hello, %s!\n\nTo verify your email, open the link:\n%s\n\nThe link expires in %d hours.", name, verifyURL, e.params.Age),
+	}
+	if err := e.mailer.Send(msg); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// Update validates and normalizes address, updates user's email by user ID
+// and sends verification link to address.
+// Address is unique only among verified emails, so that unverified address
+// doesn't block its owner from using it.
+// Link is sent again if address is equal to current unverified email.
+func (e *email) Update(userID uuid.UUID, address string) error {
+	address, err := normalizeEmail(address)
+	if err != nil {
+		return err
+	}
+
+	user, err := e.repos.User.GetByID(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrUserNotExist, true)
+		}
+		return NewError(err, false)
+	}
+
+	if user.Email == address && user.EmailVerified {
+		return nil
+	}
+
+	existing, err := e.repos.User.GetByEmail(context.Background(), address)
+	if err == nil && existing.ID != user.ID {
+		return NewError(ErrEmailExists, true)
+	} else if err != nil && !errors.Is(err, repo.ErrNoRows) {
+		return NewError(err, false)
+	}
+
+	if user.Email != address {
+		if err := e.repos.User.UpdateEmail(context.Background(), user.ID, address); err != nil {
+			return NewError(err, false)
+		}
+	}
+
+	return e.send(user.Name, user.ID, address)
+}
+
+// Verify verifies signature and expiration of token
+// and marks email from token as verified.
+// It returns error if token is invalid, expired, email was changed
+// or email was verified by another user.
+func (e *email) Verify(token string) error {
+	payload, err := secret.Verify(e.params.Secret, token)
+	if err != nil {
+		return NewError(ErrTokenInvalid, true)
+	}
+
+	var data emailToken
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return NewError(ErrTokenInvalid, true)
+	}
+
+	if time.Unix(data.ExpiresAt, 0).Before(time.Now()) {
+		return NewError(ErrTokenExpired, true)
+	}
+
+	if err := e.repos.User.VerifyEmail(context.Background(), data.UserID, data.Email); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrTokenInvalid, true)
+		}
+		if errors.Is(err, repo.ErrExists) {
+			return NewError(ErrEmailExists, true)
+		}
+		return NewError(err, false)
+	}
+
+	return nil
+}
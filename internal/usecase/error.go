@@ -8,24 +8,114 @@ import (
 )
 
 var (
-	ErrUserExists        = errors.New("user already exists")
-	ErrUserNotExist      = errors.New("user does not exist")
-	ErrUserIDInvalid     = errors.New("user id is invalid")
-	ErrNameInvalid       = errors.New("name is invalid")
-	ErrPasswordInvalid   = errors.New("password is invalid")
-	ErrPasswordIncorrect = errors.New("password is incorrect")
-	ErrTokenIncorrect    = errors.New("token is incorrect")
-	ErrTokenInvalid      = errors.New("token is invalid")
-	ErrTokenExpired      = errors.New("token is expired")
+	ErrUserExists            = errors.New("user already exists")
+	ErrUserNotLinked         = errors.New("user exists and is not linked to identity provider")
+	ErrUserNotLocal          = errors.New("user password is managed by identity provider")
+	ErrUserNotExist          = errors.New("user does not exist")
+	ErrUserIDInvalid         = errors.New("user id is invalid")
+	ErrPasswordInvalid       = errors.New("password is invalid")
+	ErrPasswordIncorrect     = errors.New("password is incorrect")
+	ErrTokenIncorrect        = errors.New("token is incorrect")
+	ErrTokenInvalid          = errors.New("token is invalid")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenReused           = errors.New("token is reused")
+	ErrEmailInvalid          = errors.New("email is invalid")
+	ErrEmailExists           = errors.New("email is already used")
+	ErrEmailNotVerified      = errors.New("email is not verified")
+	ErrCursorInvalid         = errors.New("cursor is invalid")
+	ErrLimitInvalid          = errors.New("limit is out of allowed range [1,100]")
+	ErrRoleExists            = errors.New("role already exists")
+	ErrRoleNotExist          = errors.New("role does not exist")
+	ErrRoleIDInvalid         = errors.New("role id is invalid")
+	ErrTitleInvalid          = errors.New("title is invalid")
+	ErrDescInvalid           = errors.New("description is invalid")
+	ErrAssertionReplayed     = errors.New("assertion is already used")
+	ErrCodeIncorrect         = errors.New("code is incorrect")
+	ErrCodeExpired           = errors.New("code is expired")
+	ErrCodeUsed              = errors.New("code is already used")
+	ErrMFAEnabled            = errors.New("mfa is already enabled")
+	ErrMFANotEnabled         = errors.New("mfa is not enabled")
+	ErrChallengeIncorrect    = errors.New("challenge is incorrect")
+	ErrChallengeExpired      = errors.New("challenge is expired")
+	ErrCeremonyIncorrect     = errors.New("ceremony is incorrect")
+	ErrCeremonyExpired       = errors.New("ceremony is expired")
+	ErrCredentialExists      = errors.New("credential already exists")
+	ErrCredentialNotExist    = errors.New("credential does not exist")
+	ErrCredentialInvalid     = errors.New("credential is invalid")
+	ErrCredentialNameInvalid = errors.New("credential name is invalid")
+	ErrTooManyAttempts       = errors.New("too many failed attempts")
+	ErrTokenRestricted       = errors.New("token is restricted to password change")
+	ErrAttributesInvalid     = errors.New("attributes are invalid")
+	ErrUserDisabled          = errors.New("user is disabled")
+	ErrUserSuspended         = errors.New("user is suspended")
+	ErrUserPending           = errors.New("user is pending activation")
+	ErrStatusInvalid         = errors.New("status is invalid")
+	ErrSuspensionInvalid     = errors.New("suspension end must be set in the future only for suspended user")
+	ErrReasonInvalid         = errors.New("reason is longer than allowed value (256)")
+	ErrUserDeleted           = errors.New("user is pending deletion")
+	ErrUserNotDeleted        = errors.New("user is not pending deletion")
+	ErrTooManyRequests       = errors.New("too many requests")
 )
 
 var (
-	ErrHashCostInvalid   = errors.New("bcrypt cost is out of allowed range [4,31]")
-	ErrAccessAgeInvalid  = errors.New("access token age is out of allowed range [1,60]")
-	ErrRefreshAgeInvalid = errors.New("refresh token age is less than allowed value (1)")
-	ErrRefreshCapInvalid = errors.New("refresh token capacity is less than allowed value (1)")
+	ErrAccessAgeInvalid        = errors.New("access token age is out of allowed range [1,60]")
+	ErrRefreshAgeInvalid       = errors.New("refresh token age is less than allowed value (1)")
+	ErrRefreshCapInvalid       = errors.New("refresh token capacity is less than allowed value (1)")
+	ErrRefreshGraceInvalid     = errors.New("refresh token grace period is out of allowed range [0,60]")
+	ErrRedirectURLEmpty        = errors.New("redirect url is empty")
+	ErrVerifyURLEmpty          = errors.New("verify url is empty")
+	ErrSecretInvalid           = errors.New("secret is shorter than allowed value (32)")
+	ErrEmailAgeInvalid         = errors.New("email link age is less than allowed value (1)")
+	ErrResetURLEmpty           = errors.New("reset url is empty")
+	ErrResetAgeInvalid         = errors.New("reset token age is out of allowed range [1,1440]")
+	ErrChallengeAgeInvalid     = errors.New("challenge age is out of allowed range [1,15]")
+	ErrChallengeLimitInvalid   = errors.New("challenge limit is out of allowed range [1,100]")
+	ErrChallengeWindowInvalid  = errors.New("challenge window is out of allowed range [1,1440]")
+	ErrCeremonyAgeInvalid      = errors.New("ceremony age is out of allowed range [30,600]")
+	ErrHistorySizeInvalid      = errors.New("password history size is out of allowed range [0,24]")
+	ErrPasswordAgeInvalid      = errors.New("password age is out of allowed range [1,3650]")
+	ErrMagicLinkURLEmpty       = errors.New("magic link url is empty")
+	ErrMagicLinkAgeInvalid     = errors.New("magic link age is out of allowed range [1,60]")
+	ErrMagicLinkLimitInvalid   = errors.New("magic link limit is out of allowed range [1,100]")
+	ErrMagicLinkWindowInvalid  = errors.New("magic link window is out of allowed range [1,1440]")
+	ErrDeletionGraceInvalid    = errors.New("deletion grace period is out of allowed range [1,365]")
+	ErrLockoutThresholdInvalid = errors.New("lockout threshold is out of allowed range [1,100]")
+	ErrLockoutDelayInvalid     = errors.New("lockout delay is out of allowed range [0,60]")
+	ErrLockoutDurationInvalid  = errors.New("lockout duration is out of allowed range [1,1440]")
 )
 
+// PasswordError represents error that occurs if password violates policy.
+// Rules are names of failed policy rules.
+type PasswordError struct {
+	Rules []string
+}
+
+// Error returns string representation of PasswordError.
+func (e *PasswordError) Error() string {
+	return ErrPasswordInvalid.Error()
+}
+
+// Is reports whether target is ErrPasswordInvalid.
+func (e *PasswordError) Is(target error) bool {
+	return target == ErrPasswordInvalid
+}
+
+// AttributesError represents error that occurs if attributes violate profile schema.
+// Fields maps names of invalid attributes to reasons.
+type AttributesError struct {
+	Fields map[string]string
+}
+
+// Error returns string representation of AttributesError.
+func (e *AttributesError) Error() string {
+	return ErrAttributesInvalid.Error()
+}
+
+// Is reports whether target is ErrAttributesInvalid.
+func (e *AttributesError) Is(target error) bool {
+	return target == ErrAttributesInvalid
+}
+
 // Error represents error that occurs in use cases.
 type Error struct {
 	Func     string
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/username"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// LockoutRepos represents repositories the lockout use case interacts with.
+type LockoutRepos struct {
+	User    repo.User
+	Attempt repo.Attempt
+}
+
+// LockoutParams represents parameters for lockout use case.
+// Threshold is number of failures after which subject is locked out,
+// Delay is number of seconds subject is delayed after the first failure,
+// the delay is doubled after each next failure.
+// Duration is number of minutes of lockout and also
+// period after which failures are forgotten.
+type LockoutParams struct {
+	Threshold int
+	Delay     int
+	Duration  int
+}
+
+// Validate compares parameters with min and max values.
+// It returns error if at least one of parameters is invalid.
+func (p LockoutParams) Validate() error {
+	if p.Threshold < 1 || p.Threshold > 100 {
+		return ErrLockoutThresholdInvalid
+	}
+	if p.Delay < 0 || p.Delay > 60 {
+		return ErrLockoutDelayInvalid
+	}
+	if p.Duration < 1 || p.Duration > 1440 {
+		return ErrLockoutDurationInvalid
+	}
+	return nil
+}
+
+// lockout implements Lockout interface.
+type lockout struct {
+	repos  LockoutRepos
+	params LockoutParams
+}
+
+// NewLockout validates parameters and creates a new lockout use case.
+// It returns pointer to a lockout instance or nil if parameters are invalid.
+func NewLockout(repos LockoutRepos, params LockoutParams) (*lockout, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &lockout{repos, params}, nil
+}
+
+// nameKey creates attempt key of username regardless of case.
+func nameKey(name string) string {
+	return "name:" + username.Fold(name)
+}
+
+// keys creates attempt keys of username and client IP.
+// IP key is omitted if ip is empty.
+// It returns slice of keys.
+func (l *lockout) keys(name string, ip string) []string {
+	keys := []string{nameKey(name)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+// delay calculates how long subject is locked after number of failures.
+// It returns duration that exponentially grows until threshold is reached.
+func (l *lockout) delay(failures int) time.Duration {
+	max := time.Duration(l.params.Duration) * time.Minute
+	if failures >= l.params.Threshold {
+		return max
+	}
+
+	delay := time.Duration(l.params.Delay) * time.Second
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// Check checks whether username or client IP is locked
+// and counts attempt of those that aren't locked in the same statement,
+// so that concurrent attempts cannot exceed threshold.
+// Counted attempt is considered failed until it is released or forgotten,
+// but subject is locked only by Fail.
+// It returns remaining lock duration and ErrTooManyAttempts if any of them is locked
+// or has threshold reached by attempts in progress.
+func (l *lockout) Check(name string, ip string) (time.Duration, error) {
+	ctx := context.Background()
+	window := time.Duration(l.params.Duration) * time.Minute
+
+	var locked []string
+	for _, key := range l.keys(name, ip) {
+		if _, err := l.repos.Attempt.Acquire(ctx, key, l.params.Threshold, time.Now().Add(-window)); err != nil {
+			if !errors.Is(err, repo.ErrNoRows) {
+				return 0, NewError(err, false)
+			}
+			locked = append(locked, key)
+		}
+	}
+
+	if len(locked) == 0 {
+		return 0, nil
+	}
+
+	attempts, err := l.repos.Attempt.GetByKeys(ctx, locked)
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	var retryAfter time.Duration
+	for _, attempt := range attempts {
+		if remaining := time.Until(attempt.LockedUntil); remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	return retryAfter, NewError(ErrTooManyAttempts, true)
+}
+
+// Fail locks username and client IP for exponentially growing delay
+// after attempt counted by Check failed.
+// It returns duration until next attempt is allowed.
+func (l *lockout) Fail(name string, ip string) (time.Duration, error) {
+	ctx := context.Background()
+	window := time.Duration(l.params.Duration) * time.Minute
+
+	if err := l.repos.Attempt.DeleteExpired(ctx, time.Now().Add(-window)); err != nil {
+		return 0, NewError(err, false)
+	}
+
+	attempts, err := l.repos.Attempt.GetByKeys(ctx, l.keys(name, ip))
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	var retryAfter time.Duration
+	for _, attempt := range attempts {
+		delay := l.delay(attempt.Failures)
+		if delay <= 0 {
+			continue
+		}
+
+		if err := l.repos.Attempt.Lock(ctx, attempt.Key, time.Now().Add(delay)); err != nil {
+			return 0, NewError(err, false)
+		}
+
+		if delay > retryAfter {
+			retryAfter = delay
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// Release uncounts attempt of username and client IP counted by Check
+// after credentials turned out to be correct,
+// even if authentication was rejected for another reason such as user's status.
+func (l *lockout) Release(name string, ip string) error {
+	for _, key := range l.keys(name, ip) {
+		if err := l.repos.Attempt.Release(context.Background(), key); err != nil {
+			return NewError(err, false)
+		}
+	}
+	return nil
+}
+
+// Succeed forgets failed attempts of username after successful authentication.
+// Client IP failures aren't forgotten, so that attacker can't reset them
+// by logging in to own account.
+func (l *lockout) Succeed(name string) error {
+	if err := l.repos.Attempt.DeleteByKey(context.Background(), nameKey(name)); err != nil {
+		return NewError(err, false)
+	}
+	return nil
+}
+
+// Unlock gets a user by ID and forgets failed attempts of user's name.
+func (l *lockout) Unlock(userID uuid.UUID) error {
+	user, err := l.repos.User.GetByID(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrUserNotExist, true)
+		}
+		return NewError(err, false)
+	}
+
+	return l.Succeed(user.Name)
+}
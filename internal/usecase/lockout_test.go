@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/repo"
+)
+
+// attemptMemory implements repo.Attempt interface
+// the same way as attemptPostgres, storing attempts in memory.
+type attemptMemory struct {
+	attempts map[string]*entity.Attempt
+}
+
+func (a *attemptMemory) GetByKeys(ctx context.Context, keys []string) ([]entity.Attempt, error) {
+	var attempts []entity.Attempt
+	for key, attempt := range a.attempts {
+		if slices.Contains(keys, key) {
+			attempts = append(attempts, *attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (a *attemptMemory) AddFailure(ctx context.Context, key string, since time.Time) (*entity.Attempt, error) {
+	now := time.Now()
+	attempt, ok := a.attempts[key]
+	if !ok {
+		attempt = &entity.Attempt{Key: key, LockedUntil: now}
+		a.attempts[key] = attempt
+	}
+	if attempt.UpdatedAt.Before(since) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.UpdatedAt = now
+	result := *attempt
+	return &result, nil
+}
+
+func (a *attemptMemory) Acquire(ctx context.Context, key string, threshold int, since time.Time) (*entity.Attempt, error) {
+	now := time.Now()
+	attempt, ok := a.attempts[key]
+	if !ok {
+		attempt = &entity.Attempt{Key: key, Failures: 1, LockedUntil: now, UpdatedAt: now}
+		a.attempts[key] = attempt
+		result := *attempt
+		return &result, nil
+	}
+	if attempt.LockedUntil.After(now) || (!attempt.UpdatedAt.Before(since) && attempt.Failures >= threshold) {
+		return nil, repo.ErrNoRows
+	}
+	if attempt.UpdatedAt.Before(since) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.UpdatedAt = now
+	result := *attempt
+	return &result, nil
+}
+
+func (a *attemptMemory) Release(ctx context.Context, key string) error {
+	if attempt, ok := a.attempts[key]; ok && attempt.Failures > 0 {
+		attempt.Failures--
+	}
+	return nil
+}
+
+func (a *attemptMemory) Lock(ctx context.Context, key string, until time.Time) error {
+	if attempt, ok := a.attempts[key]; ok && until.After(attempt.LockedUntil) {
+		attempt.LockedUntil = until
+	}
+	return nil
+}
+
+func (a *attemptMemory) DeleteByKey(ctx context.Context, key string) error {
+	delete(a.attempts, key)
+	return nil
+}
+
+func (a *attemptMemory) DeleteExpired(ctx context.Context, since time.Time) error {
+	for key, attempt := range a.attempts {
+		if attempt.UpdatedAt.Before(since) && attempt.LockedUntil.Before(time.Now()) {
+			delete(a.attempts, key)
+		}
+	}
+	return nil
+}
+
+func Test_lockout_Check(t *testing.T) {
+	const (
+		name = "alice"
+		ip   = "192.0.2.1"
+	)
+
+	tests := []struct {
+		name    string
+		steps   []string
+		wantErr error
+	}{
+		{"NoAttempts", nil, nil},
+		{"ThresholdAttemptSucceeds", []string{"fail", "fail", "succeed"}, nil},
+		{"ThresholdAttemptRejectedByStatus", []string{"fail", "fail", "release"}, nil},
+		{"ThresholdAttemptFails", []string{"fail", "fail", "fail"}, ErrTooManyAttempts},
+		{"ThresholdReachedByPending", []string{"fail", "pending", "pending"}, ErrTooManyAttempts},
+		{"PendingAttemptReleased", []string{"pending", "pending", "release"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLockout(LockoutRepos{nil, &attemptMemory{map[string]*entity.Attempt{}}}, LockoutParams{3, 0, 15})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, step := range tt.steps {
+				if _, err := l.Check(name, ip); err != nil {
+					t.Fatalf("lockout.Check() before %s error = %v", step, err)
+				}
+
+				switch step {
+				case "fail":
+					_, err = l.Fail(name, ip)
+				case "succeed":
+					// client IP is released on correct password, username is forgotten on completed login
+					if err = l.Release(name, ip); err == nil {
+						err = l.Succeed(name)
+					}
+				case "release":
+					err = l.Release(name, ip)
+				}
+				if err != nil {
+					t.Fatalf("lockout %s error = %v", step, err)
+				}
+			}
+
+			if _, err := l.Check(name, ip); !errors.Is(err, tt.wantErr) {
+				t.Errorf("lockout.Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/pkg/fingerprint"
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+// magicLinkTokenSize is number of random bytes in magic link token.
+const magicLinkTokenSize = 32
+
+// MagicLinkRepos represents repositories the magic link use case interacts with.
+type MagicLinkRepos struct {
+	User      repo.User
+	MagicLink repo.MagicLink
+	Attempt   repo.Attempt
+}
+
+// MagicLinkParams represents parameters for magic link use case.
+// LinkURL is URL of page that receives token in "token" query parameter.
+// Age is number of minutes until token expires.
+// Limit is number of links that can be requested for one address
+// within Window minutes, the address is locked for Window minutes after that.
+type MagicLinkParams struct {
+	LinkURL string
+	Age     int
+	Limit   int
+	Window  int
+}
+
+// Validate checks that URL is set and compares other parameters with min and max values.
+// It returns error if at least one of parameters is invalid.
+func (p MagicLinkParams) Validate() error {
+	if p.LinkURL == "" {
+		return ErrMagicLinkURLEmpty
+	}
+	if p.Age < 1 || p.Age > 60 {
+		return ErrMagicLinkAgeInvalid
+	}
+	if p.Limit < 1 || p.Limit > 100 {
+		return ErrMagicLinkLimitInvalid
+	}
+	if p.Window < 1 || p.Window > 1440 {
+		return ErrMagicLinkWindowInvalid
+	}
+	return nil
+}
+
+// magicLink implements MagicLink interface.
+type magicLink struct {
+	repos    MagicLinkRepos
+	params   MagicLinkParams
+	notifier Notifier
+}
+
+// NewMagicLink validates parameters and creates a new magic link use case.
+// Links are delivered to users by notifier.
+// It returns pointer to a magicLink instance or nil if parameters are invalid.
+func NewMagicLink(repos MagicLinkRepos, params MagicLinkParams, notifier Notifier) (*magicLink, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &magicLink{repos, params, notifier}, nil
+}
+
+// limit counts request for address and locks address
+// when number of requests within window reaches limit.
+// Requests are counted for every address whether or not user exists.
+// It returns remaining lock duration and error if address is locked.
+func (m *magicLink) limit(address string) (time.Duration, error) {
+	key := "email:" + address
+
+	attempts, err := m.repos.Attempt.GetByKeys(context.Background(), []string{key})
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	for _, attempt := range attempts {
+		if remaining := time.Until(attempt.LockedUntil); remaining > 0 {
+			return remaining, NewError(ErrTooManyRequests, true)
+		}
+	}
+
+	window := time.Duration(m.params.Window) * time.Minute
+	attempt, err := m.repos.Attempt.AddFailure(context.Background(), key, time.Now().Add(-window))
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	if attempt.Failures >= m.params.Limit {
+		if err := m.repos.Attempt.Lock(context.Background(), key, time.Now().Add(window)); err != nil {
+			return 0, NewError(err, false)
+		}
+	}
+
+	return 0, nil
+}
+
+// Send gets a user by email, replaces user's magic links with a new one
+// bound to client's fingerprint and sends it to user.
+// It returns nil if user doesn't exist, has no verified email or is blocked,
+// so that response doesn't reveal whether user exists.
+// It returns remaining lock duration and error if address requested too many links.
+func (m *magicLink) Send(address string, fp []byte) (time.Duration, error) {
+	address, err := normalizeEmail(address)
+	if err != nil {
+		return 0, err
+	}
+
+	if retryAfter, err := m.limit(address); err != nil {
+		return retryAfter, err
+	}
+
+	user, err := m.repos.User.GetByEmail(context.Background(), address)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, NewError(err, false)
+	}
+
+	if !user.EmailVerified || checkStatus(user) != nil {
+		return 0, nil
+	}
+
+	if err := m.repos.MagicLink.DeleteExpired(context.Background()); err != nil {
+		return 0, NewError(err, false)
+	}
+
+	if err := m.repos.MagicLink.DeleteByUser(context.Background(), user.ID); err != nil {
+		return 0, NewError(err, false)
+	}
+
+	fpHash, err := fingerprint.New(user.ID, fp).Hash()
+	if err != nil {
+		return 0, NewError(err, true)
+	}
+
+	value, err := secret.New(magicLinkTokenSize)
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	data := entity.MagicLink{
+		Hash:        secret.Hash(value),
+		Fingerprint: fpHash,
+		ExpiresAt:   time.Now().Add(time.Duration(m.params.Age) * time.Minute),
+		UserID:      user.ID,
+	}
+	if _, err := m.repos.MagicLink.Create(context.Background(), data); err != nil {
+		return 0, NewError(err, false)
+	}
+
+	linkURL, err := url.Parse(m.params.LinkURL)
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	query := linkURL.Query()
+	query.Set("token", value)
+	linkURL.RawQuery = query.Encode()
+
+	body := fmt.Sprintf("Hello, %s!\n\nTo sign in, open the link in the same browser you requested it from:\n%s\n\nThe link expires in %d minutes and can be used once. If you didn't request sign in, ignore this message.", user.Name, linkURL, m.params.Age)
+	if err := m.notifier.Notify(user, "Sign in", body); err != nil {
+		if errors.Is(err, ErrEmailNotVerified) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// Consume consumes magic link token and compares client's fingerprint
+// with fingerprint of client requested link.
+// Token is consumed even if fingerprint doesn't match, so that it can't be guessed.
+// It returns user ID or error if token is incorrect or expired.
+func (m *magicLink) Consume(value string, fp []byte) (uuid.UUID, error) {
+	// token is consumed atomically, so that concurrent requests can't use it twice
+	link, err := m.repos.MagicLink.ConsumeByHash(context.Background(), secret.Hash(value))
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return uuid.UUID{}, NewError(ErrTokenIncorrect, true)
+		}
+		return uuid.UUID{}, NewError(err, false)
+	}
+
+	if link.ExpiresAt.Before(time.Now()) {
+		return uuid.UUID{}, NewError(ErrTokenExpired, true)
+	}
+
+	if err := fingerprint.New(link.UserID, fp).Verify(link.Fingerprint); err != nil {
+		return uuid.UUID{}, NewError(ErrTokenIncorrect, true)
+	}
+
+	return link.UserID, nil
+}
@@ -0,0 +1,513 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/totp"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+const (
+	// challengeTokenSize is number of random bytes in challenge token.
+	challengeTokenSize = 32
+
+	// challengeAttempts is number of incorrect codes after which challenge is deleted.
+	challengeAttempts = 5
+)
+
+// MFARepos represents repositories the MFA use case interacts with.
+// Credential is nil if WebAuthn is disabled.
+type MFARepos struct {
+	User       repo.User
+	TOTP       repo.TOTP
+	Challenge  repo.Challenge
+	Recovery   repo.Recovery
+	Credential repo.Credential
+	Attempt    repo.Attempt
+}
+
+// MFAParams represents parameters for MFA use case.
+// ChallengeAge is number of minutes until challenge expires,
+// Limit is number of challenges created for user within Window minutes,
+// Secret is used to encrypt TOTP secrets and hash recovery codes.
+type MFAParams struct {
+	ChallengeAge int
+	Limit        int
+	Window       int
+	Secret       string
+}
+
+// Validate compares parameters with min and max values.
+// It returns error if at least one of parameters is invalid.
+func (p MFAParams) Validate() error {
+	if p.ChallengeAge < 1 || p.ChallengeAge > 15 {
+		return ErrChallengeAgeInvalid
+	}
+	if p.Limit < 1 || p.Limit > 100 {
+		return ErrChallengeLimitInvalid
+	}
+	if p.Window < 1 || p.Window > 1440 {
+		return ErrChallengeWindowInvalid
+	}
+	if len(p.Secret) < 32 {
+		return ErrSecretInvalid
+	}
+	return nil
+}
+
+// mfa implements MFA interface.
+type mfa struct {
+	repos     MFARepos
+	params    MFAParams
+	generator totp.Generator
+	lockout   Lockout
+}
+
+// NewMFA validates parameters and creates a new MFA use case.
+// Incorrect codes are recorded as failed attempts by lockout.
+// It returns pointer to a mfa instance or nil if parameters are invalid.
+func NewMFA(repos MFARepos, params MFAParams, generator totp.Generator, lockout Lockout) (*mfa, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &mfa{repos, params, generator, lockout}, nil
+}
+
+// getTOTP gets user's TOTP secret by user ID and decrypts it.
+// It returns ErrMFANotEnabled if user has no secret
+// or secret isn't confirmed while confirmed is true.
+func (m *mfa) getTOTP(userID uuid.UUID, confirmed bool) (*entity.TOTP, error) {
+	data, err := m.repos.TOTP.GetByUser(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrMFANotEnabled, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	if confirmed && !data.Confirmed {
+		return nil, NewError(ErrMFANotEnabled, true)
+	}
+
+	if data.Sealed {
+		if data.Secret, err = secret.Open(m.params.Secret, data.Secret); err != nil {
+			return nil, NewError(err, false)
+		}
+	}
+
+	return data, nil
+}
+
+// SealSecrets encrypts TOTP secrets stored before encryption was introduced.
+// It returns number of encrypted secrets.
+func (m *mfa) SealSecrets() (int, error) {
+	totps, err := m.repos.TOTP.GetUnsealed(context.Background())
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	var count int
+	for _, data := range totps {
+		sealed, err := secret.Seal(m.params.Secret, data.Secret)
+		if err != nil {
+			return count, NewError(err, false)
+		}
+
+		if err := m.repos.TOTP.Seal(context.Background(), data.UserID, sealed); err != nil {
+			if errors.Is(err, repo.ErrNoRows) {
+				continue
+			}
+			return count, NewError(err, false)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// verifyCode validates code and marks its time step as used.
+// It returns error if code is incorrect or was already used.
+func (m *mfa) verifyCode(data *entity.TOTP, code string) error {
+	counter, err := m.generator.Validate(data.Secret, code, time.Now())
+	if err != nil {
+		return NewError(ErrCodeIncorrect, true)
+	}
+
+	if err := m.repos.TOTP.UseCounter(context.Background(), data.UserID, counter); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrCodeUsed, true)
+		}
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// Enroll generates a new TOTP secret for user
+// that must be confirmed with the first code. Secret is stored encrypted.
+// It returns encoded secret and otpauth:// URI
+// or error if user has already enabled MFA.
+func (m *mfa) Enroll(userID uuid.UUID) (string, string, error) {
+	user, err := m.repos.User.GetByID(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return "", "", NewError(ErrUserNotExist, true)
+		}
+		return "", "", NewError(err, false)
+	}
+
+	value, err := m.generator.Secret()
+	if err != nil {
+		return "", "", NewError(err, false)
+	}
+
+	sealed, err := secret.Seal(m.params.Secret, value)
+	if err != nil {
+		return "", "", NewError(err, false)
+	}
+
+	if _, err := m.repos.TOTP.Create(context.Background(), entity.TOTP{UserID: user.ID, Secret: sealed, Sealed: true}); err != nil {
+		if errors.Is(err, repo.ErrExists) {
+			return "", "", NewError(ErrMFAEnabled, true)
+		}
+		return "", "", NewError(err, false)
+	}
+
+	return totp.Encode(value), m.generator.URI(user.Name, value), nil
+}
+
+// Confirm verifies the first code generated with enrolled secret
+// and enables MFA for user.
+// It returns error if code is incorrect or MFA is already enabled.
+func (m *mfa) Confirm(userID uuid.UUID, code string) error {
+	data, err := m.getTOTP(userID, false)
+	if err != nil {
+		return err
+	}
+
+	if data.Confirmed {
+		return NewError(ErrMFAEnabled, true)
+	}
+
+	if err := m.verifyCode(data, code); err != nil {
+		return err
+	}
+
+	if err := m.repos.TOTP.Confirm(context.Background(), userID); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// Disable verifies code and disables MFA for user.
+// It returns error if code is incorrect or MFA isn't enabled.
+func (m *mfa) Disable(userID uuid.UUID, code string) error {
+	data, err := m.getTOTP(userID, true)
+	if err != nil {
+		return err
+	}
+
+	if err := m.verifyCode(data, code); err != nil {
+		return err
+	}
+
+	if err := m.repos.TOTP.DeleteByUser(context.Background(), userID); err != nil {
+		return NewError(err, false)
+	}
+
+	methods, err := m.methods(userID)
+	if err != nil {
+		return err
+	}
+
+	// recovery codes are useless without second factor
+	if len(methods) == 0 {
+		if err := m.repos.Recovery.DeleteByUser(context.Background(), userID); err != nil {
+			return NewError(err, false)
+		}
+	}
+
+	return nil
+}
+
+// Reset disables MFA for user without code by deleting TOTP secret,
+// WebAuthn credentials and recovery codes,
+// so that user who lost authenticator can enroll again.
+func (m *mfa) Reset(userID uuid.UUID) error {
+	if _, err := m.repos.User.GetByID(context.Background(), userID); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrUserNotExist, true)
+		}
+		return NewError(err, false)
+	}
+
+	if err := m.repos.TOTP.DeleteByUser(context.Background(), userID); err != nil {
+		return NewError(err, false)
+	}
+
+	if m.repos.Credential != nil {
+		if err := m.repos.Credential.DeleteByUser(context.Background(), userID); err != nil {
+			return NewError(err, false)
+		}
+	}
+
+	if err := m.repos.Recovery.DeleteByUser(context.Background(), userID); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// methods gets second factors enabled by user:
+// confirmed TOTP secret and registered WebAuthn credentials.
+// It returns slice of method names.
+func (m *mfa) methods(userID uuid.UUID) ([]string, error) {
+	var methods []string
+
+	if _, err := m.getTOTP(userID, true); err == nil {
+		methods = append(methods, entity.MethodTOTP)
+	} else if !errors.Is(err, ErrMFANotEnabled) {
+		return nil, err
+	}
+
+	if m.repos.Credential != nil {
+		credentials, err := m.repos.Credential.GetByUser(context.Background(), userID)
+		if err != nil {
+			return nil, NewError(err, false)
+		}
+
+		if len(credentials) > 0 {
+			methods = append(methods, entity.MethodWebAuthn)
+		}
+	}
+
+	return methods, nil
+}
+
+// limit counts challenge created for user and locks creation of new ones
+// when number of challenges within window reaches limit,
+// so that attempts aren't multiplied by creating new challenges.
+// It returns error if creation is locked.
+func (m *mfa) limit(userID uuid.UUID) error {
+	key := "challenge:" + userID.String()
+
+	attempts, err := m.repos.Attempt.GetByKeys(context.Background(), []string{key})
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	for _, attempt := range attempts {
+		if attempt.LockedUntil.After(time.Now()) {
+			return NewError(ErrTooManyRequests, true)
+		}
+	}
+
+	return m.count(key)
+}
+
+// count adds attempt to user's challenge limiter by key
+// and locks creation of new challenges when number of attempts within window reaches limit.
+func (m *mfa) count(key string) error {
+	window := time.Duration(m.params.Window) * time.Minute
+	attempt, err := m.repos.Attempt.AddFailure(context.Background(), key, time.Now().Add(-window))
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	if attempt.Failures >= m.params.Limit {
+		if err := m.repos.Attempt.Lock(context.Background(), key, time.Now().Add(window)); err != nil {
+			return NewError(err, false)
+		}
+	}
+
+	return nil
+}
+
+// Challenge checks user's status and creates a new challenge for user if MFA is enabled.
+// MFA is enabled if user has confirmed TOTP secret or registered WebAuthn credential.
+// Number of challenges created for user within window is limited.
+// If MFA isn't enabled, authentication is complete and failed attempts of user's name are forgotten.
+// It returns pointer to an entity.Challenge instance
+// or nil if user hasn't enabled MFA and tokens can be created immediately.
+func (m *mfa) Challenge(userID uuid.UUID, session bool) (*entity.Challenge, error) {
+	user, err := m.repos.User.GetByID(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrUserNotExist, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	if err := checkStatus(user); err != nil {
+		return nil, err
+	}
+
+	methods, err := m.methods(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(methods) == 0 {
+		return nil, m.lockout.Succeed(user.Name)
+	}
+
+	if err := m.limit(userID); err != nil {
+		return nil, err
+	}
+
+	if err := m.repos.Challenge.DeleteExpired(context.Background()); err != nil {
+		return nil, NewError(err, false)
+	}
+
+	value, err := secret.New(challengeTokenSize)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	data := entity.Challenge{
+		Hash:      secret.Hash(value),
+		ExpiresAt: time.Now().Add(time.Duration(m.params.ChallengeAge) * time.Minute),
+		Session:   session,
+		UserID:    userID,
+	}
+
+	challenge, err := m.repos.Challenge.Create(context.Background(), data)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+	count, err := m.repos.Recovery.CountByUser(context.Background(), userID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	if count > 0 {
+		methods = append(methods, entity.MethodRecovery)
+	}
+
+	challenge.Token = value
+	challenge.Methods = methods
+
+	return challenge, nil
+}
+
+// getChallenge gets a challenge by hash of its value and checks expiration.
+// Challenge is rejected if challenged user's name is locked out.
+// It returns pointer to an entity.Challenge instance and user's name.
+func (m *mfa) getChallenge(value string) (*entity.Challenge, string, error) {
+	challenge, err := m.repos.Challenge.GetByHash(context.Background(), secret.Hash(value))
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, "", NewError(ErrChallengeIncorrect, true)
+		}
+		return nil, "", NewError(err, false)
+	}
+
+	if challenge.ExpiresAt.Before(time.Now()) {
+		return nil, "", NewError(ErrChallengeExpired, true)
+	}
+
+	user, err := m.repos.User.GetByID(context.Background(), challenge.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, "", NewError(ErrChallengeIncorrect, true)
+		}
+		return nil, "", NewError(err, false)
+	}
+
+	if _, err := m.lockout.Check(user.Name, ""); err != nil {
+		return nil, "", err
+	}
+
+	return challenge, user.Name, nil
+}
+
+// fail increments number of failed attempts of challenge
+// and deletes challenge after too many attempts.
+// Incorrect or used code and invalid WebAuthn credential are recorded
+// as failed attempt of user's name and counted by user's challenge limiter,
+// so that second factor cannot be guessed using new challenges.
+// It returns error passed as err if no other error occurred.
+func (m *mfa) fail(challenge *entity.Challenge, name string, err error) error {
+	attempts, aErr := m.repos.Challenge.AddAttempt(context.Background(), challenge.ID)
+	if aErr != nil && !errors.Is(aErr, repo.ErrNoRows) {
+		return NewError(aErr, false)
+	}
+
+	if attempts >= challengeAttempts {
+		if dErr := m.repos.Challenge.DeleteByID(context.Background(), challenge.ID); dErr != nil && !errors.Is(dErr, repo.ErrNoRows) {
+			return NewError(dErr, false)
+		}
+	}
+
+	if errors.Is(err, ErrCodeIncorrect) || errors.Is(err, ErrCodeUsed) || errors.Is(err, ErrCredentialInvalid) {
+		if cErr := m.count("challenge:" + challenge.UserID.String()); cErr != nil {
+			return cErr
+		}
+
+		if _, fErr := m.lockout.Fail(name, ""); fErr != nil {
+			return fErr
+		}
+	}
+
+	return err
+}
+
+// Fail records failed attempt of challenge by ID completed by second factor
+// verified outside MFA use case, such as WebAuthn assertion,
+// the same way as incorrect code.
+// It returns error passed as err if no other error occurred.
+func (m *mfa) Fail(challengeID uuid.UUID, userID uuid.UUID, err error) error {
+	user, uErr := m.repos.User.GetByID(context.Background(), userID)
+	if uErr != nil {
+		if errors.Is(uErr, repo.ErrNoRows) {
+			return NewError(ErrChallengeIncorrect, true)
+		}
+		return NewError(uErr, false)
+	}
+
+	return m.fail(&entity.Challenge{ID: challengeID, UserID: userID}, user.Name, err)
+}
+
+// complete deletes completed challenge and forgets failed attempts of user's name.
+// It returns error if challenge was completed concurrently.
+func (m *mfa) complete(challenge *entity.Challenge, name string) error {
+	if err := m.repos.Challenge.DeleteByID(context.Background(), challenge.ID); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrChallengeIncorrect, true)
+		}
+		return NewError(err, false)
+	}
+
+	return m.lockout.Succeed(name)
+}
+
+// Complete verifies challenge and TOTP code, then deletes challenge.
+// Challenge is deleted after too many incorrect codes.
+// It returns pointer to a completed entity.Challenge instance.
+func (m *mfa) Complete(value string, code string) (*entity.Challenge, error) {
+	challenge, name, err := m.getChallenge(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := m.getTOTP(challenge.UserID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.verifyCode(data, code); err != nil {
+		return nil, m.fail(challenge, name, err)
+	}
+
+	if err := m.complete(challenge, name); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/log"
+	"github.com/qsoulior/auth-server/pkg/mail"
+)
+
+// Notifier is interface implemented by types
+// that can deliver messages to users.
+type Notifier interface {
+	// Notify delivers message with subject and body to user.
+	// It returns ErrEmailNotVerified if user has no verified contact.
+	Notify(user *entity.User, subject string, body string) error
+}
+
+// mailNotifier implements Notifier interface
+// using user's verified email.
+type mailNotifier struct {
+	mailer mail.Mailer
+}
+
+// NewMailNotifier creates a new notifier sending messages by mailer.
+// It returns pointer to a mailNotifier instance.
+func NewMailNotifier(mailer mail.Mailer) *mailNotifier {
+	return &mailNotifier{mailer}
+}
+
+// Notify sends message to user's email if it is verified.
+// It returns error if email isn't verified or sending failed.
+func (n *mailNotifier) Notify(user *entity.User, subject string, body string) error {
+	if user.Email == "" || !user.EmailVerified {
+		return NewError(ErrEmailNotVerified, true)
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	}
+	if err := n.mailer.Send(msg); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// asyncNotifier implements Notifier interface
+// delivering messages in background by another notifier.
+type asyncNotifier struct {
+	notifier Notifier
+	logger   log.Logger
+}
+
+// NewAsyncNotifier creates a new notifier delivering messages in background by notifier,
+// so that response time doesn't depend on delivery. Delivery errors are logged by logger.
+// It returns pointer to an asyncNotifier instance.
+func NewAsyncNotifier(notifier Notifier, logger log.Logger) *asyncNotifier {
+	return &asyncNotifier{notifier, logger}
+}
+
+// Notify starts delivery of message to user in background.
+// It returns ErrEmailNotVerified if user has no verified email.
+func (n *asyncNotifier) Notify(user *entity.User, subject string, body string) error {
+	if user.Email == "" || !user.EmailVerified {
+		return NewError(ErrEmailNotVerified, true)
+	}
+
+	go func() {
+		if err := n.notifier.Notify(user, subject, body); err != nil {
+			n.logger.Error("failed to notify user %s: %s", user.ID, err)
+		}
+	}()
+
+	return nil
+}
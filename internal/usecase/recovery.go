@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// recoveryCodeCount is number of recovery codes in a set.
+	recoveryCodeCount = 10
+
+	// recoveryCodeLength is number of characters in recovery code without separator.
+	recoveryCodeLength = 10
+)
+
+// recoveryEncoding is lowercase base32 encoding used for recovery codes.
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// newRecoveryCode generates random recovery code in "xxxxx-xxxxx" format.
+// It returns code string.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := recoveryEncoding.EncodeToString(b)[:recoveryCodeLength]
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:], nil
+}
+
+// normalizeRecoveryCode lowercases code and removes separators and spaces.
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// hashRecovery hashes normalized recovery code using HMAC-SHA256 keyed with secret.
+// Recovery codes are random, so fast keyed hash is enough to protect them.
+// It returns hash bytes.
+func (m *mfa) hashRecovery(code string) []byte {
+	mac := hmac.New(sha256.New, []byte(m.params.Secret))
+	mac.Write([]byte(normalizeRecoveryCode(code)))
+	return mac.Sum(nil)
+}
+
+// matchRecovery compares normalized recovery code with hash.
+// Hashes created with bcrypt before keyed hash was introduced are also compared.
+// It returns true if code matches hash.
+func (m *mfa) matchRecovery(hash []byte, code string) bool {
+	if len(hash) == sha256.Size {
+		return hmac.Equal(hash, m.hashRecovery(code))
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(code)) == nil
+}
+
+// GenerateRecovery generates a new set of recovery codes for user with enabled MFA
+// and replaces the old set. Only hashes of codes are stored.
+// It returns slice of codes that must be shown to user once.
+func (m *mfa) GenerateRecovery(userID uuid.UUID) ([]string, error) {
+	methods, err := m.methods(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(methods) == 0 {
+		return nil, NewError(ErrMFANotEnabled, true)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, NewError(err, false)
+		}
+
+		hashes[i] = m.hashRecovery(codes[i])
+	}
+
+	if err := m.repos.Recovery.Replace(context.Background(), userID, hashes); err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return codes, nil
+}
+
+// CountRecovery counts user's remaining recovery codes.
+// It returns number of codes.
+func (m *mfa) CountRecovery(userID uuid.UUID) (int, error) {
+	count, err := m.repos.Recovery.CountByUser(context.Background(), userID)
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	return count, nil
+}
+
+// useRecovery compares code with user's recovery code hashes and deletes matched one.
+// It returns error if code is incorrect or was used concurrently.
+func (m *mfa) useRecovery(userID uuid.UUID, code string) error {
+	recoveryCodes, err := m.repos.Recovery.GetByUser(context.Background(), userID)
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	code = normalizeRecoveryCode(code)
+	if len(code) != recoveryCodeLength {
+		return NewError(ErrCodeIncorrect, true)
+	}
+
+	var matched *entity.RecoveryCode
+	for i := range recoveryCodes {
+		if m.matchRecovery(recoveryCodes[i].Hash, code) {
+			matched = &recoveryCodes[i]
+			break
+		}
+	}
+
+	if matched == nil {
+		return NewError(ErrCodeIncorrect, true)
+	}
+
+	if err := m.repos.Recovery.DeleteByID(context.Background(), matched.ID); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrCodeUsed, true)
+		}
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// Recover verifies challenge and recovery code used in place of second factor,
+// then deletes challenge. Challenge is deleted after too many incorrect codes.
+// It returns pointer to a completed entity.Challenge instance.
+func (m *mfa) Recover(value string, code string) (*entity.Challenge, error) {
+	challenge, name, err := m.getChallenge(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.useRecovery(challenge.UserID, code); err != nil {
+		return nil, m.fail(challenge, name, err)
+	}
+
+	if err := m.complete(challenge, name); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
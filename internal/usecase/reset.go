@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/password"
+)
+
+// resetTokenSize is number of random bytes in password reset token.
+const resetTokenSize = 32
+
+// ResetRepos represents repositories the reset use case interacts with.
+type ResetRepos struct {
+	User    repo.User
+	Token   repo.Token
+	Reset   repo.Reset
+	History repo.History
+}
+
+// ResetParams represents parameters for reset use case.
+// ResetURL is URL of page that receives reset token in "token" query parameter.
+// Age is number of minutes until reset token expires.
+// HistorySize is number of last passwords that can't be used again.
+type ResetParams struct {
+	ResetURL    string
+	Age         int
+	HistorySize int
+}
+
+// Validate checks that URL is set and compares other parameters with min and max values.
+// It returns error if at least one of parameters is invalid.
+func (p ResetParams) Validate() error {
+	if p.ResetURL == "" {
+		return ErrResetURLEmpty
+	}
+	if p.Age < 1 || p.Age > 1440 {
+		return ErrResetAgeInvalid
+	}
+	if p.HistorySize < 0 || p.HistorySize > maxHistorySize {
+		return ErrHistorySizeInvalid
+	}
+	return nil
+}
+
+// reset implements Reset interface.
+type reset struct {
+	repos    ResetRepos
+	params   ResetParams
+	hasher   password.Hasher
+	policy   password.Policy
+	notifier Notifier
+}
+
+// NewReset validates parameters and creates a new reset use case.
+// New passwords are checked against policy and hashed by hasher,
+// reset links are delivered to users by notifier.
+// It returns pointer to a reset instance or nil if parameters are invalid.
+func NewReset(repos ResetRepos, params ResetParams, hasher password.Hasher, policy password.Policy, notifier Notifier) (*reset, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &reset{repos, params, hasher, policy, notifier}, nil
+}
+
+// Forgot gets a user by email, replaces user's reset tokens with a new one
+// and sends reset link to user.
+// It returns nil if user doesn't exist, has no verified email, isn't local or is blocked,
+// so that response doesn't reveal whether user exists.
+func (r *reset) Forgot(address string) error {
+	address, err := normalizeEmail(address)
+	if err != nil {
+		return err
+	}
+
+	user, err := r.repos.User.GetByEmail(context.Background(), address)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil
+		}
+		return NewError(err, false)
+	}
+
+	if !user.EmailVerified || user.Source != entity.SourceLocal || checkStatus(user) != nil {
+		return nil
+	}
+
+	if err := r.repos.Reset.DeleteExpired(context.Background()); err != nil {
+		return NewError(err, false)
+	}
+
+	if err := r.repos.Reset.DeleteByUser(context.Background(), user.ID); err != nil {
+		return NewError(err, false)
+	}
+
+	value, err := secret.New(resetTokenSize)
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	data := entity.ResetToken{
+		Hash:      secret.Hash(value),
+		ExpiresAt: time.Now().Add(time.Duration(r.params.Age) * time.Minute),
+		UserID:    user.ID,
+	}
+	if _, err := r.repos.Reset.Create(context.Background(), data); err != nil {
+		return NewError(err, false)
+	}
+
+	resetURL, err := url.Parse(r.params.ResetURL)
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	query := resetURL.Query()
+	query.Set("token", value)
+	resetURL.RawQuery = query.Encode()
+
+	body := fmt.Sprintf("Hello, %s!\n\nTo reset your password, open the link:\n%s\n\nThe link expires in %d minutes. If you didn't request password reset, ignore this message.", user.Name, resetURL, r.params.Age)
+	if err := r.notifier.Notify(user, "Reset your password", body); err != nil {
+		if errors.Is(err, ErrEmailNotVerified) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Reset gets reset token, validates and hashes new password, consumes token,
+// updates user's password and deletes all user's refresh tokens.
+// Token isn't consumed if password is invalid or user isn't allowed to reset password.
+// Only local users who aren't blocked can reset password,
+// so that password of identity provider's user can't be set locally.
+// It returns error if password is invalid or token is incorrect or expired.
+func (r *reset) Reset(value string, newPassword []byte) error {
+	hash := secret.Hash(value)
+
+	token, err := r.repos.Reset.GetByHash(context.Background(), hash)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrTokenIncorrect, true)
+		}
+		return NewError(err, false)
+	}
+
+	if token.ExpiresAt.Before(time.Now()) {
+		return NewError(ErrTokenExpired, true)
+	}
+
+	user, err := r.repos.User.GetByID(context.Background(), token.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrTokenIncorrect, true)
+		}
+		return NewError(err, false)
+	}
+
+	if user.Source != entity.SourceLocal {
+		return NewError(ErrUserNotLocal, true)
+	}
+
+	if err := checkStatus(user); err != nil {
+		return err
+	}
+
+	hashedPassword, err := preparePassword(r.policy, r.hasher, r.repos.History, user, newPassword, r.params.HistorySize)
+	if err != nil {
+		return err
+	}
+
+	// token is consumed atomically, so that concurrent requests can't use it twice
+	if _, err := r.repos.Reset.ConsumeByHash(context.Background(), hash); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrTokenIncorrect, true)
+		}
+		return NewError(err, false)
+	}
+
+	if err := storePassword(r.repos.User, r.repos.History, user.ID, hashedPassword, r.params.HistorySize); err != nil {
+		return err
+	}
+
+	if err := r.repos.Token.DeleteByUser(context.Background(), user.ID); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+const (
+	lowerChars = `abcdefghijklmnopqrstuvwxyz`
+	upperChars = `ABCDEFGHIJKLMNOPQRSTUVWXYZ`
+	digitChars = `0123456789`
+)
+
+// validateRole returns error if role's title or description is invalid.
+// Title is limited to 20 characters and description to 100 characters.
+func validateRole(role entity.Role) error {
+	if length := utf8.RuneCountInString(role.Title); length < 1 || length > 20 {
+		return NewError(ErrTitleInvalid, true)
+	}
+
+	for _, r := range role.Title {
+		if !strings.ContainsRune(lowerChars+upperChars+digitChars+"_-", r) {
+			return NewError(ErrTitleInvalid, true)
+		}
+	}
+
+	if !utf8.ValidString(role.Description) || utf8.RuneCountInString(role.Description) > 100 {
+		return NewError(ErrDescInvalid, true)
+	}
+
+	return nil
+}
+
+// syncRoles assigns roles mapped from external groups the user is member of
+// and unassigns mapped roles of groups the user is not member of.
+// Roles absent from mapping and roles that don't exist are left unchanged.
+func syncRoles(roleRepo repo.Role, userID uuid.UUID, mapping map[string]string, groups []string) error {
+	granted := make(map[string]bool, len(mapping))
+	for _, title := range mapping {
+		granted[title] = false
+	}
+	for _, group := range groups {
+		if title, ok := mapping[group]; ok {
+			granted[title] = true
+		}
+	}
+
+	for title, ok := range granted {
+		role, err := roleRepo.GetByTitle(context.Background(), title)
+		if err != nil {
+			if errors.Is(err, repo.ErrNoRows) {
+				continue
+			}
+			return NewError(err, false)
+		}
+
+		if ok {
+			err = roleRepo.AddUser(context.Background(), role.ID, userID)
+		} else {
+			err = roleRepo.RemoveUser(context.Background(), role.ID, userID)
+		}
+		if err != nil {
+			return NewError(err, false)
+		}
+	}
+
+	return nil
+}
+
+// RoleRepos represents repositories the role use case interacts with.
+type RoleRepos struct {
+	Role repo.Role
+	User repo.User
+}
+
+// role implements Role interface.
+type role struct {
+	repos RoleRepos
+}
+
+// user checks that user exists by ID.
+// It returns error if user doesn't exist.
+func (r *role) user(id uuid.UUID) error {
+	if _, err := r.repos.User.GetByID(context.Background(), id); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrUserNotExist, true)
+		}
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// NewRole creates a new role use case.
+// It returns pointer to a role instance.
+func NewRole(repos RoleRepos) *role {
+	return &role{repos}
+}
+
+// Create validates data and creates a new role.
+// It returns pointer to an entity.Role instance or nil if an error occurred.
+func (r *role) Create(data entity.Role) (*entity.Role, error) {
+	if err := validateRole(data); err != nil {
+		return nil, err
+	}
+
+	_, err := r.repos.Role.GetByTitle(context.Background(), data.Title)
+	if err == nil {
+		return nil, NewError(ErrRoleExists, true)
+	} else if !errors.Is(err, repo.ErrNoRows) {
+		return nil, NewError(err, false)
+	}
+
+	role, err := r.repos.Role.Create(context.Background(), data)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return role, nil
+}
+
+// List gets all roles.
+// It returns slice of entity.Role instances.
+func (r *role) List() ([]entity.Role, error) {
+	roles, err := r.repos.Role.GetAll(context.Background())
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return roles, nil
+}
+
+// Get gets a role by ID.
+// It returns pointer to an entity.Role instance or nil if an error occurred.
+func (r *role) Get(id uuid.UUID) (*entity.Role, error) {
+	role, err := r.repos.Role.GetByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrRoleNotExist, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	return role, nil
+}
+
+// Update validates data and updates role's title and description by ID
+// if role exists and title isn't used by another role.
+// It returns pointer to an entity.Role instance or nil if an error occurred.
+func (r *role) Update(data entity.Role) (*entity.Role, error) {
+	if _, err := r.Get(data.ID); err != nil {
+		return nil, err
+	}
+
+	if err := validateRole(data); err != nil {
+		return nil, err
+	}
+
+	existing, err := r.repos.Role.GetByTitle(context.Background(), data.Title)
+	if err == nil && existing.ID != data.ID {
+		return nil, NewError(ErrRoleExists, true)
+	} else if err != nil && !errors.Is(err, repo.ErrNoRows) {
+		return nil, NewError(err, false)
+	}
+
+	role, err := r.repos.Role.Update(context.Background(), data)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrRoleNotExist, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	return role, nil
+}
+
+// Delete deletes a role by ID if role exists.
+func (r *role) Delete(id uuid.UUID) error {
+	if _, err := r.Get(id); err != nil {
+		return err
+	}
+
+	if err := r.repos.Role.DeleteByID(context.Background(), id); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// Assign assigns a role to user by role ID and user ID
+// if role and user exist.
+// Role appears in access tokens issued after assignment.
+func (r *role) Assign(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := r.Get(id); err != nil {
+		return err
+	}
+
+	if err := r.user(userID); err != nil {
+		return err
+	}
+
+	if err := r.repos.Role.AddUser(context.Background(), id, userID); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// Unassign unassigns a role from user by role ID and user ID
+// if role and user exist.
+func (r *role) Unassign(id uuid.UUID, userID uuid.UUID) error {
+	if _, err := r.Get(id); err != nil {
+		return err
+	}
+
+	if err := r.user(userID); err != nil {
+		return err
+	}
+
+	if err := r.repos.Role.RemoveUser(context.Background(), id, userID); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// ListByUser gets roles assigned to user by user ID if user exists.
+// It returns slice of entity.Role instances.
+func (r *role) ListByUser(userID uuid.UUID) ([]entity.Role, error) {
+	if err := r.user(userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := r.repos.Role.GetByUser(context.Background(), userID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return roles, nil
+}
+
+// ListUsers gets users the role is assigned to by role ID if role exists.
+// It returns slice of entity.User instances.
+func (r *role) ListUsers(id uuid.UUID) ([]entity.User, error) {
+	if _, err := r.Get(id); err != nil {
+		return nil, err
+	}
+
+	users, err := r.repos.User.GetByRole(context.Background(), id)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return users, nil
+}
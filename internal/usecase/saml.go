@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/saml"
+	"github.com/qsoulior/auth-server/pkg/username"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+)
+
+const (
+	samlCodeSize = 32
+	samlCodeAge  = time.Minute
+)
+
+// SAMLRepos represents repositories the SAML use case interacts with.
+type SAMLRepos struct {
+	User      repo.User
+	Role      repo.Role
+	Assertion repo.Assertion
+}
+
+// SAMLParams represents parameters for SAML use case.
+type SAMLParams struct {
+	RedirectURL   string
+	NameAttribute string
+	RoleAttribute string
+	RoleMapping   map[string]string
+}
+
+// Validate checks that redirect URL is set.
+// It returns error if at least one of parameters is invalid.
+func (p SAMLParams) Validate() error {
+	if p.RedirectURL == "" {
+		return ErrRedirectURLEmpty
+	}
+	return nil
+}
+
+// sso implements SAML interface.
+type sso struct {
+	repos  SAMLRepos
+	params SAMLParams
+	sp     saml.ServiceProvider
+	names  username.Policy
+}
+
+// NewSAML validates parameters and creates a new SAML use case.
+// Names of provisioned users are checked against names policy.
+// It returns pointer to a sso instance or nil if parameters are invalid.
+func NewSAML(repos SAMLRepos, params SAMLParams, sp saml.ServiceProvider, names username.Policy) (*sso, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &sso{repos, params, sp, names}, nil
+}
+
+// Metadata creates service provider metadata.
+// It returns XML document bytes.
+func (s *sso) Metadata() ([]byte, error) {
+	metadata, err := s.sp.Metadata()
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return metadata, nil
+}
+
+// Request creates a new authentication request.
+// It returns request ID and URL of identity provider to redirect user to.
+func (s *sso) Request(relayState string) (string, string, error) {
+	id, url, err := s.sp.AuthnRequest(relayState)
+	if err != nil {
+		return "", "", NewError(err, false)
+	}
+
+	return id, url, nil
+}
+
+// Login validates response from identity provider, gets or creates a user
+// and synchronizes user's roles using assertion attributes.
+// Each assertion can be consumed only once, it is recorded before user is provisioned.
+// Only users provisioned by SAML can be linked.
+// It returns redirect URL containing one-time code that can be exchanged for tokens.
+func (s *sso) Login(response string, requestID string, relayState string) (string, error) {
+	assertion, err := s.sp.ParseResponse(response, requestID)
+	if err != nil {
+		return "", NewError(err, true)
+	}
+
+	name := assertion.NameID
+	if s.params.NameAttribute != "" {
+		name = ""
+		if values := assertion.Attributes[s.params.NameAttribute]; len(values) > 0 {
+			name = values[0]
+		}
+	}
+
+	if err := validateName(s.names, name); err != nil {
+		return "", err
+	}
+
+	if err := s.repos.Assertion.DeleteExpired(context.Background()); err != nil {
+		return "", NewError(err, false)
+	}
+
+	codeExpiresAt := time.Now().Add(samlCodeAge)
+	expiresAt := assertion.ExpiresAt.Local()
+	if expiresAt.Before(codeExpiresAt) {
+		expiresAt = codeExpiresAt
+	}
+
+	if err := s.repos.Assertion.Create(context.Background(), assertion.ID, expiresAt); err != nil {
+		if errors.Is(err, repo.ErrExists) {
+			return "", NewError(ErrAssertionReplayed, true)
+		}
+		return "", NewError(err, false)
+	}
+
+	user, err := provisionUser(s.repos.User, name, entity.SourceSAML)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := secret.New(samlCodeSize)
+	if err != nil {
+		return "", NewError(err, false)
+	}
+
+	data := entity.Assertion{
+		ID:            assertion.ID,
+		Code:          secret.Hash(code),
+		CodeExpiresAt: codeExpiresAt,
+		UserID:        user.ID,
+	}
+	if _, err := s.repos.Assertion.UpdateCode(context.Background(), data); err != nil {
+		return "", NewError(err, false)
+	}
+
+	if s.params.RoleAttribute != "" {
+		groups := assertion.Attributes[s.params.RoleAttribute]
+		if err := syncRoles(s.repos.Role, user.ID, s.params.RoleMapping, groups); err != nil {
+			return "", err
+		}
+	}
+
+	redirectURL, err := url.Parse(s.params.RedirectURL)
+	if err != nil {
+		return "", NewError(err, false)
+	}
+
+	query := redirectURL.Query()
+	query.Set("code", code)
+	if relayState != "" {
+		query.Set("state", relayState)
+	}
+	redirectURL.RawQuery = query.Encode()
+
+	return redirectURL.String(), nil
+}
+
+// Exchange consumes one-time code issued by Login.
+// It returns user ID if code is correct and not expired.
+func (s *sso) Exchange(code string) (uuid.UUID, error) {
+	assertion, err := s.repos.Assertion.ConsumeByCode(context.Background(), secret.Hash(code))
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return uuid.UUID{}, NewError(ErrCodeIncorrect, true)
+		}
+		return uuid.UUID{}, NewError(err, false)
+	}
+
+	if assertion.CodeExpiresAt.Before(time.Now()) {
+		return uuid.UUID{}, NewError(ErrCodeExpired, true)
+	}
+
+	return assertion.UserID, nil
+}
@@ -7,22 +7,38 @@ import (
 
 	"github.com/qsoulior/auth-server/internal/entity"
 	"github.com/qsoulior/auth-server/internal/pkg/fingerprint"
+	"github.com/qsoulior/auth-server/internal/pkg/hash"
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
 	"github.com/qsoulior/auth-server/internal/repo"
 	"github.com/qsoulior/auth-server/pkg/jwt"
+	"github.com/qsoulior/auth-server/pkg/profile"
 	"github.com/qsoulior/auth-server/pkg/uuid"
 )
 
+const (
+	// refreshTokenSize is number of random bytes in opaque refresh token.
+	refreshTokenSize = 32
+
+	// restrictedTokenAge is lifetime of access token restricted to password change.
+	restrictedTokenAge = 5 * time.Minute
+)
+
 // TokenRepos represents repositories the token use case interacts with.
 type TokenRepos struct {
 	Token repo.Token
 	Role  repo.Role
+	Event repo.Event
+	User  repo.User
 }
 
 // TokenParams represents parameters for token use case.
+// Attributes of Schema marked as claims are included in access token.
 type TokenParams struct {
-	AccessAge  int
-	RefreshAge int
-	RefreshCap int
+	AccessAge    int
+	RefreshAge   int
+	RefreshCap   int
+	RefreshGrace int
+	Schema       profile.Schema
 }
 
 // Validate compares parameters with min and max values.
@@ -37,7 +53,10 @@ func (p TokenParams) Validate() error {
 	if p.RefreshCap < 1 {
 		return ErrRefreshCapInvalid
 	}
-	return nil
+	if p.RefreshGrace < 0 || p.RefreshGrace > 60 {
+		return ErrRefreshGraceInvalid
+	}
+	return p.Schema.Validate()
 }
 
 // token implements Token interface.
@@ -56,6 +75,20 @@ func NewToken(repos TokenRepos, params TokenParams, jwt jwt.Builder) (*token, er
 	return &token{repos, params, jwt}, nil
 }
 
+// active checks whether user exists and isn't blocked by status.
+// It returns error describing status if user is blocked.
+func (t *token) active(userID uuid.UUID) error {
+	user, err := t.repos.User.GetByID(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrUserNotExist, true)
+		}
+		return NewError(err, false)
+	}
+
+	return checkStatus(user)
+}
+
 // verify compares user's fingerprint with token-related fingerprint.
 // It returns nil if fingerprints are equal.
 func (t *token) verify(token *entity.RefreshToken, fp []byte) error {
@@ -67,10 +100,30 @@ func (t *token) verify(token *entity.RefreshToken, fp []byte) error {
 	return nil
 }
 
-// create creates new access and refresh tokens using user's fingerprint.
+// revoke deletes all refresh tokens of the family
+// and records security event when used token is presented again.
+func (t *token) revoke(token *entity.RefreshToken) error {
+	if err := t.repos.Token.DeleteByFamily(context.Background(), token.FamilyID); err != nil {
+		return NewError(err, false)
+	}
+
+	event := entity.Event{
+		Type:      entity.EventTokenReused,
+		Details:   "token family " + token.FamilyID.String() + " revoked",
+		CreatedAt: time.Now(),
+		UserID:    token.UserID,
+	}
+	if _, err := t.repos.Event.Create(context.Background(), event); err != nil {
+		return NewError(err, false)
+	}
+
+	return NewError(ErrTokenReused, true)
+}
+
+// create creates new access and refresh tokens of the family using user's fingerprint.
 // It returns entity.AccessToken instance
 // and pointer to an entity.RefreshToken instance.
-func (t *token) create(userID uuid.UUID, fp []byte, session bool) (entity.AccessToken, *entity.RefreshToken, error) {
+func (t *token) create(userID uuid.UUID, familyID uuid.UUID, fp []byte, session bool) (entity.AccessToken, *entity.RefreshToken, error) {
 	// fingerprint
 	fpObj := fingerprint.New(userID, fp)
 	fpHash, err := fpObj.Hash()
@@ -79,22 +132,42 @@ func (t *token) create(userID uuid.UUID, fp []byte, session bool) (entity.Access
 	}
 
 	// refresh token
+	value, err := secret.New(refreshTokenSize)
+	if err != nil {
+		return "", nil, NewError(err, false)
+	}
+
 	rtData := entity.RefreshToken{
 		ExpiresAt:   time.Now().AddDate(0, 0, t.params.RefreshAge),
 		Fingerprint: fpHash,
 		Session:     session,
 		UserID:      userID,
+		FamilyID:    familyID,
+		Hash:        secret.Hash(value),
 	}
 
 	rt, err := t.repos.Token.Create(context.Background(), rtData)
 	if err != nil {
 		return "", nil, NewError(err, false)
 	}
+	rt.Token = value
 
 	// access token
+	at, err := t.access(userID, fpHash)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return at, rt, nil
+}
+
+// access creates a new access token using user's roles, attributes and fingerprint hash.
+// User is got only if schema has attributes included in token.
+// It returns entity.AccessToken instance.
+func (t *token) access(userID uuid.UUID, fpHash hash.Hash) (entity.AccessToken, error) {
 	roles, err := t.repos.Role.GetByUser(context.Background(), userID)
 	if err != nil {
-		return "", nil, NewError(err, false)
+		return "", NewError(err, false)
 	}
 
 	roleTitles := make([]string, len(roles))
@@ -102,31 +175,90 @@ func (t *token) create(userID uuid.UUID, fp []byte, session bool) (entity.Access
 		roleTitles[i] = role.Title
 	}
 
-	at, err := t.jwt.Build(userID.String(), time.Duration(t.params.AccessAge)*time.Minute, fpHash.HexString(), roleTitles)
+	var attributes map[string]any
+	if t.params.Schema.HasClaims() {
+		user, err := t.repos.User.GetByID(context.Background(), userID)
+		if err != nil {
+			return "", NewError(err, false)
+		}
+		attributes = t.params.Schema.Claims(user.Attributes)
+	}
+
+	at, err := t.jwt.Build(userID.String(), time.Duration(t.params.AccessAge)*time.Minute, fpHash.HexString(), roleTitles, attributes)
 	if err != nil {
+		return "", NewError(err, false)
+	}
+
+	return entity.AccessToken(at), nil
+}
+
+// successor gets refresh token that replaced used token within grace period,
+// decrypts it with used token value and creates a new access token for it,
+// so that concurrent refreshes with the same token get the same refresh token.
+// It returns entity.AccessToken instance
+// and pointer to an entity.RefreshToken instance.
+func (t *token) successor(token *entity.RefreshToken, value string) (entity.AccessToken, *entity.RefreshToken, error) {
+	if token.ReplacedBy == nil || token.Successor == nil {
+		return "", nil, NewError(ErrTokenIncorrect, true)
+	}
+
+	successor, err := secret.Open(value, token.Successor)
+	if err != nil {
+		return "", nil, NewError(ErrTokenIncorrect, true)
+	}
+
+	rt, err := t.repos.Token.GetByID(context.Background(), *token.ReplacedBy)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return "", nil, NewError(ErrTokenIncorrect, true)
+		}
 		return "", nil, NewError(err, false)
 	}
 
-	return entity.AccessToken(at), rt, nil
+	if rt.UsedAt != nil {
+		return "", nil, NewError(ErrTokenIncorrect, true)
+	}
+	rt.Token = string(successor)
+
+	at, err := t.access(rt.UserID, rt.Fingerprint)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return at, rt, nil
 }
 
-// Create creates new access and refresh tokens using user's fingerprint
-// and deletes old tokens if total number of tokens is greater than RefreshCap.
+// Create creates new access and refresh tokens of a new family using user's fingerprint
+// and deletes the oldest family if total number of unused tokens is greater than RefreshCap.
+// Tokens aren't created for blocked user.
 // It returns entity.AccessToken instance
 // and pointer to an entity.RefreshToken instance.
 func (t *token) Create(userID uuid.UUID, fp []byte, session bool) (entity.AccessToken, *entity.RefreshToken, error) {
+	if err := t.active(userID); err != nil {
+		return "", nil, err
+	}
+
+	if err := t.repos.Token.DeleteExpired(context.Background()); err != nil {
+		return "", nil, NewError(err, false)
+	}
+
 	tokens, err := t.repos.Token.GetByUser(context.Background(), userID)
 	if err != nil {
 		return "", nil, NewError(err, false)
 	}
 
 	if len(tokens) >= t.params.RefreshCap {
-		if err := t.repos.Token.DeleteByID(context.Background(), tokens[0].ID); err != nil {
+		if err := t.repos.Token.DeleteByFamily(context.Background(), tokens[0].FamilyID); err != nil {
 			return "", nil, NewError(err, false)
 		}
 	}
 
-	accessToken, refreshToken, err := t.create(userID, fp, session)
+	familyID, err := uuid.New()
+	if err != nil {
+		return "", nil, NewError(err, false)
+	}
+
+	accessToken, refreshToken, err := t.create(userID, familyID, fp, session)
 	if err != nil {
 		return "", nil, err
 	}
@@ -134,12 +266,39 @@ func (t *token) Create(userID uuid.UUID, fp []byte, session bool) (entity.Access
 	return accessToken, refreshToken, nil
 }
 
-// Refresh verifies user's fingerprint and current refresh token by ID,
-// deletes an old refresh token and creates new access and refresh tokens.
+// CreateRestricted creates a short-lived access token using user's fingerprint
+// that is only valid for password change, refresh token isn't created.
+// Token isn't created for blocked user.
+// It returns entity.AccessToken instance.
+func (t *token) CreateRestricted(userID uuid.UUID, fp []byte) (entity.AccessToken, error) {
+	if err := t.active(userID); err != nil {
+		return "", err
+	}
+
+	fpObj := fingerprint.New(userID, fp)
+	fpHash, err := fpObj.Hash()
+	if err != nil {
+		return "", NewError(err, true)
+	}
+
+	at, err := t.jwt.BuildScoped(userID.String(), restrictedTokenAge, fpHash.HexString(), entity.ScopePassword)
+	if err != nil {
+		return "", NewError(err, false)
+	}
+
+	return entity.AccessToken(at), nil
+}
+
+// Refresh verifies user's fingerprint and current refresh token,
+// creates new access and refresh tokens of the same family
+// and marks an old refresh token as used.
+// If token was used within grace period, its successor is returned.
+// If token was used before grace period, the whole family is revoked.
+// If user is blocked, the whole family is revoked as well.
 // It returns entity.AccessToken instance
 // and pointer to an entity.RefreshToken instance.
-func (t *token) Refresh(id uuid.UUID, fp []byte) (entity.AccessToken, *entity.RefreshToken, error) {
-	token, err := t.Get(id)
+func (t *token) Refresh(value string, fp []byte) (entity.AccessToken, *entity.RefreshToken, error) {
+	token, err := t.Get(value)
 	if err != nil {
 		return "", nil, err
 	}
@@ -148,23 +307,56 @@ func (t *token) Refresh(id uuid.UUID, fp []byte) (entity.AccessToken, *entity.Re
 		return "", nil, err
 	}
 
-	if err := t.repos.Token.DeleteByID(context.Background(), token.ID); err != nil {
-		return "", nil, NewError(err, false)
+	if err := t.active(token.UserID); err != nil {
+		if dErr := t.repos.Token.DeleteByFamily(context.Background(), token.FamilyID); dErr != nil {
+			return "", nil, NewError(dErr, false)
+		}
+		return "", nil, err
 	}
 
-	accessToken, refreshToken, err := t.create(token.UserID, fp, token.Session)
+	if token.UsedAt != nil {
+		return t.successor(token, value)
+	}
+
+	accessToken, refreshToken, err := t.create(token.UserID, token.FamilyID, fp, token.Session)
 	if err != nil {
 		return "", nil, err
 	}
 
+	successor, err := secret.Seal(value, []byte(refreshToken.Token))
+	if err != nil {
+		return "", nil, NewError(err, false)
+	}
+
+	// token may be used concurrently after it was got,
+	// in this case created token is discarded in favor of concurrent one
+	if _, err := t.repos.Token.MarkUsed(context.Background(), token.ID, refreshToken.ID, successor); err != nil {
+		if !errors.Is(err, repo.ErrNoRows) {
+			return "", nil, NewError(err, false)
+		}
+
+		if err := t.repos.Token.DeleteByID(context.Background(), refreshToken.ID); err != nil {
+			return "", nil, NewError(err, false)
+		}
+
+		token, err := t.Get(value)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return t.successor(token, value)
+	}
+
 	return accessToken, refreshToken, nil
 }
 
-// Get gets a refresh token by ID.
+// Get gets a refresh token by hash of opaque token value.
+// If token was used before grace period, the whole family is revoked.
+// If user is blocked, the whole family is revoked as well.
 // It returns pointer to an entity.RefreshToken instance
-// if id is correct and token isn't expired.
-func (t *token) Get(id uuid.UUID) (*entity.RefreshToken, error) {
-	token, err := t.repos.Token.GetByID(context.Background(), id)
+// if value is correct and token isn't expired or used.
+func (t *token) Get(value string) (*entity.RefreshToken, error) {
+	token, err := t.repos.Token.GetByHash(context.Background(), secret.Hash(value))
 	if err != nil {
 		if errors.Is(err, repo.ErrNoRows) {
 			return nil, NewError(ErrTokenIncorrect, true)
@@ -175,14 +367,20 @@ func (t *token) Get(id uuid.UUID) (*entity.RefreshToken, error) {
 	if token.ExpiresAt.Before(time.Now()) {
 		return nil, NewError(ErrTokenExpired, true)
 	}
+
+	grace := time.Duration(t.params.RefreshGrace) * time.Second
+	if token.UsedAt != nil && token.UsedAt.Add(grace).Before(time.Now()) {
+		return nil, t.revoke(token)
+	}
+
 	return token, nil
 }
 
-// Delete verifies user's fingerprint and current refresh token by ID
-// and deletes a refresh token by ID.
-// It returns error if id is incorrect or token is expired.
-func (t *token) Delete(id uuid.UUID, fp []byte) error {
-	token, err := t.Get(id)
+// Delete verifies user's fingerprint and current refresh token
+// and deletes refresh tokens of the same family.
+// It returns error if token is incorrect or expired.
+func (t *token) Delete(value string, fp []byte) error {
+	token, err := t.Get(value)
 	if err != nil {
 		return err
 	}
@@ -191,18 +389,18 @@ func (t *token) Delete(id uuid.UUID, fp []byte) error {
 		return err
 	}
 
-	if err = t.repos.Token.DeleteByID(context.Background(), token.ID); err != nil {
+	if err = t.repos.Token.DeleteByFamily(context.Background(), token.FamilyID); err != nil {
 		return NewError(err, false)
 	}
 
 	return nil
 }
 
-// DeleteAll verifies user's fingerprint and current refresh token by ID
+// DeleteAll verifies user's fingerprint and current refresh token
 // and deletes all user refresh tokens.
-// It returns error if id is incorrect or token is expired.
-func (t *token) DeleteAll(id uuid.UUID, fp []byte) error {
-	token, err := t.Get(id)
+// It returns error if token is incorrect or expired.
+func (t *token) DeleteAll(value string, fp []byte) error {
+	token, err := t.Get(value)
 	if err != nil {
 		return err
 	}
@@ -2,8 +2,12 @@
 package usecase
 
 import (
+	"time"
+
 	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/pkg/password"
 	"github.com/qsoulior/auth-server/pkg/uuid"
+	"github.com/qsoulior/auth-server/pkg/webauthn"
 )
 
 // User is interface implemented by types
@@ -19,16 +23,80 @@ type User interface {
 
 	// Verify verifies user's name and password
 	// and is used in authentication process.
-	// It returns user ID if name and password are correct.
+	// It returns user ID if name and password are correct and user isn't blocked.
 	Verify(data entity.User) (uuid.UUID, error)
 
 	// UpdatePassword updates user's password by user ID
 	// if user exists and currentPassword is correct.
 	UpdatePassword(id uuid.UUID, currentPassword []byte, newPassword []byte) error
 
-	// Delete deletes a user by ID
+	// Delete marks a user as pending deletion by user ID
 	// if user exists and currentPassword is correct.
+	// User is purged after deletion grace period unless restored.
 	Delete(id uuid.UUID, currentPassword []byte) error
+
+	// Restore verifies user's name and password
+	// and clears user's deletion request within deletion grace period.
+	Restore(data entity.User) error
+
+	// Purge deletes users whose deletion grace period has passed.
+	// It returns number of deleted users.
+	Purge() (int64, error)
+
+	// Export gets user by ID with user's roles, sessions,
+	// security events and password history.
+	// It returns pointer to an UserExport instance.
+	Export(id uuid.UUID) (*UserExport, error)
+
+	// List gets page of users matching filter ordered by name.
+	// It returns slice of entity.User instances
+	// and cursor of the next page or empty string if page is last.
+	List(filter UserFilter) ([]entity.User, string, error)
+
+	// UpdateName validates name and updates user's name by user ID
+	// if user exists and name isn't used by another user.
+	UpdateName(id uuid.UUID, name string) error
+
+	// UpdateProfile merges patch into user's attributes by user ID,
+	// null values remove attributes. Only editable attributes can be changed.
+	// It returns updated attributes.
+	UpdateProfile(id uuid.UUID, patch map[string]any) (map[string]any, error)
+
+	// ResetPassword updates user's password by user ID without
+	// current password check and revokes all user refresh tokens.
+	ResetPassword(id uuid.UUID, newPassword []byte) error
+
+	// ForceDelete marks a user as pending deletion by ID without current password check.
+	// User can be restored within deletion grace period.
+	ForceDelete(id uuid.UUID) error
+
+	// ForcePurge deletes a user by ID immediately without deletion grace period.
+	ForcePurge(id uuid.UUID) error
+
+	// ForceRestore clears user's deletion request by user ID
+	// without credentials check within deletion grace period.
+	ForceRestore(id uuid.UUID) error
+
+	// UpdateStatus changes user's status by user ID and records reason.
+	// Disabled user's refresh tokens are revoked.
+	UpdateStatus(id uuid.UUID, status string, until *time.Time, reason string) error
+
+	// ForceUpdateProfile merges patch into user's attributes by user ID
+	// including attributes that user can't edit.
+	// It returns updated attributes.
+	ForceUpdateProfile(id uuid.UUID, patch map[string]any) (map[string]any, error)
+
+	// PasswordExpired reports whether user must change password
+	// because it is flagged or older than password age of user's roles.
+	PasswordExpired(id uuid.UUID) (bool, error)
+
+	// ExpirePassword flags user's password so that it must be changed on next login.
+	ExpirePassword(id uuid.UUID) error
+
+	// Strength estimates strength of password the same way registration does
+	// and checks password against policy, user's name is blocked.
+	// It returns password.Strength and names of failed rules.
+	Strength(plain []byte, name string) (password.Strength, []string, error)
 }
 
 // Token is interface implemented by types
@@ -39,26 +107,31 @@ type Token interface {
 	// and pointer to an entity.RefreshToken instance.
 	Create(userID uuid.UUID, fingerprint []byte, session bool) (entity.AccessToken, *entity.RefreshToken, error)
 
-	// Refresh verifies user's fingerprint and current refresh token by ID
+	// CreateRestricted creates a short-lived access token using user's fingerprint
+	// that is only valid for password change.
+	// It returns entity.AccessToken instance.
+	CreateRestricted(userID uuid.UUID, fingerprint []byte) (entity.AccessToken, error)
+
+	// Refresh verifies user's fingerprint and current refresh token
 	// and creates new access and refresh tokens.
 	// It returns entity.AccessToken instance
 	// and pointer to an entity.RefreshToken instance.
-	Refresh(id uuid.UUID, fingerprint []byte) (entity.AccessToken, *entity.RefreshToken, error)
+	Refresh(token string, fingerprint []byte) (entity.AccessToken, *entity.RefreshToken, error)
 
-	// Get gets a refresh token by ID.
+	// Get gets a refresh token by opaque token value.
 	// It returns pointer to an entity.RefreshToken instance
-	// if id is correct and token isn't expired.
-	Get(id uuid.UUID) (*entity.RefreshToken, error)
+	// if token is correct and isn't expired.
+	Get(token string) (*entity.RefreshToken, error)
 
-	// Delete verifies user's fingerprint and current refresh token by ID
-	// and deletes a refresh token by ID.
-	// It returns error if id is incorrect or token is expired.
-	Delete(id uuid.UUID, fingerprint []byte) error
+	// Delete verifies user's fingerprint and current refresh token
+	// and deletes a refresh token.
+	// It returns error if token is incorrect or expired.
+	Delete(token string, fingerprint []byte) error
 
-	// DeleteAll verifies user's fingerprint and current refresh token by ID
+	// DeleteAll verifies user's fingerprint and current refresh token
 	// and deletes all user refresh tokens.
-	// It returns error if id is incorrect or token is expired.
-	DeleteAll(id uuid.UUID, fingerprint []byte) error
+	// It returns error if token is incorrect or expired.
+	DeleteAll(token string, fingerprint []byte) error
 }
 
 // Auth is interface implemented by types
@@ -68,4 +141,201 @@ type Auth interface {
 	// and retrieves user ID and roles from it.
 	// It returns user ID and roles if token is correct and not expired.
 	Verify(token entity.AccessToken, fingerprint []byte) (uuid.UUID, []string, error)
+
+	// VerifyScope verifies user's fingerprint, parses access token,
+	// and retrieves user ID from it.
+	// Both unrestricted tokens and tokens restricted to scope are accepted.
+	// It returns user ID if token is correct and not expired.
+	VerifyScope(token entity.AccessToken, fingerprint []byte, scope string) (uuid.UUID, error)
+}
+
+// Role is interface implemented by types
+// that can encapsulate role logic.
+type Role interface {
+	// Create validates data and creates a new role.
+	// It returns pointer to an entity.Role instance.
+	Create(data entity.Role) (*entity.Role, error)
+
+	// List gets all roles.
+	// It returns slice of entity.Role instances.
+	List() ([]entity.Role, error)
+
+	// Get gets a role by ID.
+	// It returns pointer to an entity.Role instance.
+	Get(id uuid.UUID) (*entity.Role, error)
+
+	// Update validates data and updates role's title and description by ID.
+	// It returns pointer to an entity.Role instance.
+	Update(data entity.Role) (*entity.Role, error)
+
+	// Delete deletes a role by ID.
+	Delete(id uuid.UUID) error
+
+	// Assign assigns a role to user by role ID and user ID.
+	Assign(id uuid.UUID, userID uuid.UUID) error
+
+	// Unassign unassigns a role from user by role ID and user ID.
+	Unassign(id uuid.UUID, userID uuid.UUID) error
+
+	// ListByUser gets roles assigned to user by user ID.
+	// It returns slice of entity.Role instances.
+	ListByUser(userID uuid.UUID) ([]entity.Role, error)
+
+	// ListUsers gets users the role is assigned to by role ID.
+	// It returns slice of entity.User instances.
+	ListUsers(id uuid.UUID) ([]entity.User, error)
+}
+
+// MFA is interface implemented by types
+// that can encapsulate multi-factor authentication logic.
+type MFA interface {
+	// Enroll generates a new TOTP secret for user.
+	// It returns encoded secret and otpauth:// URI.
+	Enroll(userID uuid.UUID) (string, string, error)
+
+	// Confirm verifies the first code and enables MFA for user.
+	Confirm(userID uuid.UUID, code string) error
+
+	// Disable verifies code and disables MFA for user.
+	Disable(userID uuid.UUID, code string) error
+
+	// Reset disables MFA for user without code
+	// by deleting TOTP secret, WebAuthn credentials and recovery codes.
+	Reset(userID uuid.UUID) error
+
+	// GenerateRecovery replaces user's recovery codes with a new set.
+	// It returns slice of codes.
+	GenerateRecovery(userID uuid.UUID) ([]string, error)
+
+	// CountRecovery counts user's remaining recovery codes.
+	CountRecovery(userID uuid.UUID) (int, error)
+
+	// Challenge checks user's status and creates a new challenge for user if MFA is enabled.
+	// It returns pointer to an entity.Challenge instance or nil if MFA isn't enabled.
+	Challenge(userID uuid.UUID, session bool) (*entity.Challenge, error)
+
+	// Complete verifies challenge token and code.
+	// It returns pointer to a completed entity.Challenge instance.
+	Complete(value string, code string) (*entity.Challenge, error)
+
+	// Recover verifies challenge token and recovery code used in place of second factor.
+	// It returns pointer to a completed entity.Challenge instance.
+	Recover(value string, code string) (*entity.Challenge, error)
+
+	// Fail records failed attempt of challenge completed by second factor
+	// verified outside MFA use case.
+	// It returns error passed as err if no other error occurred.
+	Fail(challengeID uuid.UUID, userID uuid.UUID, err error) error
+
+	// SealSecrets encrypts TOTP secrets stored before encryption was introduced.
+	// It returns number of encrypted secrets.
+	SealSecrets() (int, error)
+}
+
+// WebAuthn is interface implemented by types
+// that can encapsulate WebAuthn registration and authentication logic.
+type WebAuthn interface {
+	// BeginRegistration creates registration ceremony for user.
+	// It returns pointer to an entity.Ceremony instance and creation options.
+	BeginRegistration(userID uuid.UUID) (*entity.Ceremony, *webauthn.CreationOptions, error)
+
+	// FinishRegistration verifies registration response and stores a new credential.
+	// It returns pointer to an entity.Credential instance.
+	FinishRegistration(userID uuid.UUID, ceremonyID uuid.UUID, name string, response webauthn.RegistrationResponse) (*entity.Credential, error)
+
+	// ListCredentials gets credentials registered by user.
+	// It returns slice of entity.Credential instances.
+	ListCredentials(userID uuid.UUID) ([]entity.Credential, error)
+
+	// DeleteCredential deletes user's credential by ID.
+	DeleteCredential(userID uuid.UUID, id []byte) error
+
+	// BeginLogin creates authentication ceremony without password
+	// or completing MFA challenge if its value is set.
+	// It returns pointer to an entity.Ceremony instance and request options.
+	BeginLogin(value string, session bool) (*entity.Ceremony, *webauthn.RequestOptions, error)
+
+	// FinishLogin verifies authentication response.
+	// It returns pointer to an entity.Ceremony instance with authenticated user ID.
+	FinishLogin(ceremonyID uuid.UUID, response webauthn.AuthenticationResponse) (*entity.Ceremony, error)
+}
+
+// Email is interface implemented by types
+// that can encapsulate email verification logic.
+type Email interface {
+	// Update validates and normalizes address, updates user's email by user ID
+	// and sends verification link to address.
+	Update(userID uuid.UUID, address string) error
+
+	// Verify verifies token from verification link
+	// and marks email from token as verified.
+	Verify(token string) error
+}
+
+// Reset is interface implemented by types
+// that can encapsulate password reset logic.
+type Reset interface {
+	// Forgot sends reset link to user with email.
+	// It returns nil whether or not user exists.
+	Forgot(address string) error
+
+	// Reset consumes reset token, updates user's password
+	// and deletes all user's refresh tokens.
+	Reset(value string, password []byte) error
+}
+
+// MagicLink is interface implemented by types
+// that can send and consume passwordless login links.
+type MagicLink interface {
+	// Send sends login link bound to client's fingerprint to user with email.
+	// It returns nil whether or not user exists
+	// and remaining lock duration if address requested too many links.
+	Send(address string, fingerprint []byte) (time.Duration, error)
+
+	// Consume consumes login link token and verifies client's fingerprint.
+	// It returns user ID the link was sent to.
+	Consume(value string, fingerprint []byte) (uuid.UUID, error)
+}
+
+// SAML is interface implemented by types
+// that can encapsulate SAML single sign-on logic.
+type SAML interface {
+	// Metadata creates service provider metadata.
+	// It returns XML document bytes.
+	Metadata() ([]byte, error)
+
+	// Request creates a new authentication request.
+	// It returns request ID and URL of identity provider to redirect user to.
+	Request(relayState string) (string, string, error)
+
+	// Login validates response from identity provider, gets or creates a user
+	// and synchronizes user's roles using assertion attributes.
+	// It returns redirect URL containing one-time code that can be exchanged for tokens.
+	Login(response string, requestID string, relayState string) (string, error)
+
+	// Exchange consumes one-time code issued by Login.
+	// It returns user ID if code is correct and not expired.
+	Exchange(code string) (uuid.UUID, error)
+}
+
+// Lockout is interface implemented by types
+// that can encapsulate brute-force protection logic.
+type Lockout interface {
+	// Check checks whether username or client IP is locked
+	// and atomically counts attempt of those that aren't locked.
+	// It returns remaining lock duration and error if any of them is locked.
+	Check(name string, ip string) (time.Duration, error)
+
+	// Fail locks username and client IP after counted attempt failed.
+	// It returns duration until next attempt is allowed.
+	Fail(name string, ip string) (time.Duration, error)
+
+	// Release uncounts attempt of username and client IP after credentials turned out to be correct.
+	Release(name string, ip string) error
+
+	// Succeed forgets failed attempts of username.
+	Succeed(name string) error
+
+	// Unlock forgets failed attempts of user's name by user ID.
+	Unlock(userID uuid.UUID) error
 }
@@ -2,73 +2,105 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
-	"strings"
+	"slices"
+	"time"
+	"unicode/utf8"
 
 	"github.com/qsoulior/auth-server/internal/entity"
 	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/password"
+	"github.com/qsoulior/auth-server/pkg/profile"
+	"github.com/qsoulior/auth-server/pkg/username"
 	"github.com/qsoulior/auth-server/pkg/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
-const (
-	lowerChars   = `abcdefghijklmnopqrstuvwxyz`
-	upperChars   = `ABCDEFGHIJKLMNOPQRSTUVWXYZ`
-	digitChars   = `0123456789`
-	specialChars = ` !"#$%&'()*+,-./:;<=>?@[\]^_{|}~`
-)
+// validateName checks name against username policy.
+// It returns error describing failed rule if name is invalid.
+func validateName(policy username.Policy, name string) error {
+	if err := policy.Check(name); err != nil {
+		return NewError(err, true)
+	}
+
+	return nil
+}
 
-// validateName returns error if name is invalid.
-func validateName(name string) error {
-	if length := len(name); length < 4 || length > 20 {
-		return NewError(ErrNameInvalid, true)
+// checkStatus checks whether user is allowed to log in and refresh tokens.
+// Suspended user is allowed again after end of suspension,
+// user who requested deletion isn't allowed until restored.
+// It returns error describing status if user is blocked.
+func checkStatus(user *entity.User) error {
+	if user.DeletedAt != nil {
+		return NewError(ErrUserDeleted, true)
 	}
 
-	for _, r := range name {
-		if !strings.ContainsRune(lowerChars+upperChars+digitChars+"_", r) {
-			return NewError(ErrNameInvalid, true)
+	switch user.Status {
+	case entity.StatusDisabled:
+		return NewError(ErrUserDisabled, true)
+	case entity.StatusPending:
+		return NewError(ErrUserPending, true)
+	case entity.StatusSuspended:
+		if user.SuspendedUntil == nil || user.SuspendedUntil.After(time.Now()) {
+			return NewError(ErrUserSuspended, true)
 		}
 	}
 
 	return nil
 }
 
-// validatePassword returns error if password is invalid.
-func validatePassword(password []byte) error {
-	if length := len(password); length < 8 || length > 72 {
-		return NewError(ErrPasswordInvalid, true)
+// applyAttributes merges patch into user's attributes according to schema,
+// only editable attributes are changed unless admin is set.
+// It returns merged attributes or AttributesError listing invalid attributes.
+func applyAttributes(schema profile.Schema, attributes map[string]any, patch map[string]any, admin bool) (map[string]any, error) {
+	result, err := schema.Apply(attributes, patch, admin)
+	if err != nil {
+		var e *profile.Error
+		if errors.As(err, &e) {
+			return nil, NewError(&AttributesError{e.Fields}, true)
+		}
+		return nil, NewError(err, false)
 	}
 
-	var lower, upper, digit, special bool
+	return result, nil
+}
 
-	for _, r := range string(password) {
-		switch {
-		case strings.ContainsRune(lowerChars, r):
-			lower = true
-		case strings.ContainsRune(upperChars, r):
-			upper = true
-		case strings.ContainsRune(digitChars, r):
-			digit = true
-		case strings.ContainsRune(specialChars, r):
-			special = true
-		}
+// checkPassword checks password against policy, user's name is blocked.
+// Password mustn't be longer than number of bytes used by hasher.
+// It returns names of failed rules or nil if password is valid.
+func checkPassword(policy password.Policy, hasher password.Hasher, plain []byte, name string) ([]string, error) {
+	rules, err := policy.Check(plain, name)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
 
-		if lower && upper && digit && special {
-			return nil
-		}
+	if len(password.Normalize(plain)) > hasher.MaxLength() && !slices.Contains(rules, password.RuleMaxLength) {
+		rules = append(rules, password.RuleMaxLength)
 	}
 
-	return NewError(ErrPasswordInvalid, true)
+	return rules, nil
 }
 
-// hashPassword validates password and hashes it using bcrypt algorithm.
-// It returns nil if password is invalid or hashing failed.
-func hashPassword(password []byte, hashCost int) ([]byte, error) {
-	if err := validatePassword(password); err != nil {
-		return nil, err
+// validatePassword checks password against policy, user's name is blocked.
+// It returns PasswordError listing failed rules if password is invalid.
+func validatePassword(policy password.Policy, hasher password.Hasher, plain []byte, name string) error {
+	rules, err := checkPassword(policy, hasher, plain, name)
+	if err != nil {
+		return err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword(password, hashCost)
+	if len(rules) > 0 {
+		return NewError(&PasswordError{rules}, true)
+	}
+
+	return nil
+}
+
+// hashPassword normalizes password and hashes it using hasher.
+// Password must be validated before hashing.
+// It returns nil if hashing failed.
+func hashPassword(hasher password.Hasher, plain []byte) ([]byte, error) {
+	hash, err := hasher.Hash(password.Normalize(plain))
 	if err != nil {
 		return nil, NewError(err, false)
 	}
@@ -76,76 +108,238 @@ func hashPassword(password []byte, hashCost int) ([]byte, error) {
 	return hash, nil
 }
 
-// verifyPassword compares hashedPassword with password.
+// ruleHistory is name of failed rule if password was used before.
+const ruleHistory = "history"
+
+// checkHistory compares password with user's current password
+// and the last size passwords from history using hasher.
+// History isn't checked if size is zero.
+// It returns PasswordError if password was used before.
+func checkHistory(hasher password.Hasher, historyRepo repo.History, user *entity.User, plain []byte, size int) error {
+	if size == 0 {
+		return nil
+	}
+
+	history, err := historyRepo.GetByUser(context.Background(), user.ID, size)
+	if err != nil {
+		return NewError(err, false)
+	}
+
+	hashes := [][]byte{user.Password}
+	for _, entry := range history {
+		hashes = append(hashes, entry.Hash)
+	}
+
+	for _, hash := range hashes {
+		err := verifyPassword(hasher, hash, plain)
+		if err == nil {
+			return NewError(&PasswordError{[]string{ruleHistory}}, true)
+		}
+		if !errors.Is(err, ErrPasswordIncorrect) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addHistory stores hash of user's new password in history
+// keeping only the last size entries.
+// History isn't stored if size is zero.
+func addHistory(historyRepo repo.History, userID uuid.UUID, hash []byte, size int) error {
+	if size == 0 {
+		return nil
+	}
+
+	data := entity.PasswordHistory{Hash: hash, CreatedAt: time.Now(), UserID: userID}
+	if err := historyRepo.Create(context.Background(), data, size); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// preparePassword validates new password of user against policy and password history
+// and hashes it using hasher.
+// It returns hash of password or error if password is invalid or was used before.
+func preparePassword(policy password.Policy, hasher password.Hasher, historyRepo repo.History, user *entity.User, plain []byte, size int) ([]byte, error) {
+	if err := validatePassword(policy, hasher, plain, user.Name); err != nil {
+		return nil, err
+	}
+
+	if err := checkHistory(hasher, historyRepo, user, plain, size); err != nil {
+		return nil, err
+	}
+
+	return hashPassword(hasher, plain)
+}
+
+// storePassword updates user's password hash by user ID
+// and stores it in password history keeping only the last size entries.
+func storePassword(userRepo repo.User, historyRepo repo.History, userID uuid.UUID, hash []byte, size int) error {
+	if err := userRepo.UpdatePassword(context.Background(), userID, hash); err != nil {
+		return NewError(err, false)
+	}
+
+	return addHistory(historyRepo, userID, hash, size)
+}
+
+// verifyPassword compares hashedPassword with normalized password using hasher.
+// Password is also compared as is if it was hashed before normalization was used.
+// Users without local password never match.
 // It returns nil if passwords are equal.
-func verifyPassword(hashedPassword []byte, password []byte) error {
-	if err := bcrypt.CompareHashAndPassword(hashedPassword, password); err != nil {
+func verifyPassword(hasher password.Hasher, hashedPassword []byte, plain []byte) error {
+	if len(hashedPassword) == 0 {
 		return NewError(ErrPasswordIncorrect, true)
 	}
 
+	normalized := password.Normalize(plain)
+	err := hasher.Compare(hashedPassword, normalized)
+	if errors.Is(err, password.ErrMismatch) && string(normalized) != string(plain) {
+		err = hasher.Compare(hashedPassword, plain)
+	}
+
+	if err != nil {
+		if errors.Is(err, password.ErrMismatch) {
+			return NewError(ErrPasswordIncorrect, true)
+		}
+		return NewError(err, false)
+	}
+
 	return nil
 }
 
 // UserRepos represents repositories the user use case interacts with.
 type UserRepos struct {
-	User repo.User
+	User    repo.User
+	Token   repo.Token
+	Role    repo.Role
+	History repo.History
+	Event   repo.Event
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	// maxReasonLength is max number of characters in reason of status change.
+	maxReasonLength = 256
+
+	// maxHistorySize is max number of passwords kept in history.
+	maxHistorySize = 24
+)
+
+// UserFilter represents filter and page of users.
+// Empty fields aren't used in filter.
+// Status is compared with stored status regardless of end of suspension.
+// Cursor is returned by previous page, Limit is set to default if zero.
+type UserFilter struct {
+	NamePrefix string
+	Role       string
+	Status     string
+	Cursor     string
+	Limit      int
 }
 
 // UserParams represents parameters for user use case.
+// HistorySize is number of last passwords that can't be used again,
+// history is disabled if zero.
+// PasswordAge maps role titles to number of days after which password expires,
+// AnyRole key applies to all users, passwords don't expire if it's empty.
+// Schema describes custom attributes of user profile.
+// DeletionGrace is number of days user can restore account after deletion request.
 type UserParams struct {
-	HashCost int
+	HistorySize   int
+	PasswordAge   map[string]int
+	Schema        profile.Schema
+	DeletionGrace int
 }
 
+// AnyRole is key of UserParams.PasswordAge that applies to all users.
+const AnyRole = "*"
+
 // Validate compares parameters with min and max values.
 // It returns error if at least one of parameters is invalid.
 func (p UserParams) Validate() error {
-	if p.HashCost < bcrypt.MinCost || p.HashCost > bcrypt.MaxCost {
-		return ErrHashCostInvalid
+	if p.HistorySize < 0 || p.HistorySize > maxHistorySize {
+		return ErrHistorySizeInvalid
 	}
-	return nil
+	for _, age := range p.PasswordAge {
+		if age < 1 || age > 3650 {
+			return ErrPasswordAgeInvalid
+		}
+	}
+	if p.DeletionGrace < 1 || p.DeletionGrace > 365 {
+		return ErrDeletionGraceInvalid
+	}
+	return p.Schema.Validate()
 }
 
 // user implements User interface.
 type user struct {
-	repos  UserRepos
-	params UserParams
+	repos    UserRepos
+	params   UserParams
+	hasher   password.Hasher
+	policy   password.Policy
+	names    username.Policy
+	verifier Verifier
 }
 
 // NewUser validates parameters and creates a new user use case.
+// Passwords are checked against policy and hashed by hasher,
+// names are checked against names policy,
+// credentials are verified by verifier in authentication process.
 // It returns pointer to an user instance or nil if parameters are invalid.
-func NewUser(repos UserRepos, params UserParams) (*user, error) {
+func NewUser(repos UserRepos, params UserParams, hasher password.Hasher, policy password.Policy, names username.Policy, verifier Verifier) (*user, error) {
 	if err := params.Validate(); err != nil {
 		return nil, err
 	}
-	return &user{repos, params}, nil
+	return &user{repos, params, hasher, policy, names, verifier}, nil
 }
 
 // Create validates data and creates a new user.
+// Name is normalized and compared with other names regardless of case,
+// attributes are checked against schema.
 // It returns pointer to an entity.User instance or nil if an error occurred.
 func (u *user) Create(data entity.User) (*entity.User, error) {
-	_, err := u.repos.User.GetByName(context.Background(), data.Name)
+	data.Name, data.NameKey = username.Normalize(data.Name), username.Fold(data.Name)
+
+	_, err := u.repos.User.GetByName(context.Background(), data.NameKey)
 	if err == nil {
 		return nil, NewError(ErrUserExists, true)
 	} else if !errors.Is(err, repo.ErrNoRows) {
 		return nil, NewError(err, false)
 	}
 
-	if err := validateName(data.Name); err != nil {
+	if err := validateName(u.names, data.Name); err != nil {
 		return nil, err
 	}
 
-	hash, err := hashPassword(data.Password, u.params.HashCost)
+	if err := validatePassword(u.policy, u.hasher, data.Password, data.Name); err != nil {
+		return nil, err
+	}
+
+	attributes, err := applyAttributes(u.params.Schema, nil, data.Attributes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(u.hasher, data.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	data.Password = hash
+	data.Password, data.Attributes = hash, attributes
 
 	user, err := u.repos.User.Create(context.Background(), data)
 	if err != nil {
 		return nil, NewError(err, false)
 	}
 
+	if err := addHistory(u.repos.History, user.ID, hash, u.params.HistorySize); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
@@ -163,20 +357,19 @@ func (u *user) Get(id uuid.UUID) (*entity.User, error) {
 	return user, nil
 }
 
-// Verify verifies user's name and password
+// Verify verifies user's name and password using verifier
 // and is used in authentication process.
-// It returns user ID if name and password are correct
+// Local verifier rehashes password if hash is outdated.
+// Status is checked only if password is correct, so that it isn't revealed.
+// It returns user ID if name and password are correct and user isn't blocked
 // or empty UUID if an error occurred.
 func (u *user) Verify(data entity.User) (uuid.UUID, error) {
-	user, err := u.repos.User.GetByName(context.Background(), data.Name)
+	user, err := u.verifier.Verify(data.Name, data.Password)
 	if err != nil {
-		if errors.Is(err, repo.ErrNoRows) {
-			return uuid.UUID{}, NewError(ErrUserNotExist, true)
-		}
-		return uuid.UUID{}, NewError(err, false)
+		return uuid.UUID{}, err
 	}
 
-	if err := verifyPassword(user.Password, data.Password); err != nil {
+	if err := checkStatus(user); err != nil {
 		return uuid.UUID{}, err
 	}
 
@@ -184,38 +377,331 @@ func (u *user) Verify(data entity.User) (uuid.UUID, error) {
 }
 
 // UpdatePassword updates user's password by user ID
-// if user exists and currentPassword is correct.
+// if user exists, currentPassword is correct
+// and newPassword isn't found in user's password history.
 func (u *user) UpdatePassword(id uuid.UUID, currentPassword []byte, newPassword []byte) error {
 	user, err := u.Get(id)
 	if err != nil {
 		return err
 	}
 
-	if err = verifyPassword(user.Password, currentPassword); err != nil {
+	if err = verifyPassword(u.hasher, user.Password, currentPassword); err != nil {
 		return err
 	}
 
-	hashedPassword, err := hashPassword(newPassword, u.params.HashCost)
+	hashedPassword, err := preparePassword(u.policy, u.hasher, u.repos.History, user, newPassword, u.params.HistorySize)
 	if err != nil {
 		return err
 	}
 
-	if err = u.repos.User.UpdatePassword(context.Background(), user.ID, hashedPassword); err != nil {
-		return NewError(err, false)
+	if err := storePassword(u.repos.User, u.repos.History, user.ID, hashedPassword, u.params.HistorySize); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// Delete deletes a user by ID
+// Delete marks a user as pending deletion by user ID
 // if user exists and currentPassword is correct.
+// User can't log in and all user refresh tokens are revoked,
+// user is purged after deletion grace period unless restored.
+// It returns error if user is already pending deletion.
 func (u *user) Delete(id uuid.UUID, currentPassword []byte) error {
 	user, err := u.Get(id)
 	if err != nil {
 		return err
 	}
 
-	if err = verifyPassword(user.Password, currentPassword); err != nil {
+	if err = verifyPassword(u.hasher, user.Password, currentPassword); err != nil {
+		return err
+	}
+
+	return u.delete(user, "deletion requested")
+}
+
+// delete marks user as pending deletion, revokes all user's refresh tokens
+// and records details in security events.
+// User already pending deletion is rejected, so that deletion grace period isn't extended.
+func (u *user) delete(user *entity.User, details string) error {
+	if user.DeletedAt != nil {
+		return NewError(ErrUserDeleted, true)
+	}
+
+	now := time.Now()
+	if err := u.repos.User.UpdateDeletedAt(context.Background(), user.ID, &now); err != nil {
+		return NewError(err, false)
+	}
+
+	if err := u.repos.Token.DeleteByUser(context.Background(), user.ID); err != nil {
+		return NewError(err, false)
+	}
+
+	return u.event(user.ID, entity.EventDeleted, details)
+}
+
+// restore clears user's deletion request if deletion grace period hasn't passed.
+// Users whose grace period has passed are treated as deleted.
+func (u *user) restore(user *entity.User) error {
+	if user.DeletedAt == nil {
+		return NewError(ErrUserNotDeleted, true)
+	}
+
+	if user.DeletedAt.AddDate(0, 0, u.params.DeletionGrace).Before(time.Now()) {
+		return NewError(ErrUserNotExist, true)
+	}
+
+	if err := u.repos.User.UpdateDeletedAt(context.Background(), user.ID, nil); err != nil {
+		return NewError(err, false)
+	}
+
+	return u.event(user.ID, entity.EventRestored, "deletion cancelled")
+}
+
+// Restore verifies user's name and password using verifier
+// and clears user's deletion request within deletion grace period.
+func (u *user) Restore(data entity.User) error {
+	user, err := u.verifier.Verify(data.Name, data.Password)
+	if err != nil {
+		return err
+	}
+
+	return u.restore(user)
+}
+
+// Purge deletes users whose deletion grace period has passed.
+// It returns number of deleted users.
+func (u *user) Purge() (int64, error) {
+	before := time.Now().AddDate(0, 0, -u.params.DeletionGrace)
+	ids, err := u.repos.User.DeleteRequested(context.Background(), before)
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	for _, id := range ids {
+		if err := u.event(id, entity.EventPurged, "purged after deletion grace period"); err != nil {
+			return int64(len(ids)), err
+		}
+	}
+
+	return int64(len(ids)), nil
+}
+
+// UserExport represents all data stored about user.
+// Sessions are unused refresh tokens, History contains
+// times of password changes without hashes.
+type UserExport struct {
+	User     *entity.User
+	Roles    []entity.Role
+	Sessions []entity.RefreshToken
+	Events   []entity.Event
+	History  []entity.PasswordHistory
+}
+
+// Export gets user by ID with user's roles, sessions,
+// security events and password history.
+// It returns pointer to an UserExport instance or nil if an error occurred.
+func (u *user) Export(id uuid.UUID) (*UserExport, error) {
+	user, err := u.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := u.repos.Role.GetByUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	sessions, err := u.repos.Token.GetByUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	events, err := u.repos.Event.GetByUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	history, err := u.repos.History.GetByUser(context.Background(), user.ID, maxHistorySize)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return &UserExport{user, roles, sessions, events, history}, nil
+}
+
+// event records security event related to user.
+func (u *user) event(userID uuid.UUID, eventType string, details string) error {
+	event := entity.Event{
+		Type:      eventType,
+		Details:   details,
+		CreatedAt: time.Now(),
+		UserID:    userID,
+	}
+	if _, err := u.repos.Event.Create(context.Background(), event); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// List gets page of users matching filter ordered by name.
+// It returns slice of entity.User instances
+// and cursor of the next page or empty string if page is last.
+func (u *user) List(filter UserFilter) ([]entity.User, string, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+
+	if filter.Limit < 1 || filter.Limit > maxPageSize {
+		return nil, "", NewError(ErrLimitInvalid, true)
+	}
+
+	switch filter.Status {
+	case "", entity.StatusActive, entity.StatusSuspended, entity.StatusDisabled, entity.StatusPending:
+	default:
+		return nil, "", NewError(ErrStatusInvalid, true)
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err != nil {
+		return nil, "", NewError(ErrCursorInvalid, true)
+	}
+
+	// one more user is got to find out whether the next page exists
+	users, err := u.repos.User.GetByFilter(context.Background(), repo.UserFilter{
+		NamePrefix: username.Fold(filter.NamePrefix),
+		Role:       filter.Role,
+		Status:     filter.Status,
+		After:      string(after),
+		Limit:      filter.Limit + 1,
+	})
+	if err != nil {
+		return nil, "", NewError(err, false)
+	}
+
+	if len(users) <= filter.Limit {
+		return users, "", nil
+	}
+
+	users = users[:filter.Limit]
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(users[len(users)-1].Name))
+	return users, cursor, nil
+}
+
+// UpdateName validates name and updates user's name by user ID
+// if user exists and name isn't used by another user regardless of case.
+// User can change case of own name.
+func (u *user) UpdateName(id uuid.UUID, name string) error {
+	user, err := u.Get(id)
+	if err != nil {
+		return err
+	}
+
+	name, nameKey := username.Normalize(name), username.Fold(name)
+	if err := validateName(u.names, name); err != nil {
+		return err
+	}
+
+	existing, err := u.repos.User.GetByName(context.Background(), nameKey)
+	if err == nil && existing.ID != user.ID {
+		return NewError(ErrUserExists, true)
+	} else if err != nil && !errors.Is(err, repo.ErrNoRows) {
+		return NewError(err, false)
+	}
+
+	if err := u.repos.User.UpdateName(context.Background(), user.ID, name, nameKey); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// updateProfile merges patch into user's attributes by user ID
+// if user exists and patch satisfies schema.
+// Only editable attributes are changed unless admin is set.
+// It returns updated attributes or nil if an error occurred.
+func (u *user) updateProfile(id uuid.UUID, patch map[string]any, admin bool) (map[string]any, error) {
+	user, err := u.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := applyAttributes(u.params.Schema, user.Attributes, patch, admin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.repos.User.UpdateAttributes(context.Background(), user.ID, attributes); err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return attributes, nil
+}
+
+// UpdateProfile merges patch into user's attributes by user ID,
+// null values remove attributes. Only editable attributes can be changed.
+// It returns updated attributes or nil if an error occurred.
+func (u *user) UpdateProfile(id uuid.UUID, patch map[string]any) (map[string]any, error) {
+	return u.updateProfile(id, patch, false)
+}
+
+// ResetPassword updates user's password by user ID without
+// current password check and revokes all user refresh tokens.
+// New password mustn't be found in user's password history
+// and must be changed by user on next login.
+func (u *user) ResetPassword(id uuid.UUID, newPassword []byte) error {
+	user, err := u.Get(id)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := preparePassword(u.policy, u.hasher, u.repos.History, user, newPassword, u.params.HistorySize)
+	if err != nil {
+		return err
+	}
+
+	if err := storePassword(u.repos.User, u.repos.History, user.ID, hashedPassword, u.params.HistorySize); err != nil {
+		return err
+	}
+
+	if err = u.repos.User.RequirePasswordChange(context.Background(), user.ID); err != nil {
+		return NewError(err, false)
+	}
+
+	if err = u.repos.Token.DeleteByUser(context.Background(), user.ID); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// ForceRestore clears user's deletion request by user ID
+// without credentials check within deletion grace period.
+func (u *user) ForceRestore(id uuid.UUID) error {
+	user, err := u.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return u.restore(user)
+}
+
+// ForceDelete marks a user as pending deletion by ID without current password check
+// the same way as Delete, so that user can be restored within deletion grace period.
+func (u *user) ForceDelete(id uuid.UUID) error {
+	user, err := u.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return u.delete(user, "deletion requested by administrator")
+}
+
+// ForcePurge deletes a user by ID immediately without deletion grace period.
+// User's events are deleted and purge is recorded in security events.
+func (u *user) ForcePurge(id uuid.UUID) error {
+	user, err := u.Get(id)
+	if err != nil {
 		return err
 	}
 
@@ -223,5 +709,143 @@ func (u *user) Delete(id uuid.UUID, currentPassword []byte) error {
 		return NewError(err, false)
 	}
 
+	return u.event(user.ID, entity.EventPurged, "purged by administrator")
+}
+
+// ForceUpdateProfile merges patch into user's attributes by user ID
+// including attributes that user can't edit.
+// It returns updated attributes or nil if an error occurred.
+func (u *user) ForceUpdateProfile(id uuid.UUID, patch map[string]any) (map[string]any, error) {
+	return u.updateProfile(id, patch, true)
+}
+
+// UpdateStatus changes user's status by user ID and records reason in security events.
+// Until must be set in the future for suspended user and must be nil otherwise.
+// Disabled user's refresh tokens are revoked, other blocked users
+// lose their sessions on next refresh.
+func (u *user) UpdateStatus(id uuid.UUID, status string, until *time.Time, reason string) error {
+	switch status {
+	case entity.StatusSuspended:
+		if until == nil || !until.After(time.Now()) {
+			return NewError(ErrSuspensionInvalid, true)
+		}
+	case entity.StatusActive, entity.StatusDisabled, entity.StatusPending:
+		if until != nil {
+			return NewError(ErrSuspensionInvalid, true)
+		}
+	default:
+		return NewError(ErrStatusInvalid, true)
+	}
+
+	if utf8.RuneCountInString(reason) > maxReasonLength {
+		return NewError(ErrReasonInvalid, true)
+	}
+
+	user, err := u.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repos.User.UpdateStatus(context.Background(), user.ID, status, reason, until); err != nil {
+		return NewError(err, false)
+	}
+
+	details := "status changed to " + status
+	if until != nil {
+		details += " until " + until.UTC().Format(time.RFC3339)
+	}
+	if reason != "" {
+		details += ": " + reason
+	}
+
+	if err := u.event(user.ID, entity.EventStatusChanged, details); err != nil {
+		return err
+	}
+
+	if status == entity.StatusDisabled {
+		if err := u.repos.Token.DeleteByUser(context.Background(), user.ID); err != nil {
+			return NewError(err, false)
+		}
+	}
+
+	return nil
+}
+
+// passwordAge gets the shortest password age of user's roles
+// including age that applies to all users.
+// It returns zero if password doesn't expire.
+func (u *user) passwordAge(id uuid.UUID) (int, error) {
+	if len(u.params.PasswordAge) == 0 {
+		return 0, nil
+	}
+
+	age := u.params.PasswordAge[AnyRole]
+
+	roles, err := u.repos.Role.GetByUser(context.Background(), id)
+	if err != nil {
+		return 0, NewError(err, false)
+	}
+
+	for _, role := range roles {
+		if roleAge, ok := u.params.PasswordAge[role.Title]; ok && (age == 0 || roleAge < age) {
+			age = roleAge
+		}
+	}
+
+	return age, nil
+}
+
+// PasswordExpired reports whether user must change password
+// because it is flagged or older than password age of user's roles.
+// Passwords of users without local password never expire.
+func (u *user) PasswordExpired(id uuid.UUID) (bool, error) {
+	user, err := u.Get(id)
+	if err != nil {
+		return false, err
+	}
+
+	if len(user.Password) == 0 {
+		return false, nil
+	}
+
+	if user.MustChangePassword {
+		return true, nil
+	}
+
+	age, err := u.passwordAge(user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if age == 0 {
+		return false, nil
+	}
+
+	return user.PasswordChangedAt.AddDate(0, 0, age).Before(time.Now()), nil
+}
+
+// ExpirePassword flags user's password so that it must be changed on next login.
+func (u *user) ExpirePassword(id uuid.UUID) error {
+	user, err := u.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.repos.User.RequirePasswordChange(context.Background(), user.ID); err != nil {
+		return NewError(err, false)
+	}
+
 	return nil
 }
+
+// Strength estimates strength of password the same way registration does
+// and checks password against policy, user's name is blocked.
+// It returns password.Strength and names of failed rules.
+func (u *user) Strength(plain []byte, name string) (password.Strength, []string, error) {
+	rules, err := checkPassword(u.policy, u.hasher, plain, name)
+	if err != nil {
+		return password.Strength{}, nil, err
+	}
+
+	return u.policy.Estimate(plain, name), rules, nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/ldap"
+	"github.com/qsoulior/auth-server/pkg/password"
+	"github.com/qsoulior/auth-server/pkg/username"
+)
+
+// Verifier is interface implemented by types
+// that can verify user's credentials.
+type Verifier interface {
+	// Verify verifies user's name and password.
+	// It returns pointer to an entity.User instance
+	// if name and password are correct.
+	Verify(name string, password []byte) (*entity.User, error)
+}
+
+// localVerifier implements Verifier interface
+// using password hashes stored in user repository.
+type localVerifier struct {
+	userRepo repo.User
+	hasher   password.Hasher
+}
+
+// NewLocalVerifier creates a new verifier using password hashes compared by hasher.
+// It returns pointer to a localVerifier instance.
+func NewLocalVerifier(userRepo repo.User, hasher password.Hasher) *localVerifier {
+	return &localVerifier{userRepo, hasher}
+}
+
+// Verify gets a user by name regardless of case and compares user's password hash with password.
+// If hash was created with outdated algorithm, parameters or pepper,
+// password is rehashed with current ones.
+// It returns pointer to an entity.User instance or nil if an error occurred.
+func (v *localVerifier) Verify(name string, password []byte) (*entity.User, error) {
+	user, err := v.userRepo.GetByName(context.Background(), username.Fold(name))
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrUserNotExist, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	if err := verifyPassword(v.hasher, user.Password, password); err != nil {
+		return nil, err
+	}
+
+	if v.hasher.NeedsRehash(user.Password) {
+		hash, err := hashPassword(v.hasher, password)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := v.userRepo.UpdateHash(context.Background(), user.ID, hash); err != nil {
+			return nil, NewError(err, false)
+		}
+		user.Password = hash
+	}
+
+	return user, nil
+}
+
+// sourceVerifier implements Verifier interface
+// choosing verifier by user's source.
+type sourceVerifier struct {
+	userRepo repo.User
+	local    Verifier
+	external Verifier
+}
+
+// NewSourceVerifier creates a new verifier that verifies local users by local verifier
+// and other users, including not existing ones, by external verifier.
+// It returns pointer to a sourceVerifier instance.
+func NewSourceVerifier(userRepo repo.User, local Verifier, external Verifier) *sourceVerifier {
+	return &sourceVerifier{userRepo, local, external}
+}
+
+// Verify gets a user by name regardless of case and verifies name and password
+// by local verifier if user was created locally or by external verifier otherwise.
+// It returns pointer to an entity.User instance or nil if an error occurred.
+func (v *sourceVerifier) Verify(name string, password []byte) (*entity.User, error) {
+	user, err := v.userRepo.GetByName(context.Background(), username.Fold(name))
+	if err != nil && !errors.Is(err, repo.ErrNoRows) {
+		return nil, NewError(err, false)
+	}
+
+	if err == nil && user.Source == entity.SourceLocal {
+		return v.local.Verify(name, password)
+	}
+
+	return v.external.Verify(name, password)
+}
+
+// provisionUser gets a user by name regardless of case or creates a new one
+// without password for users authenticated by external identity provider.
+// Existing user is linked only if it was provisioned from the same source,
+// so that local accounts and accounts of other identity provider
+// cannot be taken over by identity provider.
+// It returns pointer to an entity.User instance.
+func provisionUser(userRepo repo.User, name string, source string) (*entity.User, error) {
+	user, err := userRepo.GetByName(context.Background(), username.Fold(name))
+	if err == nil {
+		if user.Source != source {
+			return nil, NewError(ErrUserNotLinked, true)
+		}
+		return user, nil
+	}
+
+	if !errors.Is(err, repo.ErrNoRows) {
+		return nil, NewError(err, false)
+	}
+
+	data := entity.User{Name: username.Normalize(name), NameKey: username.Fold(name), Password: []byte{}, Source: source}
+	user, err = userRepo.Create(context.Background(), data)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return user, nil
+}
+
+// LDAPRepos represents repositories the LDAP verifier interacts with.
+type LDAPRepos struct {
+	User repo.User
+	Role repo.Role
+}
+
+// LDAPParams represents parameters for LDAP verifier.
+type LDAPParams struct {
+	RoleMapping map[string]string
+}
+
+// ldapVerifier implements Verifier interface
+// using LDAP directory.
+type ldapVerifier struct {
+	repos         LDAPRepos
+	params        LDAPParams
+	authenticator ldap.Authenticator
+	names         username.Policy
+}
+
+// NewLDAPVerifier creates a new verifier using LDAP directory.
+// Names of provisioned users are checked against names policy.
+// It returns pointer to a ldapVerifier instance.
+func NewLDAPVerifier(repos LDAPRepos, params LDAPParams, authenticator ldap.Authenticator, names username.Policy) *ldapVerifier {
+	return &ldapVerifier{repos, params, authenticator, names}
+}
+
+// Verify binds to directory as user, gets or creates a local user
+// with directory's canonical name and synchronizes user's roles
+// using directory groups.
+// It returns pointer to an entity.User instance or nil if an error occurred.
+func (v *ldapVerifier) Verify(name string, password []byte) (*entity.User, error) {
+	entry, err := v.authenticator.Authenticate(name, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, ldap.ErrCredentialsInvalid):
+			return nil, NewError(ErrPasswordIncorrect, true)
+		case errors.Is(err, ldap.ErrUserNotFound):
+			return nil, NewError(ErrUserNotExist, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	if err := validateName(v.names, entry.Name); err != nil {
+		return nil, err
+	}
+
+	user, err := provisionUser(v.repos.User, entry.Name, entity.SourceLDAP)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncRoles(v.repos.Role, user.ID, v.params.RoleMapping, entry.Groups); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/qsoulior/auth-server/internal/entity"
+	"github.com/qsoulior/auth-server/internal/pkg/secret"
+	"github.com/qsoulior/auth-server/internal/repo"
+	"github.com/qsoulior/auth-server/pkg/uuid"
+	"github.com/qsoulior/auth-server/pkg/webauthn"
+)
+
+// WebAuthnRepos represents repositories the WebAuthn use case interacts with.
+type WebAuthnRepos struct {
+	User       repo.User
+	Credential repo.Credential
+	Ceremony   repo.Ceremony
+	Challenge  repo.Challenge
+}
+
+// WebAuthnParams represents parameters for WebAuthn use case.
+// CeremonyAge is number of seconds until ceremony expires.
+type WebAuthnParams struct {
+	CeremonyAge int
+}
+
+// Validate compares parameters with min and max values.
+// It returns error if at least one of parameters is invalid.
+func (p WebAuthnParams) Validate() error {
+	if p.CeremonyAge < 30 || p.CeremonyAge > 600 {
+		return ErrCeremonyAgeInvalid
+	}
+	return nil
+}
+
+// webAuthn implements WebAuthn interface.
+type webAuthn struct {
+	repos  WebAuthnRepos
+	params WebAuthnParams
+	rp     webauthn.RelyingParty
+	mfa    MFA
+}
+
+// NewWebAuthn validates parameters and creates a new WebAuthn use case.
+// Failed assertions completing MFA challenge are recorded by mfa.
+// It returns pointer to a webAuthn instance or nil if parameters are invalid.
+func NewWebAuthn(repos WebAuthnRepos, params WebAuthnParams, rp webauthn.RelyingParty, mfa MFA) (*webAuthn, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &webAuthn{repos, params, rp, mfa}, nil
+}
+
+// credentialIDs gets IDs of user's credentials.
+// It returns slice of credential IDs.
+func (w *webAuthn) credentialIDs(userID uuid.UUID) ([][]byte, error) {
+	credentials, err := w.repos.Credential.GetByUser(context.Background(), userID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	ids := make([][]byte, len(credentials))
+	for i, credential := range credentials {
+		ids[i] = credential.ID
+	}
+
+	return ids, nil
+}
+
+// begin deletes expired ceremonies and creates a new one with random challenge.
+// It returns pointer to an entity.Ceremony instance.
+func (w *webAuthn) begin(typ string, session bool, userID *uuid.UUID, challengeID *uuid.UUID) (*entity.Ceremony, error) {
+	if err := w.repos.Ceremony.DeleteExpired(context.Background()); err != nil {
+		return nil, NewError(err, false)
+	}
+
+	challenge, err := w.rp.Challenge()
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	data := entity.Ceremony{
+		Challenge:   challenge,
+		Type:        typ,
+		Session:     session,
+		ExpiresAt:   time.Now().Add(time.Duration(w.params.CeremonyAge) * time.Second),
+		UserID:      userID,
+		ChallengeID: challengeID,
+	}
+
+	ceremony, err := w.repos.Ceremony.Create(context.Background(), data)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return ceremony, nil
+}
+
+// consume deletes a ceremony by ID and checks its type and expiration.
+// It returns pointer to an entity.Ceremony instance.
+func (w *webAuthn) consume(id uuid.UUID, typ string) (*entity.Ceremony, error) {
+	ceremony, err := w.repos.Ceremony.ConsumeByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrCeremonyIncorrect, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	if ceremony.Type != typ {
+		return nil, NewError(ErrCeremonyIncorrect, true)
+	}
+
+	if ceremony.ExpiresAt.Before(time.Now()) {
+		return nil, NewError(ErrCeremonyExpired, true)
+	}
+
+	return ceremony, nil
+}
+
+// BeginRegistration creates registration ceremony for user.
+// Credentials already registered by user are excluded.
+// It returns pointer to an entity.Ceremony instance
+// and options that must be passed to authenticator.
+func (w *webAuthn) BeginRegistration(userID uuid.UUID) (*entity.Ceremony, *webauthn.CreationOptions, error) {
+	user, err := w.repos.User.GetByID(context.Background(), userID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, nil, NewError(ErrUserNotExist, true)
+		}
+		return nil, nil, NewError(err, false)
+	}
+
+	exclude, err := w.credentialIDs(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ceremony, err := w.begin(entity.CeremonyRegistration, false, &user.ID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := w.rp.CreationOptions(webauthn.User{ID: user.ID[:], Name: user.Name, DisplayName: user.Name}, ceremony.Challenge, exclude)
+	return ceremony, &options, nil
+}
+
+// FinishRegistration verifies registration response and stores a new credential with name.
+// It returns pointer to an entity.Credential instance.
+func (w *webAuthn) FinishRegistration(userID uuid.UUID, ceremonyID uuid.UUID, name string, response webauthn.RegistrationResponse) (*entity.Credential, error) {
+	if length := utf8.RuneCountInString(name); length < 1 || length > 64 {
+		return nil, NewError(ErrCredentialNameInvalid, true)
+	}
+
+	ceremony, err := w.consume(ceremonyID, entity.CeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	if ceremony.UserID == nil || *ceremony.UserID != userID {
+		return nil, NewError(ErrCeremonyIncorrect, true)
+	}
+
+	credential, err := w.rp.Register(ceremony.Challenge, response)
+	if err != nil {
+		return nil, NewError(ErrCredentialInvalid, true)
+	}
+
+	data := entity.Credential{
+		ID:        credential.ID,
+		PublicKey: credential.PublicKey,
+		SignCount: int64(credential.SignCount),
+		Name:      name,
+		CreatedAt: time.Now(),
+		UserID:    userID,
+	}
+
+	created, err := w.repos.Credential.Create(context.Background(), data)
+	if err != nil {
+		if errors.Is(err, repo.ErrExists) {
+			return nil, NewError(ErrCredentialExists, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	return created, nil
+}
+
+// ListCredentials gets credentials registered by user.
+// It returns slice of entity.Credential instances.
+func (w *webAuthn) ListCredentials(userID uuid.UUID) ([]entity.Credential, error) {
+	credentials, err := w.repos.Credential.GetByUser(context.Background(), userID)
+	if err != nil {
+		return nil, NewError(err, false)
+	}
+
+	return credentials, nil
+}
+
+// DeleteCredential deletes user's credential by ID.
+// It returns error if credential doesn't exist or belongs to another user.
+func (w *webAuthn) DeleteCredential(userID uuid.UUID, id []byte) error {
+	credential, err := w.repos.Credential.GetByID(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return NewError(ErrCredentialNotExist, true)
+		}
+		return NewError(err, false)
+	}
+
+	if credential.UserID != userID {
+		return NewError(ErrCredentialNotExist, true)
+	}
+
+	if err := w.repos.Credential.DeleteByID(context.Background(), id); err != nil {
+		return NewError(err, false)
+	}
+
+	return nil
+}
+
+// BeginLogin creates authentication ceremony.
+// If value of MFA challenge is set, ceremony completes the challenge
+// and only credentials of challenged user are allowed.
+// Otherwise, any discoverable credential can be used without password.
+// It returns pointer to an entity.Ceremony instance
+// and options that must be passed to authenticator.
+func (w *webAuthn) BeginLogin(value string, session bool) (*entity.Ceremony, *webauthn.RequestOptions, error) {
+	if value == "" {
+		ceremony, err := w.begin(entity.CeremonyAuthentication, session, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		options := w.rp.RequestOptions(ceremony.Challenge, nil, true)
+		return ceremony, &options, nil
+	}
+
+	challenge, err := w.repos.Challenge.GetByHash(context.Background(), secret.Hash(value))
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, nil, NewError(ErrChallengeIncorrect, true)
+		}
+		return nil, nil, NewError(err, false)
+	}
+
+	if challenge.ExpiresAt.Before(time.Now()) {
+		return nil, nil, NewError(ErrChallengeExpired, true)
+	}
+
+	allow, err := w.credentialIDs(challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(allow) == 0 {
+		return nil, nil, NewError(ErrCredentialNotExist, true)
+	}
+
+	ceremony, err := w.begin(entity.CeremonyAuthentication, challenge.Session, &challenge.UserID, &challenge.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := w.rp.RequestOptions(ceremony.Challenge, allow, false)
+	return ceremony, &options, nil
+}
+
+// FinishLogin verifies authentication response, updates sign counter of credential
+// and deletes MFA challenge if ceremony completes it.
+// User verification is required only if credential is used without password.
+// Invalid credential used to complete MFA challenge is recorded as failed attempt of challenge.
+// It returns pointer to an entity.Ceremony instance with authenticated user ID.
+func (w *webAuthn) FinishLogin(ceremonyID uuid.UUID, response webauthn.AuthenticationResponse) (*entity.Ceremony, error) {
+	ceremony, err := w.consume(ceremonyID, entity.CeremonyAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := w.login(ceremony, response)
+	if err != nil {
+		if ceremony.ChallengeID != nil && errors.Is(err, ErrCredentialInvalid) {
+			return nil, w.mfa.Fail(*ceremony.ChallengeID, *ceremony.UserID, err)
+		}
+		return nil, err
+	}
+
+	if ceremony.ChallengeID != nil {
+		if err := w.repos.Challenge.DeleteByID(context.Background(), *ceremony.ChallengeID); err != nil {
+			if errors.Is(err, repo.ErrNoRows) {
+				return nil, NewError(ErrChallengeIncorrect, true)
+			}
+			return nil, NewError(err, false)
+		}
+	}
+
+	ceremony.UserID = &credential.UserID
+	return ceremony, nil
+}
+
+// login verifies authentication response of ceremony and updates sign counter of credential.
+// It returns pointer to an entity.Credential instance used in response.
+func (w *webAuthn) login(ceremony *entity.Ceremony, response webauthn.AuthenticationResponse) (*entity.Credential, error) {
+	credential, err := w.repos.Credential.GetByID(context.Background(), response.RawID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrCredentialInvalid, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	if ceremony.UserID != nil && *ceremony.UserID != credential.UserID {
+		return nil, NewError(ErrCredentialInvalid, true)
+	}
+
+	if handle := response.Response.UserHandle; len(handle) != 0 && string(handle) != string(credential.UserID[:]) {
+		return nil, NewError(ErrCredentialInvalid, true)
+	}
+
+	stored := webauthn.Credential{ID: credential.ID, PublicKey: credential.PublicKey, SignCount: uint32(credential.SignCount)}
+	signCount, err := w.rp.Login(ceremony.Challenge, stored, response, ceremony.ChallengeID == nil)
+	if err != nil {
+		return nil, NewError(ErrCredentialInvalid, true)
+	}
+
+	if err := w.repos.Credential.UpdateSignCount(context.Background(), credential.ID, credential.SignCount, int64(signCount)); err != nil {
+		if errors.Is(err, repo.ErrNoRows) {
+			return nil, NewError(ErrCredentialInvalid, true)
+		}
+		return nil, NewError(err, false)
+	}
+
+	return credential, nil
+}
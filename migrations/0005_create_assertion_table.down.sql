@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.assertion;
\ No newline at end of file
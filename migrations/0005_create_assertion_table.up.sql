@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS auth.assertion (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    code BYTEA UNIQUE,
+    code_expires_at TIMESTAMP NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
\ No newline at end of file
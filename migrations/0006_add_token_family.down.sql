@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS auth.token_family_id_idx;
+ALTER TABLE auth.token DROP COLUMN IF EXISTS used_at, DROP COLUMN IF EXISTS family_id;
\ No newline at end of file
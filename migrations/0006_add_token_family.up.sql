@@ -0,0 +1,4 @@
+ALTER TABLE auth.token ADD COLUMN IF NOT EXISTS family_id UUID;
+UPDATE auth.token SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE auth.token ALTER COLUMN family_id SET NOT NULL, ADD COLUMN IF NOT EXISTS used_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS token_family_id_idx ON auth.token(family_id);
\ No newline at end of file
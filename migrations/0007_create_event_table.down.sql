@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.event;
\ No newline at end of file
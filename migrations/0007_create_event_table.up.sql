@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS auth.event (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type TEXT NOT NULL,
+    details TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.token DROP COLUMN IF EXISTS replaced_by;
\ No newline at end of file
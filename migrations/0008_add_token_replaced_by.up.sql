@@ -0,0 +1 @@
+ALTER TABLE auth.token ADD COLUMN IF NOT EXISTS replaced_by UUID;
\ No newline at end of file
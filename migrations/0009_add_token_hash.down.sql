@@ -0,0 +1 @@
+ALTER TABLE auth.token DROP CONSTRAINT IF EXISTS token_hash_key, DROP COLUMN IF EXISTS successor, DROP COLUMN IF EXISTS hash;
\ No newline at end of file
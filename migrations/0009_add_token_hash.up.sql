@@ -0,0 +1,3 @@
+ALTER TABLE auth.token ADD COLUMN IF NOT EXISTS hash BYTEA, ADD COLUMN IF NOT EXISTS successor BYTEA;
+UPDATE auth.token SET hash = sha256(convert_to(id::text, 'UTF8')) WHERE hash IS NULL;
+ALTER TABLE auth.token ALTER COLUMN hash SET NOT NULL, ADD CONSTRAINT token_hash_key UNIQUE (hash);
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM auth.role WHERE title = 'admin';
\ No newline at end of file
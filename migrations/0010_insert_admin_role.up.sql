@@ -0,0 +1 @@
+INSERT INTO auth.role(title, description) VALUES ('admin', 'Administrator') ON CONFLICT DO NOTHING;
\ No newline at end of file
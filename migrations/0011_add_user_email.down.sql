@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS auth.user_email_key;
+ALTER TABLE auth.user DROP COLUMN IF EXISTS email_verified, DROP COLUMN IF EXISTS email;
\ No newline at end of file
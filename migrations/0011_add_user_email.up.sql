@@ -0,0 +1,2 @@
+ALTER TABLE auth.user ADD COLUMN IF NOT EXISTS email VARCHAR(254) NOT NULL DEFAULT '', ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE UNIQUE INDEX IF NOT EXISTS user_email_key ON auth.user(email) WHERE email <> '';
\ No newline at end of file
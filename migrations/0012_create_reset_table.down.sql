@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.reset;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth.reset (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.totp;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth.totp (
+    user_id UUID PRIMARY KEY REFERENCES auth.user(id) ON DELETE CASCADE,
+    secret BYTEA NOT NULL,
+    confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    last_counter BIGINT NOT NULL DEFAULT 0
+);
\ No newline at end of file
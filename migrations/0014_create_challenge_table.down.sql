@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.challenge;
\ No newline at end of file
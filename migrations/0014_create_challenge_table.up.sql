@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS auth.challenge (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    session BOOLEAN NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.credential;
\ No newline at end of file
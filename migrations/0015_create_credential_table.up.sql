@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS auth.credential (
+    id BYTEA PRIMARY KEY,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS credential_user_id_idx ON auth.credential(user_id);
\ No newline at end of file
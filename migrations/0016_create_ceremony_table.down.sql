@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.ceremony;
\ No newline at end of file
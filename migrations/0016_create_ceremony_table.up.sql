@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS auth.ceremony (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    challenge BYTEA NOT NULL,
+    type TEXT NOT NULL,
+    session BOOLEAN NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE,
+    challenge_id UUID REFERENCES auth.challenge(id) ON DELETE CASCADE
+);
\ No newline at end of file
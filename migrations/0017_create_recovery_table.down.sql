@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.recovery;
\ No newline at end of file
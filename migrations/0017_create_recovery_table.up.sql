@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth.recovery (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash BYTEA NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS recovery_user_id_idx ON auth.recovery(user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.attempt;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth.attempt (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.history;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS auth.history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_user_id_idx ON auth.history(user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.user DROP COLUMN IF EXISTS must_change_password, DROP COLUMN IF EXISTS password_changed_at;
\ No newline at end of file
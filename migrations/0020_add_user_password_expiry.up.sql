@@ -0,0 +1 @@
+ALTER TABLE auth.user ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP NOT NULL DEFAULT now(), ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS auth.user_name_key_key;
+DROP TABLE IF EXISTS auth.user_name_conflict;
+ALTER TABLE auth.user DROP COLUMN IF EXISTS name_key, ALTER COLUMN name TYPE VARCHAR(20);
\ No newline at end of file
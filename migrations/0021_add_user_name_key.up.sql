@@ -0,0 +1,11 @@
+ALTER TABLE auth.user ALTER COLUMN name TYPE VARCHAR(64), ADD COLUMN IF NOT EXISTS name_key TEXT;
+UPDATE auth.user SET name_key = translate(name, 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz') WHERE name_key IS NULL AND name ~ '^[\x01-\x7F]*$';
+UPDATE auth.user SET name_key = normalize(replace(replace(lower(normalize(name, NFKC)), 'ß', 'ss'), 'ς', 'σ'), NFKC) WHERE name_key IS NULL;
+CREATE TABLE IF NOT EXISTS auth.user_name_conflict (
+    user_id UUID PRIMARY KEY REFERENCES auth.user(id) ON DELETE CASCADE,
+    name_key TEXT NOT NULL
+);
+INSERT INTO auth.user_name_conflict(user_id, name_key) SELECT id, name_key FROM (SELECT id, name_key, row_number() OVER (PARTITION BY name_key ORDER BY name COLLATE "C", id) AS rank FROM auth.user) AS ranked WHERE rank > 1 ON CONFLICT DO NOTHING;
+UPDATE auth.user AS u SET name_key = c.name_key || '#' || u.id FROM auth.user_name_conflict AS c WHERE c.user_id = u.id AND u.name_key = c.name_key;
+ALTER TABLE auth.user ALTER COLUMN name_key SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS user_name_key_key ON auth.user(name_key);
\ No newline at end of file
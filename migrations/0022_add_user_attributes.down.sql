@@ -0,0 +1 @@
+ALTER TABLE auth.user DROP COLUMN IF EXISTS attributes;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.user ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.user DROP COLUMN IF EXISTS suspended_until, DROP COLUMN IF EXISTS status_reason, DROP COLUMN IF EXISTS status;
\ No newline at end of file
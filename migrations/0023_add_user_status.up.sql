@@ -0,0 +1 @@
+ALTER TABLE auth.user ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active', ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '', ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP;
\ No newline at end of file
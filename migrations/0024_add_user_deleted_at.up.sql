@@ -0,0 +1 @@
+ALTER TABLE auth.user ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
\ No newline at end of file
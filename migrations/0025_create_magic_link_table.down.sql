@@ -0,0 +1 @@
+DROP TABLE IF EXISTS auth.magic_link;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS auth.magic_link (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash BYTEA UNIQUE NOT NULL,
+    fingerprint BYTEA NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    user_id UUID REFERENCES auth.user(id) ON DELETE CASCADE NOT NULL
+);
\ No newline at end of file
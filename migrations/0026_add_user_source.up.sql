@@ -0,0 +1,2 @@
+ALTER TABLE auth.user ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'local';
+UPDATE auth.user SET source = 'external' WHERE password = ''::bytea;
\ No newline at end of file
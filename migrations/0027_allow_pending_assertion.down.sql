@@ -0,0 +1,2 @@
+DELETE FROM auth.assertion WHERE user_id IS NULL OR code_expires_at IS NULL;
+ALTER TABLE auth.assertion ALTER COLUMN code_expires_at SET NOT NULL, ALTER COLUMN user_id SET NOT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.assertion ALTER COLUMN code_expires_at DROP NOT NULL, ALTER COLUMN user_id DROP NOT NULL;
\ No newline at end of file
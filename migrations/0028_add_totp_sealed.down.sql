@@ -0,0 +1 @@
+ALTER TABLE auth.totp DROP COLUMN IF EXISTS sealed;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.totp ADD COLUMN IF NOT EXISTS sealed BOOLEAN NOT NULL DEFAULT FALSE;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM auth.event WHERE user_id NOT IN (SELECT id FROM auth.user);
+ALTER TABLE auth.event ADD CONSTRAINT event_user_id_fkey FOREIGN KEY (user_id) REFERENCES auth.user(id) ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE auth.event DROP CONSTRAINT IF EXISTS event_user_id_fkey;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE auth.user AS u SET email = '' WHERE NOT u.email_verified AND EXISTS (SELECT 1 FROM auth.user AS o WHERE o.email = u.email AND o.id <> u.id AND (o.email_verified OR o.id < u.id));
+DROP INDEX IF EXISTS auth.user_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS user_email_key ON auth.user(email) WHERE email <> '';
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS auth.user_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS user_email_key ON auth.user(email) WHERE email_verified;
\ No newline at end of file
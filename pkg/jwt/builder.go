@@ -8,9 +8,13 @@ import (
 
 // Builder is interface implemented by types that can build JWT.
 type Builder interface {
-	// Build creates new Claims, JWT string and signing it.
+	// Build creates new Claims with roles and attributes, JWT string and signing it.
 	// It returns JWT string or empty string if signing failed.
-	Build(subject string, age time.Duration, fingerprint string, roles []string) (string, error)
+	Build(subject string, age time.Duration, fingerprint string, roles []string, attributes map[string]any) (string, error)
+
+	// BuildScoped creates new Claims restricted to scope without roles, JWT string and signing it.
+	// It returns JWT string or empty string if signing failed.
+	BuildScoped(subject string, age time.Duration, fingerprint string, scope string) (string, error)
 }
 
 // builder implements Builder interface.
@@ -30,12 +34,26 @@ func NewBuilder(params Params) (*builder, error) {
 	return &builder{params, method}, nil
 }
 
-// Build creates new Claims, JWT string and signing it.
+// Build creates new Claims with roles and attributes, JWT string and signing it.
+// It returns JWT string or empty string if signing failed.
+func (b *builder) Build(subject string, age time.Duration, fingerprint string, roles []string, attributes map[string]any) (string, error) {
+	return b.build(subject, age, fingerprint, roles, "", attributes)
+}
+
+// BuildScoped creates new Claims restricted to scope without roles, JWT string and signing it.
+// It returns JWT string or empty string if signing failed.
+func (b *builder) BuildScoped(subject string, age time.Duration, fingerprint string, scope string) (string, error) {
+	return b.build(subject, age, fingerprint, nil, scope, nil)
+}
+
+// build creates new Claims, JWT string and signing it.
 // It returns JWT string or empty string if signing failed.
-func (b *builder) Build(subject string, age time.Duration, fingerprint string, roles []string) (string, error) {
+func (b *builder) build(subject string, age time.Duration, fingerprint string, roles []string, scope string, attributes map[string]any) (string, error) {
 	claims := &Claims{
 		fingerprint,
 		roles,
+		scope,
+		attributes,
 		jwt.RegisteredClaims{
 			Issuer:    b.params.Issuer,
 			Subject:   subject,
@@ -3,6 +3,7 @@ package jwt
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -37,9 +38,71 @@ func TestNewBuilder(t *testing.T) {
 func Test_builder_Build(t *testing.T) {
 	b := &builder{Params{"", "HS256", []byte("secret")}, jwt.SigningMethodHS256}
 
-	_, err := b.Build("", 10, "", nil)
+	_, err := b.Build("", 10, "", nil, nil)
 	if err != nil {
 		t.Errorf("builder.Build() error = %v, wantErr %v", err, false)
 		return
 	}
 }
+
+func Test_builder_Build_attributes(t *testing.T) {
+	params := Params{"auth", "HS256", []byte("secret")}
+	b := &builder{params, jwt.SigningMethodHS256}
+	p := &parser{params, jwt.SigningMethodHS256}
+
+	tests := []struct {
+		name       string
+		attributes map[string]any
+	}{
+		{"Empty", nil},
+		{"Attributes", map[string]any{"department": "sales", "level": float64(3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := b.Build("subject", time.Minute, "", []string{"user"}, tt.attributes)
+			if err != nil {
+				t.Fatalf("builder.Build() error = %v", err)
+			}
+
+			claims, err := p.Parse(token)
+			if err != nil {
+				t.Fatalf("parser.Parse() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(claims.Attributes, tt.attributes) {
+				t.Errorf("builder.Build() attributes = %v, want %v", claims.Attributes, tt.attributes)
+			}
+		})
+	}
+}
+
+func Test_builder_BuildScoped(t *testing.T) {
+	params := Params{"auth", "HS256", []byte("secret")}
+	b := &builder{params, jwt.SigningMethodHS256}
+	p := &parser{params, jwt.SigningMethodHS256}
+
+	tests := []struct {
+		name  string
+		scope string
+	}{
+		{"Unscoped", ""},
+		{"Scoped", "password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := b.BuildScoped("subject", time.Minute, "", tt.scope)
+			if err != nil {
+				t.Fatalf("builder.BuildScoped() error = %v", err)
+			}
+
+			claims, err := p.Parse(token)
+			if err != nil {
+				t.Fatalf("parser.Parse() error = %v", err)
+			}
+
+			if claims.Scope != tt.scope || claims.Roles != nil {
+				t.Errorf("builder.BuildScoped() scope = %v, roles = %v, want %v, nil", claims.Scope, claims.Roles, tt.scope)
+			}
+		})
+	}
+}
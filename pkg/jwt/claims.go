@@ -5,9 +5,13 @@ package jwt
 import "github.com/golang-jwt/jwt/v5"
 
 // Claims represents custom claims.
+// Scope is empty if token isn't restricted to specific action.
+// Attributes are custom user attributes exposed in token.
 // The jwt.RegisteredClaims embedded in it.
 type Claims struct {
-	Fingerprint string   `json:"fingerprint"`
-	Roles       []string `json:"roles"`
+	Fingerprint string         `json:"fingerprint"`
+	Roles       []string       `json:"roles"`
+	Scope       string         `json:"scope,omitempty"`
+	Attributes  map[string]any `json:"attributes,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,181 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// defaultTimeout is used if Params.Timeout isn't set.
+const defaultTimeout = 10 * time.Second
+
+var (
+	ErrCredentialsInvalid = errors.New("credentials are invalid")
+	ErrUserNotFound       = errors.New("user is not found")
+)
+
+// Entry represents authenticated directory user.
+type Entry struct {
+	DN     string
+	Name   string
+	Groups []string
+}
+
+// Authenticator is interface implemented by types
+// that can authenticate users in directory.
+type Authenticator interface {
+	// Authenticate binds as user with name and password
+	// and looks up user's groups.
+	// It returns pointer to an Entry instance or nil if authentication failed.
+	Authenticate(name string, password []byte) (*Entry, error)
+}
+
+// authenticator implements Authenticator interface.
+type authenticator struct {
+	params Params
+}
+
+// NewAuthenticator validates params and creates a new authenticator.
+// It returns pointer to an authenticator instance or nil if params are invalid.
+func NewAuthenticator(params Params) (*authenticator, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	if params.Timeout <= 0 {
+		params.Timeout = defaultTimeout
+	}
+
+	return &authenticator{params}, nil
+}
+
+// dial connects to directory and upgrades connection to TLS if required.
+// It returns pointer to a ldap.Conn instance.
+func (a *authenticator) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(a.params.URL, ldap.DialWithDialer(&net.Dialer{Timeout: a.params.Timeout}))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(a.params.Timeout)
+
+	if a.params.StartTLS {
+		u, err := url.Parse(a.params.URL)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		if err := conn.StartTLS(&tls.Config{ServerName: u.Hostname()}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// search performs a search request with attributes to return.
+// It returns slice of found entries or nil if base object doesn't exist.
+func (a *authenticator) search(conn *ldap.Conn, base string, scope int, filter string, attrs []string) ([]*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(base, scope, ldap.NeverDerefAliases, 0, int(a.params.Timeout.Seconds()), false, filter, attrs, nil)
+	result, err := conn.Search(request)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+// userDN gets DN of user by name using template or service account search.
+// It returns DN string or ErrUserNotFound if user doesn't exist or isn't unique.
+func (a *authenticator) userDN(conn *ldap.Conn, name string) (string, error) {
+	if a.params.BindDN == "" {
+		return fmt.Sprintf(a.params.UserDN, ldap.EscapeDN(name)), nil
+	}
+
+	if err := conn.Bind(a.params.BindDN, a.params.BindPassword); err != nil {
+		return "", err
+	}
+
+	filter := fmt.Sprintf(a.params.UserFilter, ldap.EscapeFilter(name))
+	entries, err := a.search(conn, a.params.UserBase, ldap.ScopeWholeSubtree, filter, []string{"1.1"})
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) != 1 {
+		return "", ErrUserNotFound
+	}
+
+	return entries[0].DN, nil
+}
+
+// Authenticate binds as user with name and password
+// and looks up user's canonical name and groups.
+// Empty password is rejected to prevent unauthenticated bind.
+// It returns pointer to an Entry instance or nil if authentication failed.
+func (a *authenticator) Authenticate(name string, password []byte) (*Entry, error) {
+	if name == "" || len(password) == 0 {
+		return nil, ErrCredentialsInvalid
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	dn, err := a.userDN(conn, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(dn, string(password)); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrCredentialsInvalid
+		}
+		return nil, err
+	}
+
+	entry := &Entry{DN: dn, Name: name}
+
+	if a.params.NameAttr != "" {
+		entries, err := a.search(conn, dn, ldap.ScopeBaseObject, "(objectClass=*)", []string{a.params.NameAttr})
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) == 1 {
+			if value := entries[0].GetAttributeValue(a.params.NameAttr); value != "" {
+				entry.Name = value
+			}
+		}
+	}
+
+	if a.params.GroupBase != "" {
+		// user may not be allowed to read groups, so service account is bound again
+		if a.params.BindDN != "" {
+			if err := conn.Bind(a.params.BindDN, a.params.BindPassword); err != nil {
+				return nil, err
+			}
+		}
+
+		filter := fmt.Sprintf(a.params.GroupFilter, ldap.EscapeFilter(dn))
+		entries, err := a.search(conn, a.params.GroupBase, ldap.ScopeWholeSubtree, filter, []string{a.params.GroupAttr})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range entries {
+			entry.Groups = append(entry.Groups, group.GetAttributeValues(a.params.GroupAttr)...)
+		}
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,283 @@
+package ldap
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// directoryEntry represents entry stored in test directory.
+type directoryEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+// directory is embedded LDAP server supporting simple bind
+// and search with equality, presence and "and" filters.
+type directory struct {
+	listener net.Listener
+	entries  map[string]directoryEntry
+}
+
+func newDirectory(t *testing.T) *directory {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &directory{listener, map[string]directoryEntry{
+		"cn=service,dc=example,dc=org": {"service", nil},
+		"uid=alice,ou=people,dc=example,dc=org": {"secret", map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"alice"},
+		}},
+		"uid=bob,ou=people,dc=example,dc=org": {"hunter2", map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"bob"},
+		}},
+		"cn=admins,ou=groups,dc=example,dc=org": {"", map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"admins"},
+			"member":      {"uid=alice,ou=people,dc=example,dc=org"},
+		}},
+		"cn=developers,ou=groups,dc=example,dc=org": {"", map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"developers"},
+			"member":      {"uid=alice,ou=people,dc=example,dc=org", "uid=bob,ou=people,dc=example,dc=org"},
+		}},
+	}}
+
+	go d.serve()
+	t.Cleanup(func() { listener.Close() })
+	return d
+}
+
+// URL returns ldap URL of directory.
+func (d *directory) URL() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *directory) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *directory) handle(conn net.Conn) {
+	defer conn.Close()
+	bound := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := int64(ldap.LDAPResultInvalidCredentials)
+			if entry, ok := d.entries[dn]; ok && entry.password != "" && entry.password == password {
+				code, bound = ldap.LDAPResultSuccess, dn
+			}
+			conn.Write(d.result(id, ldap.ApplicationBindResponse, code).Bytes())
+		case ldap.ApplicationSearchRequest:
+			if bound == "" {
+				conn.Write(d.result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights).Bytes())
+				continue
+			}
+			base := strings.ToLower(op.Children[0].Data.String())
+			scope := op.Children[1].Value.(int64)
+			found := false
+			for dn, entry := range d.entries {
+				lower := strings.ToLower(dn)
+				if lower == base {
+					found = true
+				}
+				if scope == ldap.ScopeBaseObject && lower != base || !strings.HasSuffix(lower, base) {
+					continue
+				}
+				if entry.attributes != nil && d.match(op.Children[6], entry.attributes) {
+					conn.Write(d.entry(id, dn, entry.attributes).Bytes())
+				}
+			}
+			code := int64(ldap.LDAPResultSuccess)
+			if !found && scope == ldap.ScopeBaseObject {
+				code = ldap.LDAPResultNoSuchObject
+			}
+			conn.Write(d.result(id, ldap.ApplicationSearchResultDone, code).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (d *directory) match(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !d.match(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterEqualityMatch:
+		name, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		for _, v := range attributes[name] {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+	case ldap.FilterPresent:
+		return filter.Data.String() == "objectClass" || len(attributes[filter.Data.String()]) > 0
+	}
+	return false
+}
+
+func (d *directory) message(id int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func (d *directory) result(id int64, tag ber.Tag, code int64) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return d.message(id, op)
+}
+
+func (d *directory) entry(id int64, dn string, attributes map[string][]string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "objectName"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range attributes {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, value := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		attr.AppendChild(vals)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+	return d.message(id, op)
+}
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"DirectBind", Params{URL: "ldap://localhost", UserDN: "uid=%s,dc=org"}, false},
+		{"SearchBind", Params{URL: "ldap://localhost", BindDN: "cn=service", UserBase: "dc=org", UserFilter: "(uid=%s)"}, false},
+		{"EmptyURL", Params{UserDN: "uid=%s,dc=org"}, true},
+		{"InvalidUserDN", Params{URL: "ldap://localhost", UserDN: "uid=alice,dc=org"}, true},
+		{"EmptyUserBase", Params{URL: "ldap://localhost", BindDN: "cn=service", UserFilter: "(uid=%s)"}, true},
+		{"InvalidGroupFilter", Params{URL: "ldap://localhost", UserDN: "uid=%s,dc=org", GroupBase: "dc=org", GroupFilter: "(member=x)", GroupAttr: "cn"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_authenticator_Authenticate(t *testing.T) {
+	d := newDirectory(t)
+
+	direct := Params{
+		URL:         d.URL(),
+		UserDN:      "uid=%s,ou=people,dc=example,dc=org",
+		NameAttr:    "uid",
+		GroupBase:   "ou=groups,dc=example,dc=org",
+		GroupFilter: "(member=%s)",
+		GroupAttr:   "cn",
+	}
+	search := Params{
+		URL:          d.URL(),
+		BindDN:       "cn=service,dc=example,dc=org",
+		BindPassword: "service",
+		UserBase:     "ou=people,dc=example,dc=org",
+		UserFilter:   "(&(objectClass=person)(uid=%s))",
+		NameAttr:     "uid",
+		GroupBase:    "ou=groups,dc=example,dc=org",
+		GroupFilter:  "(member=%s)",
+		GroupAttr:    "cn",
+	}
+	serviceInvalid := search
+	serviceInvalid.BindPassword = "wrong"
+
+	type args struct {
+		name     string
+		password []byte
+	}
+	tests := []struct {
+		name    string
+		params  Params
+		args    args
+		want    *Entry
+		wantErr error
+	}{
+		{"DirectBind", direct, args{"alice", []byte("secret")}, &Entry{"uid=alice,ou=people,dc=example,dc=org", "alice", []string{"admins", "developers"}}, nil},
+		{"DirectBindWrongPassword", direct, args{"alice", []byte("wrong")}, nil, ErrCredentialsInvalid},
+		{"DirectBindEmptyPassword", direct, args{"alice", []byte{}}, nil, ErrCredentialsInvalid},
+		{"SearchBind", search, args{"bob", []byte("hunter2")}, &Entry{"uid=bob,ou=people,dc=example,dc=org", "bob", []string{"developers"}}, nil},
+		{"SearchBindCaseInsensitive", search, args{"BOB", []byte("hunter2")}, &Entry{"uid=bob,ou=people,dc=example,dc=org", "bob", []string{"developers"}}, nil},
+		{"SearchBindWrongPassword", search, args{"bob", []byte("secret")}, nil, ErrCredentialsInvalid},
+		{"SearchBindUnknownUser", search, args{"carol", []byte("secret")}, nil, ErrUserNotFound},
+		{"SearchBindFilterInjection", search, args{"*", []byte("secret")}, nil, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAuthenticator(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := a.Authenticate(tt.args.name, tt.args.password)
+			if err != tt.wantErr {
+				t.Errorf("authenticator.Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				sortStrings(got.Groups)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("authenticator.Authenticate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("ServiceBindFailed", func(t *testing.T) {
+		a, _ := NewAuthenticator(serviceInvalid)
+		if _, err := a.Authenticate("bob", []byte("hunter2")); err == nil {
+			t.Errorf("authenticator.Authenticate() error = %v, wantErr %v", err, true)
+		}
+	})
+}
+
+func sortStrings(s []string) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j] < s[j-1]; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package ldap provides structures to authenticate users
+// against LDAP directory or Active Directory.
+package ldap
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrParamsInvalid = errors.New("params are invalid")
+
+// Params represents params of directory connection, user lookup and group lookup.
+//
+// User is bound directly using UserDN template if BindDN is empty.
+// Otherwise, service account is bound with BindDN and BindPassword,
+// user is searched in UserBase using UserFilter template and then bound with found DN.
+// Groups are looked up only if GroupBase is set.
+type Params struct {
+	URL          string
+	StartTLS     bool
+	Timeout      time.Duration
+	BindDN       string
+	BindPassword string
+	UserDN       string
+	UserBase     string
+	UserFilter   string
+	NameAttr     string
+	GroupBase    string
+	GroupFilter  string
+	GroupAttr    string
+}
+
+// Validate checks that parameters required by chosen bind mode are set
+// and templates contain exactly one placeholder.
+// It returns error if at least one of parameters is invalid.
+func (p Params) Validate() error {
+	if p.URL == "" {
+		return ErrParamsInvalid
+	}
+
+	if p.BindDN == "" {
+		if strings.Count(p.UserDN, "%s") != 1 {
+			return ErrParamsInvalid
+		}
+	} else if p.UserBase == "" || strings.Count(p.UserFilter, "%s") != 1 {
+		return ErrParamsInvalid
+	}
+
+	if p.GroupBase != "" && (strings.Count(p.GroupFilter, "%s") != 1 || p.GroupAttr == "") {
+		return ErrParamsInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package mail provides structures to send email messages
+// over SMTP or write them to io.Writer.
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+var (
+	ErrParamsInvalid  = errors.New("params are invalid")
+	ErrMessageInvalid = errors.New("message is invalid")
+)
+
+// Message represents plain text email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is interface implemented by types
+// that can send email messages.
+type Mailer interface {
+	// Send sends message.
+	// It returns error if message is invalid or sending failed.
+	Send(msg Message) error
+}
+
+// build validates message and formats it with headers.
+// Header values must not contain line breaks to prevent header injection.
+// It returns message bytes with CRLF line endings.
+func (m Message) build(from string, date time.Time) ([]byte, error) {
+	if m.To == "" || strings.ContainsAny(m.To+m.Subject+from, "\r\n") {
+		return nil, ErrMessageInvalid
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+
+	return b.Bytes(), nil
+}
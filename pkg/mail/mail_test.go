@@ -0,0 +1,158 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage_build(t *testing.T) {
+	date := time.Date(2023, 7, 22, 16, 35, 36, 0, time.UTC)
+	tests := []struct {
+		name    string
+		msg     Message
+		want    string
+		wantErr bool
+	}{
+		{
+			"Valid",
+			Message{"user@example.org", "Verify email", "Hello\nWorld"},
+			"From: auth@example.org\r\nTo: user@example.org\r\nSubject: Verify email\r\nDate: Sat, 22 Jul 2023 16:35:36 +0000\r\n" +
+				"MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\nHello\r\nWorld\r\n",
+			false,
+		},
+		{
+			"EncodedSubject",
+			Message{"user@example.org", "Подтверждение", ""},
+			"Subject: =?utf-8?q?",
+			false,
+		},
+		{"EmptyRecipient", Message{"", "Verify email", ""}, "", true},
+		{"InjectedRecipient", Message{"user@example.org\r\nBcc: other@example.org", "Verify email", ""}, "", true},
+		{"InjectedSubject", Message{"user@example.org", "Verify\nBcc: other@example.org", ""}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.msg.build("auth@example.org", date)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Message.build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("Message.build() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_writerMailer_Send(t *testing.T) {
+	var b bytes.Buffer
+	m := NewWriter(&b, "auth@example.org")
+
+	if err := m.Send(Message{"user@example.org", "Verify email", "https://example.org/verify"}); err != nil {
+		t.Fatalf("writerMailer.Send() error = %v", err)
+	}
+
+	if got := b.String(); !strings.Contains(got, "To: user@example.org\r\n") || !strings.HasSuffix(got, "https://example.org/verify\r\n\r\n") {
+		t.Errorf("writerMailer.Send() wrote %q", got)
+	}
+}
+
+// smtpServer is minimal SMTP server that accepts one message.
+func smtpServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("220 localhost ESMTP\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				conn.Write([]byte("250 localhost\r\n"))
+			case strings.HasPrefix(cmd, "DATA"):
+				conn.Write([]byte("354 end with .\r\n"))
+				var b strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					b.WriteString(line)
+				}
+				data <- b.String()
+				conn.Write([]byte("250 accepted\r\n"))
+			case strings.HasPrefix(cmd, "QUIT"):
+				conn.Write([]byte("221 bye\r\n"))
+				return
+			default:
+				conn.Write([]byte("250 ok\r\n"))
+			}
+		}
+	}()
+
+	return listener.Addr().String(), data
+}
+
+func Test_smtpMailer_Send(t *testing.T) {
+	addr, data := smtpServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewSMTP(SMTPParams{Host: host, Port: portNum, From: "auth@example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Send(Message{"user@example.org", "Verify email", ".hidden line"}); err != nil {
+		t.Fatalf("smtpMailer.Send() error = %v", err)
+	}
+
+	got := <-data
+	if !strings.Contains(got, "To: user@example.org\r\n") || !strings.Contains(got, "\r\n..hidden line\r\n") {
+		t.Errorf("smtpMailer.Send() sent %q", got)
+	}
+}
+
+func TestSMTPParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  SMTPParams
+		wantErr bool
+	}{
+		{"Valid", SMTPParams{Host: "localhost", Port: 25, From: "auth@example.org"}, false},
+		{"EmptyHost", SMTPParams{Port: 25, From: "auth@example.org"}, true},
+		{"InvalidPort", SMTPParams{Host: "localhost", Port: 0, From: "auth@example.org"}, true},
+		{"EmptyFrom", SMTPParams{Host: "localhost", Port: 25}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("SMTPParams.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
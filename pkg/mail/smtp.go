@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPParams represents params of SMTP server connection.
+// Authentication is used only if Username is set.
+type SMTPParams struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Validate checks that host, port and sender are set.
+// It returns error if at least one of parameters is invalid.
+func (p SMTPParams) Validate() error {
+	if p.Host == "" || p.Port < 1 || p.Port > 65535 || p.From == "" {
+		return ErrParamsInvalid
+	}
+	return nil
+}
+
+// smtpMailer implements Mailer interface.
+// It represents mailer that sends messages over SMTP.
+type smtpMailer struct {
+	params SMTPParams
+}
+
+// NewSMTP validates params and creates a new smtpMailer.
+// It returns pointer to a smtpMailer instance or nil if params are invalid.
+func NewSMTP(params SMTPParams) (*smtpMailer, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &smtpMailer{params}, nil
+}
+
+// Send sends message over SMTP.
+// Connection is upgraded to TLS if server supports STARTTLS.
+// It returns error if message is invalid or sending failed.
+func (s *smtpMailer) Send(msg Message) error {
+	data, err := msg.build(s.params.From, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.params.Username != "" {
+		auth = smtp.PlainAuth("", s.params.Username, s.params.Password, s.params.Host)
+	}
+
+	addr := net.JoinHostPort(s.params.Host, strconv.Itoa(s.params.Port))
+	return smtp.SendMail(addr, auth, s.params.From, []string{msg.To}, data)
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// writerMailer implements Mailer interface.
+// It represents mailer that writes messages to io.Writer
+// and is intended for development and tests.
+type writerMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewWriter creates a new writerMailer.
+// It returns pointer to a writerMailer instance.
+func NewWriter(w io.Writer, from string) *writerMailer {
+	return &writerMailer{w: w, from: from}
+}
+
+// Send writes message followed by empty line to writer.
+// It returns error if message is invalid or writing failed.
+func (m *writerMailer) Send(msg Message) error {
+	data, err := msg.build(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.w.Write(append(data, "\r\n"...)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package password
+
+// commonPasswords contains frequently used passwords ordered by popularity,
+// rank of password is its index plus one.
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789",
+	"12345", "1234", "111111", "1234567", "dragon",
+	"123123", "baseball", "abc123", "football", "monkey",
+	"letmein", "696969", "shadow", "master", "666666",
+	"qwertyuiop", "123321", "mustang", "1234567890", "michael",
+	"654321", "superman", "1qaz2wsx", "7777777", "121212",
+	"000000", "qazwsx", "123qwe", "killer", "trustno1",
+	"jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew",
+	"tigger", "sunshine", "iloveyou", "2000", "charlie",
+	"robert", "thomas", "hockey", "ranger", "daniel",
+	"starwars", "klaster", "112233", "george", "computer",
+	"michelle", "jessica", "pepper", "1111", "zxcvbn",
+	"555555", "11111111", "131313", "freedom", "777777",
+	"pass", "maggie", "159753", "aaaaaa", "ginger",
+	"princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme",
+	"matthew", "access", "yankees", "987654321", "dallas",
+	"austin", "thunder", "taylor", "matrix", "welcome",
+	"admin", "login", "secret", "hello", "flower",
+	"passw0rd", "whatever", "qwerty123", "password1", "1q2w3e4r",
+	"monkey1", "dragon1", "football1", "baseball1", "p@ssw0rd",
+	"qwe123", "asdf", "asdfghjkl", "q1w2e3r4", "zaq12wsx",
+	"lovely", "sparky", "welcome1", "admin123", "root",
+	"toor", "changeme", "default", "guest", "test",
+	"test123", "user", "letmein1", "nintendo", "samsung",
+	"apple", "google", "internet", "soccer1", "hannah",
+	"jordan23", "liverpool", "arsenal", "chocolate", "butterfly",
+	"purple", "angel", "orange", "banana", "cookie",
+	"pokemon", "naruto", "minecraft", "blink182", "corvette",
+	"ferrari", "porsche", "mercedes", "winter", "spring",
+	"autumn", "august", "october", "november", "december",
+	"january", "february", "monday", "friday", "sunday",
+}
+
+// keyboardRows contains rows of QWERTY keyboard without and with shift,
+// each row is shifted by half key to the right relative to the previous one.
+var keyboardRows = [][2]string{
+	{"`1234567890-=", "~!@#$%^&*()_+"},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{"asdfghjkl;'", "ASDFGHJKL:\""},
+	{"zxcvbnm,./", "ZXCVBNM<>?"},
+}
@@ -0,0 +1,276 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// argon2Version is version of argon2 algorithm stored in PHC string.
+	argon2Version = "19"
+
+	// argon2SaltSize is number of random bytes in argon2id salt.
+	argon2SaltSize = 16
+
+	// argon2KeySize is number of bytes in argon2id hash.
+	argon2KeySize = 32
+
+	// bcryptMaxLength is number of password bytes bcrypt algorithm uses.
+	bcryptMaxLength = 72
+
+	// maxLength is max number of password bytes if algorithm has no limit.
+	maxLength = 1024
+)
+
+var ErrMismatch = errors.New("hash and password mismatch")
+
+// bcryptEncoding is base64 encoding used by bcrypt algorithm.
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+// Hasher is interface implemented by types
+// that can hash passwords and verify them against hashes.
+type Hasher interface {
+	// Hash hashes password using current algorithm and parameters.
+	// It returns hash in PHC string format.
+	Hash(password []byte) ([]byte, error)
+
+	// Compare compares hash created with any supported algorithm and parameters with password.
+	// It returns ErrMismatch if password is incorrect.
+	Compare(hash []byte, password []byte) error
+
+	// NeedsRehash checks whether hash was created with another algorithm,
+	// parameters or pepper than current ones.
+	NeedsRehash(hash []byte) bool
+
+	// MaxLength returns max number of password bytes current algorithm uses.
+	MaxLength() int
+}
+
+// hasher implements Hasher interface.
+type hasher struct {
+	params Params
+}
+
+// NewHasher validates params and creates a new hasher.
+// It returns pointer to a hasher instance or nil if params are invalid.
+func NewHasher(params Params) (*hasher, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &hasher{params}, nil
+}
+
+// pepper mixes server-side secret into password using HMAC-SHA256.
+// It returns password unchanged if pepper is disabled.
+func (h *hasher) pepper(password []byte, peppered bool) []byte {
+	if !peppered {
+		return password
+	}
+
+	mac := hmac.New(sha256.New, h.params.Pepper)
+	mac.Write(password)
+
+	// bcrypt stops at zero byte in some implementations, so digest is encoded
+	return []byte(encoding.EncodeToString(mac.Sum(nil)))
+}
+
+// Hash hashes password using current algorithm and parameters.
+// It returns hash in PHC string format.
+func (h *hasher) Hash(password []byte) ([]byte, error) {
+	peppered := len(h.params.Pepper) > 0
+	input := h.pepper(password, peppered)
+
+	switch h.params.Algorithm {
+	case AlgBcrypt:
+		hash, err := bcrypt.GenerateFromPassword(input, h.params.Cost)
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := parseBcrypt(hash)
+		if err != nil {
+			return nil, err
+		}
+		if peppered {
+			p.Params["pepper"] = "1"
+		}
+
+		return []byte(p.String("c", "pepper")), nil
+	case AlgArgon2id:
+		salt := make([]byte, argon2SaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+
+		p := &phc{ID: AlgArgon2id, Version: argon2Version, Params: make(map[string]string)}
+		if peppered {
+			p.Params["pepper"] = "1"
+		}
+		p.Params["m"] = strconv.FormatUint(uint64(h.params.Memory), 10)
+		p.Params["t"] = strconv.FormatUint(uint64(h.params.Time), 10)
+		p.Params["p"] = strconv.FormatUint(uint64(h.params.Threads), 10)
+		p.Salt = salt
+		p.Hash = argon2.IDKey(input, salt, h.params.Time, h.params.Memory, h.params.Threads, argon2KeySize)
+
+		return []byte(p.String("m", "t", "p", "pepper")), nil
+	}
+
+	return nil, ErrParamsInvalid
+}
+
+// Compare compares hash created with any supported algorithm and parameters with password.
+// Native bcrypt hashes created before PHC format was used are also supported.
+// It returns ErrMismatch if password is incorrect
+// or ErrHashInvalid if hash can't be parsed or requires missing pepper.
+func (h *hasher) Compare(hash []byte, password []byte) error {
+	if isBcrypt(hash) {
+		if err := bcrypt.CompareHashAndPassword(hash, password); err != nil {
+			return ErrMismatch
+		}
+		return nil
+	}
+
+	p, err := parsePHC(string(hash))
+	if err != nil {
+		return err
+	}
+
+	peppered := p.Params["pepper"] == "1"
+	if peppered && len(h.params.Pepper) == 0 {
+		return ErrHashInvalid
+	}
+	input := h.pepper(password, peppered)
+
+	switch p.ID {
+	case AlgBcrypt:
+		cost, err := p.uintParam("c", 8)
+		if err != nil {
+			return err
+		}
+
+		native := fmt.Sprintf("$%s$%02d$%s%s", p.Version, cost, bcryptEncoding.EncodeToString(p.Salt), bcryptEncoding.EncodeToString(p.Hash))
+		if err := bcrypt.CompareHashAndPassword([]byte(native), input); err != nil {
+			return ErrMismatch
+		}
+		return nil
+	case AlgArgon2id:
+		if p.Version != argon2Version || len(p.Salt) == 0 || len(p.Hash) == 0 {
+			return ErrHashInvalid
+		}
+
+		memory, err := p.uintParam("m", 32)
+		if err != nil {
+			return err
+		}
+		time, err := p.uintParam("t", 32)
+		if err != nil {
+			return err
+		}
+		threads, err := p.uintParam("p", 8)
+		if err != nil {
+			return err
+		}
+		if memory < 8*threads || time < 1 || threads < 1 {
+			return ErrHashInvalid
+		}
+
+		key := argon2.IDKey(input, p.Salt, uint32(time), uint32(memory), uint8(threads), uint32(len(p.Hash)))
+		if subtle.ConstantTimeCompare(key, p.Hash) != 1 {
+			return ErrMismatch
+		}
+		return nil
+	}
+
+	return ErrHashInvalid
+}
+
+// NeedsRehash checks whether hash was created with another algorithm,
+// parameters or pepper than current ones.
+// It returns true for native bcrypt hashes and hashes that can't be parsed.
+func (h *hasher) NeedsRehash(hash []byte) bool {
+	if isBcrypt(hash) {
+		return true
+	}
+
+	p, err := parsePHC(string(hash))
+	if err != nil || p.ID != h.params.Algorithm {
+		return true
+	}
+
+	if (p.Params["pepper"] == "1") != (len(h.params.Pepper) > 0) {
+		return true
+	}
+
+	switch p.ID {
+	case AlgBcrypt:
+		cost, err := p.uintParam("c", 8)
+		return err != nil || int(cost) != h.params.Cost
+	case AlgArgon2id:
+		memory, mErr := p.uintParam("m", 32)
+		time, tErr := p.uintParam("t", 32)
+		threads, pErr := p.uintParam("p", 8)
+		if mErr != nil || tErr != nil || pErr != nil {
+			return true
+		}
+		return uint32(memory) != h.params.Memory || uint32(time) != h.params.Time ||
+			uint8(threads) != h.params.Threads || len(p.Hash) != argon2KeySize
+	}
+
+	return true
+}
+
+// MaxLength returns max number of password bytes current algorithm uses.
+// Bcrypt ignores bytes after 72nd unless password is peppered.
+func (h *hasher) MaxLength() int {
+	if h.params.Algorithm == AlgBcrypt && len(h.params.Pepper) == 0 {
+		return bcryptMaxLength
+	}
+	return maxLength
+}
+
+// isBcrypt checks whether hash is in native bcrypt format, e.g. $2a$10$...
+func isBcrypt(hash []byte) bool {
+	return len(hash) > 3 && hash[0] == '$' && hash[1] == '2'
+}
+
+// parseBcrypt converts native bcrypt hash to PHC fields.
+// It returns pointer to a phc instance.
+func parseBcrypt(hash []byte) (*phc, error) {
+	fields := strings.Split(string(hash), "$")
+	if len(fields) != 4 || len(fields[3]) != 53 {
+		return nil, ErrHashInvalid
+	}
+
+	cost, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, ErrHashInvalid
+	}
+
+	salt, err := bcryptEncoding.DecodeString(fields[3][:22])
+	if err != nil {
+		return nil, ErrHashInvalid
+	}
+
+	key, err := bcryptEncoding.DecodeString(fields[3][22:])
+	if err != nil {
+		return nil, ErrHashInvalid
+	}
+
+	return &phc{
+		ID:      AlgBcrypt,
+		Version: fields[1],
+		Params:  map[string]string{"c": strconv.Itoa(cost)},
+		Salt:    salt,
+		Hash:    key,
+	}, nil
+}
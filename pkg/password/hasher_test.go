@@ -0,0 +1,205 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	testPepper      = bytes.Repeat([]byte("p"), 32)
+	testBcrypt      = Params{Algorithm: AlgBcrypt, Cost: bcrypt.MinCost}
+	testArgon2id    = Params{Algorithm: AlgArgon2id, Memory: 64, Time: 1, Threads: 1}
+	testBcryptPep   = Params{Algorithm: AlgBcrypt, Cost: bcrypt.MinCost, Pepper: testPepper}
+	testArgon2idPep = Params{Algorithm: AlgArgon2id, Memory: 64, Time: 1, Threads: 1, Pepper: testPepper}
+)
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"Bcrypt", testBcrypt, false},
+		{"Argon2id", testArgon2id, false},
+		{"Peppered", testArgon2idPep, false},
+		{"UnknownAlgorithm", Params{Algorithm: "md5"}, true},
+		{"LowBcryptCost", Params{Algorithm: AlgBcrypt, Cost: 3}, true},
+		{"HighBcryptCost", Params{Algorithm: AlgBcrypt, Cost: 32}, true},
+		{"ZeroTime", Params{Algorithm: AlgArgon2id, Memory: 64, Time: 0, Threads: 1}, true},
+		{"ZeroThreads", Params{Algorithm: AlgArgon2id, Memory: 64, Time: 1, Threads: 0}, true},
+		{"LowMemory", Params{Algorithm: AlgArgon2id, Memory: 15, Time: 1, Threads: 2}, true},
+		{"ShortPepper", Params{Algorithm: AlgBcrypt, Cost: bcrypt.MinCost, Pepper: []byte("short")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_hasher_Compare(t *testing.T) {
+	password := []byte("Test123$")
+	long := bytes.Repeat([]byte("a"), 100)
+
+	tests := []struct {
+		name     string
+		params   Params
+		prefix   string
+		password []byte
+		compare  []byte
+		wantErr  error
+	}{
+		{"Bcrypt", testBcrypt, "$bcrypt$v=2a$c=4$", password, password, nil},
+		{"Argon2id", testArgon2id, "$argon2id$v=19$m=64,t=1,p=1$", password, password, nil},
+		{"BcryptPeppered", testBcryptPep, "$bcrypt$v=2a$c=4,pepper=1$", password, password, nil},
+		{"Argon2idPeppered", testArgon2idPep, "$argon2id$v=19$m=64,t=1,p=1,pepper=1$", password, password, nil},
+		{"BcryptIncorrect", testBcrypt, "$bcrypt$", password, []byte("Test123%"), ErrMismatch},
+		{"Argon2idIncorrect", testArgon2id, "$argon2id$", password, []byte("Test123%"), ErrMismatch},
+		{"Argon2idLong", testArgon2id, "$argon2id$", long, append(long[:99:99], 'b'), ErrMismatch},
+		{"BcryptPepperedLong", testBcryptPep, "$bcrypt$", long, append(long[:99:99], 'b'), ErrMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hash, err := h.Hash(tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(string(hash), tt.prefix) {
+				t.Errorf("hasher.Hash() = %s, want prefix %s", hash, tt.prefix)
+			}
+
+			if err := h.Compare(hash, tt.compare); !errors.Is(err, tt.wantErr) {
+				t.Errorf("hasher.Compare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_hasher_Compare_legacy(t *testing.T) {
+	password := []byte("Test123$")
+	native, err := bcrypt.GenerateFromPassword(password, bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peppered, err := NewHasher(testArgon2idPep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pepperedHash, err := peppered.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		params   Params
+		hash     []byte
+		password []byte
+		wantErr  error
+	}{
+		{"NativeBcrypt", testArgon2id, native, password, nil},
+		{"NativeBcryptIncorrect", testArgon2id, native, []byte("Test123%"), ErrMismatch},
+		{"PepperMissing", testArgon2id, pepperedHash, password, ErrHashInvalid},
+		{"UnknownID", testArgon2id, []byte("$md5$c2FsdA$aGFzaA"), password, ErrHashInvalid},
+		{"InvalidFormat", testArgon2id, []byte("plain"), password, ErrHashInvalid},
+		{"MissingParams", testArgon2id, []byte("$argon2id$v=19$c2FsdA$aGFzaA"), password, ErrHashInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := h.Compare(tt.hash, tt.password); !errors.Is(err, tt.wantErr) {
+				t.Errorf("hasher.Compare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_hasher_NeedsRehash(t *testing.T) {
+	native, err := bcrypt.GenerateFromPassword([]byte("Test123$"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := func(params Params) []byte {
+		h, err := NewHasher(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := h.Hash([]byte("Test123$"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	tests := []struct {
+		name   string
+		params Params
+		hash   []byte
+		want   bool
+	}{
+		{"SameArgon2id", testArgon2id, hash(testArgon2id), false},
+		{"SameBcrypt", testBcrypt, hash(testBcrypt), false},
+		{"SamePeppered", testArgon2idPep, hash(testArgon2idPep), false},
+		{"NativeBcrypt", testBcrypt, native, true},
+		{"OtherAlgorithm", testArgon2id, hash(testBcrypt), true},
+		{"OtherCost", Params{Algorithm: AlgBcrypt, Cost: 5}, hash(testBcrypt), true},
+		{"OtherMemory", Params{Algorithm: AlgArgon2id, Memory: 128, Time: 1, Threads: 1}, hash(testArgon2id), true},
+		{"PepperAdded", testArgon2idPep, hash(testArgon2id), true},
+		{"PepperRemoved", testArgon2id, hash(testArgon2idPep), true},
+		{"Invalid", testArgon2id, []byte("plain"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := h.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("hasher.NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hasher_MaxLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		want   int
+	}{
+		{"Bcrypt", testBcrypt, bcryptMaxLength},
+		{"BcryptPeppered", testBcryptPep, maxLength},
+		{"Argon2id", testArgon2id, maxLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHasher(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := h.MaxLength(); got != tt.want {
+				t.Errorf("hasher.MaxLength() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
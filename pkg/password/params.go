@@ -0,0 +1,54 @@
+// Package password provides structures to hash and verify passwords
+// and to store hashes in PHC string format.
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgBcrypt   = "bcrypt"
+	AlgArgon2id = "argon2id"
+)
+
+var ErrParamsInvalid = errors.New("params are invalid")
+
+// Params represents params of password hashing.
+// Cost is used by bcrypt algorithm,
+// Memory (KiB), Time and Threads are used by argon2id algorithm.
+// Pepper is optional server-side secret mixed into every password.
+type Params struct {
+	Algorithm string
+	Cost      int
+	Memory    uint32
+	Time      uint32
+	Threads   uint8
+	Pepper    []byte
+}
+
+// Validate checks that algorithm is supported, its parameters are in allowed ranges
+// and pepper is empty or at least 32 bytes long.
+// It returns error if at least one of parameters is invalid.
+func (p Params) Validate() error {
+	switch p.Algorithm {
+	case AlgBcrypt:
+		if p.Cost < bcrypt.MinCost || p.Cost > bcrypt.MaxCost {
+			return ErrParamsInvalid
+		}
+	case AlgArgon2id:
+		if p.Time < 1 || p.Threads < 1 {
+			return ErrParamsInvalid
+		}
+		if p.Memory < 8*uint32(p.Threads) || p.Memory > 4*1024*1024 {
+			return ErrParamsInvalid
+		}
+	default:
+		return ErrParamsInvalid
+	}
+	if len(p.Pepper) > 0 && len(p.Pepper) < 32 {
+		return ErrParamsInvalid
+	}
+	return nil
+}
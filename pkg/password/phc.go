@@ -0,0 +1,109 @@
+package password
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrHashInvalid = errors.New("hash is invalid")
+
+// phc represents hash in PHC string format:
+// $<id>[$v=<version>][$<param>=<value>(,<param>=<value>)*][$<salt>[$<hash>]].
+type phc struct {
+	ID      string
+	Version string
+	Params  map[string]string
+	Salt    []byte
+	Hash    []byte
+}
+
+// encoding is base64 encoding without padding used by PHC strings.
+var encoding = base64.RawStdEncoding
+
+// String encodes PHC fields.
+// It returns PHC string with params in order of keys.
+func (p *phc) String(keys ...string) string {
+	var b strings.Builder
+	b.WriteString("$" + p.ID)
+
+	if p.Version != "" {
+		b.WriteString("$v=" + p.Version)
+	}
+
+	params := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := p.Params[key]; ok {
+			params = append(params, key+"="+value)
+		}
+	}
+	if len(params) > 0 {
+		b.WriteString("$" + strings.Join(params, ","))
+	}
+
+	if p.Salt != nil {
+		b.WriteString("$" + encoding.EncodeToString(p.Salt))
+		if p.Hash != nil {
+			b.WriteString("$" + encoding.EncodeToString(p.Hash))
+		}
+	}
+
+	return b.String()
+}
+
+// parsePHC parses hash in PHC string format.
+// It returns pointer to a phc instance or ErrHashInvalid if format is invalid.
+func parsePHC(hash string) (*phc, error) {
+	fields := strings.Split(hash, "$")
+	if len(fields) < 2 || fields[0] != "" || fields[1] == "" {
+		return nil, ErrHashInvalid
+	}
+
+	p := &phc{ID: fields[1], Params: make(map[string]string)}
+	fields = fields[2:]
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") {
+		p.Version = fields[0][2:]
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && strings.Contains(fields[0], "=") {
+		for _, param := range strings.Split(fields[0], ",") {
+			key, value, ok := strings.Cut(param, "=")
+			if !ok || key == "" {
+				return nil, ErrHashInvalid
+			}
+			p.Params[key] = value
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) > 2 {
+		return nil, ErrHashInvalid
+	}
+
+	var err error
+	if len(fields) > 0 {
+		if p.Salt, err = encoding.DecodeString(fields[0]); err != nil {
+			return nil, ErrHashInvalid
+		}
+	}
+	if len(fields) > 1 {
+		if p.Hash, err = encoding.DecodeString(fields[1]); err != nil {
+			return nil, ErrHashInvalid
+		}
+	}
+
+	return p, nil
+}
+
+// uintParam parses unsigned integer param of PHC string.
+// It returns ErrHashInvalid if param is missing or isn't a number.
+func (p *phc) uintParam(key string, bitSize int) (uint64, error) {
+	value, err := strconv.ParseUint(p.Params[key], 10, bitSize)
+	if err != nil {
+		return 0, ErrHashInvalid
+	}
+	return value, nil
+}
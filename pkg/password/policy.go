@@ -0,0 +1,245 @@
+package password
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	RuleMinLength  = "min_length"
+	RuleMaxLength  = "max_length"
+	RuleCharacters = "characters"
+	RuleLower      = "lower"
+	RuleUpper      = "upper"
+	RuleDigit      = "digit"
+	RuleSpecial    = "special"
+	RuleEntropy    = "entropy"
+	RuleBlocked    = "blocked"
+	RuleBreached   = "breached"
+)
+
+// minWordLength is min number of characters in blocked word,
+// shorter words match too many passwords.
+const minWordLength = 3
+
+// poolSizes maps character classes to number of characters
+// used to estimate password entropy.
+// Characters that belong to no class, e.g. caseless letters, count as other.
+var poolSizes = map[string]float64{
+	RuleLower:   26,
+	RuleUpper:   26,
+	RuleDigit:   10,
+	RuleSpecial: 33,
+	"other":     100,
+}
+
+// PolicyParams represents params of password policy.
+// MinLength and MaxLength are numbers of characters,
+// Classes are rules of required character classes: lower, upper, digit and special.
+// MinEntropy is min number of bits estimated by Estimate, it isn't checked if zero.
+// Blocklist contains words password mustn't contain regardless of case.
+// BreachCount is min number of breaches password is rejected after.
+type PolicyParams struct {
+	MinLength   int
+	MaxLength   int
+	Classes     []string
+	MinEntropy  float64
+	Blocklist   []string
+	BreachCount int
+}
+
+// Validate checks that lengths are in range [1,1024], classes are known,
+// entropy is in range [0,256] and breach count isn't negative.
+// It returns error if at least one of parameters is invalid.
+func (p PolicyParams) Validate() error {
+	if p.MinLength < 1 || p.MaxLength < p.MinLength || p.MaxLength > maxLength {
+		return ErrParamsInvalid
+	}
+	for _, class := range p.Classes {
+		if _, ok := poolSizes[class]; !ok || class == "other" {
+			return ErrParamsInvalid
+		}
+	}
+	if p.MinEntropy < 0 || p.MinEntropy > 256 {
+		return ErrParamsInvalid
+	}
+	if p.BreachCount < 0 {
+		return ErrParamsInvalid
+	}
+	return nil
+}
+
+// Corpus is interface implemented by types
+// that can count occurrences of password in breaches.
+type Corpus interface {
+	// Count gets number of times password was seen in breaches.
+	Count(password []byte) (int, error)
+}
+
+// Policy is interface implemented by types
+// that can check passwords against set of rules.
+type Policy interface {
+	// Check checks password against rules.
+	// Words are blocked in addition to blocklist, e.g. user's name.
+	// It returns names of failed rules or nil if password satisfies policy.
+	Check(password []byte, words ...string) ([]string, error)
+
+	// Estimate estimates strength of password the same way entropy rule does.
+	// Words and blocklist are guessed before common passwords.
+	// It returns Strength with score and feedback.
+	Estimate(password []byte, words ...string) Strength
+}
+
+// policy implements Policy interface.
+type policy struct {
+	params    PolicyParams
+	blocklist []string
+	corpus    Corpus
+}
+
+// NewPolicy validates params and creates a new policy.
+// Breached passwords are checked only if corpus isn't nil,
+// breach count must be positive in this case.
+// It returns pointer to a policy instance or nil if params are invalid.
+func NewPolicy(params PolicyParams, corpus Corpus) (*policy, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if corpus != nil && params.BreachCount < 1 {
+		return nil, ErrParamsInvalid
+	}
+
+	blocklist := make([]string, 0, len(params.Blocklist))
+	for _, word := range params.Blocklist {
+		if word = foldWord(word); word != "" {
+			blocklist = append(blocklist, word)
+		}
+	}
+
+	return &policy{params, blocklist, corpus}, nil
+}
+
+// Check checks normalized password against rules.
+// Length is counted in characters, so non-ASCII characters are accepted
+// and counted by their Unicode categories.
+// It returns names of failed rules or nil if password satisfies policy.
+func (p *policy) Check(password []byte, words ...string) ([]string, error) {
+	var rules []string
+
+	password = Normalize(password)
+	if !utf8.Valid(password) {
+		return []string{RuleCharacters}, nil
+	}
+
+	length := utf8.RuneCount(password)
+	if length < p.params.MinLength {
+		rules = append(rules, RuleMinLength)
+	}
+	if length > p.params.MaxLength {
+		rules = append(rules, RuleMaxLength)
+	}
+
+	classes := make(map[string]bool)
+	for _, r := range string(password) {
+		class := classOf(r)
+		if class == "" {
+			rules = append(rules, RuleCharacters)
+			break
+		}
+		classes[class] = true
+	}
+
+	for _, class := range p.params.Classes {
+		if !classes[class] {
+			rules = append(rules, class)
+		}
+	}
+
+	if p.params.MinEntropy > 0 && p.Estimate(password, words...).Entropy < p.params.MinEntropy {
+		rules = append(rules, RuleEntropy)
+	}
+
+	if p.blocked(password, words) {
+		rules = append(rules, RuleBlocked)
+	}
+
+	if p.corpus != nil {
+		count, err := p.corpus.Count(password)
+		if err != nil {
+			return nil, err
+		}
+		if count >= p.params.BreachCount {
+			rules = append(rules, RuleBreached)
+		}
+	}
+
+	return rules, nil
+}
+
+// Estimate estimates strength of password the same way entropy rule does.
+// Words and blocklist are guessed before common passwords.
+// It returns Strength with score and feedback.
+func (p *policy) Estimate(password []byte, words ...string) Strength {
+	all := make([]string, 0, len(words)+len(p.blocklist))
+	all = append(all, words...)
+	all = append(all, p.blocklist...)
+	return Estimate(password, all...)
+}
+
+// blocked checks whether password contains word from blocklist or words.
+func (p *policy) blocked(password []byte, words []string) bool {
+	folded := foldWord(string(password))
+	for _, word := range p.blocklist {
+		if strings.Contains(folded, word) {
+			return true
+		}
+	}
+	for _, word := range words {
+		if word = foldWord(word); word != "" && strings.Contains(folded, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize converts password to Unicode NFC form,
+// so that the same password typed on different platforms is equal.
+// It returns password unchanged if it's ASCII or invalid UTF-8.
+func Normalize(password []byte) []byte {
+	if !utf8.Valid(password) {
+		return password
+	}
+	return norm.NFC.Bytes(password)
+}
+
+// classOf gets character class of rune.
+// It returns empty string if rune isn't allowed in password.
+func classOf(r rune) string {
+	switch {
+	case r == utf8.RuneError || !unicode.IsPrint(r):
+		return ""
+	case unicode.IsLower(r):
+		return RuleLower
+	case unicode.IsUpper(r) || unicode.IsTitle(r):
+		return RuleUpper
+	case unicode.IsDigit(r):
+		return RuleDigit
+	case unicode.IsLetter(r) || unicode.IsMark(r):
+		return "other"
+	default:
+		return RuleSpecial
+	}
+}
+
+// foldWord converts word to lower case for case-insensitive matching.
+// It returns empty string if word is shorter than min length.
+func foldWord(word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if utf8.RuneCountInString(word) < minWordLength {
+		return ""
+	}
+	return word
+}
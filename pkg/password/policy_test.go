@@ -0,0 +1,177 @@
+package password
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testClasses = []string{RuleLower, RuleUpper, RuleDigit, RuleSpecial}
+
+func TestPolicyParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  PolicyParams
+		wantErr bool
+	}{
+		{"ValidParams", PolicyParams{8, 128, testClasses, 40, []string{"qwerty"}, 0}, false},
+		{"NoClasses", PolicyParams{8, 128, nil, 0, nil, 0}, false},
+		{"ZeroMinLength", PolicyParams{0, 128, nil, 0, nil, 0}, true},
+		{"MaxLessThanMin", PolicyParams{8, 7, nil, 0, nil, 0}, true},
+		{"LargeMaxLength", PolicyParams{8, 1025, nil, 0, nil, 0}, true},
+		{"UnknownClass", PolicyParams{8, 128, []string{"emoji"}, 0, nil, 0}, true},
+		{"OtherClass", PolicyParams{8, 128, []string{"other"}, 0, nil, 0}, true},
+		{"NegativeEntropy", PolicyParams{8, 128, nil, -1, nil, 0}, true},
+		{"LargeEntropy", PolicyParams{8, 128, nil, 257, nil, 0}, true},
+		{"NegativeBreachCount", PolicyParams{8, 128, nil, 0, nil, -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("PolicyParams.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_policy_Check(t *testing.T) {
+	p, err := NewPolicy(PolicyParams{8, 16, testClasses, 0, []string{"qwerty", "ab"}, 0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		words    []string
+		want     []string
+	}{
+		{"Valid", "Test123$", nil, nil},
+		{"ValidNonASCII", "Пароль123$", nil, nil},
+		{"CaselessLetters", "Test123$密码", nil, nil},
+		{"Space", "Test 123", nil, nil},
+		{"Short", "Te1$", nil, []string{RuleMinLength}},
+		{"Long", "Test123$Test123$T", nil, []string{RuleMaxLength}},
+		{"NonASCIILength", "Тест123$", nil, nil},
+		{"NoClasses", "testtest", nil, []string{RuleUpper, RuleDigit, RuleSpecial}},
+		{"Control", "Test123$\t", nil, []string{RuleCharacters}},
+		{"InvalidUTF8", "Test123$\xff", nil, []string{RuleCharacters}},
+		{"Blocked", "QWERTY1$a", nil, []string{RuleBlocked}},
+		{"ShortWordIgnored", "Tabc123$", nil, nil},
+		{"UserName", "Alice123$", []string{"alice"}, []string{RuleBlocked}},
+		{"ShortUserNameIgnored", "Al123456$a", []string{"al"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := p.Check([]byte(tt.password), tt.words...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policy.Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_policy_Check_entropy(t *testing.T) {
+	p, err := NewPolicy(PolicyParams{1, 128, nil, 50, nil, 0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"Strong", "correct horse battery staple", nil},
+		{"Mixed", "Tr0ub4dor&3", nil},
+		{"Repeated", "aaaaaaaaaaaaaaaaaaaa", []string{RuleEntropy}},
+		{"Digits", "12345678", []string{RuleEntropy}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := p.Check([]byte(tt.password)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policy.Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// corpus implements Corpus interface using map of passwords to counts.
+type corpus map[string]int
+
+func (c corpus) Count(password []byte) (int, error) {
+	if string(password) == "error" {
+		return 0, errors.New("read failed")
+	}
+	return c[string(password)], nil
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  PolicyParams
+		corpus  Corpus
+		wantErr bool
+	}{
+		{"WithoutCorpus", PolicyParams{8, 128, nil, 0, nil, 0}, nil, false},
+		{"WithCorpus", PolicyParams{8, 128, nil, 0, nil, 1}, corpus{}, false},
+		{"ZeroBreachCount", PolicyParams{8, 128, nil, 0, nil, 0}, corpus{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy(tt.params, tt.corpus); (err != nil) != tt.wantErr {
+				t.Errorf("NewPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_policy_Check_breached(t *testing.T) {
+	p, err := NewPolicy(PolicyParams{1, 128, nil, 0, nil, 3}, corpus{"Password1$": 100, "Rare123$": 2, "Caf\u00e9123$": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+		wantErr  bool
+	}{
+		{"Breached", "Password1$", []string{RuleBreached}, false},
+		{"BelowCount", "Rare123$", nil, false},
+		{"NotFound", "Unique123$", nil, false},
+		{"Normalized", "Cafe\u0301123$", []string{RuleBreached}, false},
+		{"CorpusError", "error", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Check([]byte(tt.password))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("policy.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policy.Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{"ASCII", "Test123$", "Test123$"},
+		{"Decomposed", "Cafe\u0301", "Caf\u00e9"},
+		{"Composed", "Caf\u00e9", "Caf\u00e9"},
+		{"InvalidUTF8", "Test\xff", "Test\xff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Normalize([]byte(tt.password))); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
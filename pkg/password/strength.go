@@ -0,0 +1,554 @@
+package password
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxEstimateLength is max number of characters searched for patterns,
+	// the rest of password is estimated as random characters.
+	maxEstimateLength = 100
+
+	// minPatternLength is min number of characters in sequence, repeat or keyboard pattern.
+	minPatternLength = 3
+
+	// guessRate is number of guesses per second of offline attack against slow hash.
+	guessRate = 1e4
+)
+
+const (
+	PatternDictionary = "dictionary"
+	PatternKeyboard   = "keyboard"
+	PatternRepeat     = "repeat"
+	PatternSequence   = "sequence"
+	PatternRandom     = "random"
+)
+
+// scoreGuesses contains numbers of guesses password needs to exceed
+// to get score equal to index plus one.
+var scoreGuesses = []float64{1e3, 1e6, 1e8, 1e10}
+
+// keyboardStarts is number of keys keyboard pattern can start with,
+// keyboardDegree is average number of neighbors of key.
+var (
+	keyboardStarts float64
+	keyboardDegree float64
+)
+
+// keyPosition represents row and column of key.
+type keyPosition struct {
+	row, col int
+}
+
+// keyboard maps characters to positions of their keys.
+var keyboard = make(map[rune]keyPosition)
+
+// dictionary maps common passwords to their ranks.
+var dictionary = make(map[string]int, len(commonPasswords))
+
+func init() {
+	for row, keys := range keyboardRows {
+		for _, layer := range keys {
+			for col, r := range []rune(layer) {
+				keyboard[r] = keyPosition{row, col}
+			}
+		}
+		keyboardStarts += float64(utf8.RuneCountInString(keys[0]))
+	}
+
+	var neighbors float64
+	for r, pos := range keyboard {
+		if unicode.IsUpper(r) || strings.ContainsRune(keyboardRows[pos.row][1], r) {
+			continue
+		}
+		for _, other := range keyboardRows {
+			for _, o := range other[0] {
+				if keyDirection(pos, keyboard[o]) != (keyPosition{}) {
+					neighbors++
+				}
+			}
+		}
+	}
+	keyboardDegree = neighbors / keyboardStarts
+
+	for i, word := range commonPasswords {
+		if _, ok := dictionary[word]; !ok {
+			dictionary[word] = i + 1
+		}
+	}
+}
+
+// Strength represents estimated strength of password.
+// Guesses is estimated number of guesses needed to crack password,
+// Entropy is its binary logarithm, Score is in range [0,4].
+// CrackTime is estimated number of seconds of offline attack against slow hash.
+// Patterns are names of patterns found in password.
+// Warning and Suggestions are empty if password is strong.
+type Strength struct {
+	Guesses     float64
+	Entropy     float64
+	Score       int
+	CrackTime   float64
+	Patterns    []string
+	Warning     string
+	Suggestions []string
+}
+
+// match represents part of password found by one of matchers.
+// Runes from i to j inclusive are matched.
+type match struct {
+	pattern string
+	i, j    int
+	guesses float64
+	token   string
+	rank    int
+	user    bool
+	turns   int
+	base    string
+}
+
+// Estimate estimates number of guesses needed to crack normalized password
+// by searching for common passwords, words, keyboard patterns, repeats and sequences,
+// the rest is estimated as random characters of used classes.
+// The cheapest combination of patterns is used like zxcvbn does.
+// It returns Strength with score and feedback.
+func Estimate(password []byte, words ...string) Strength {
+	runes := []rune(string(Normalize(password)))
+	if len(runes) == 0 {
+		return feedback(Strength{Guesses: 1}, nil)
+	}
+
+	pool := poolSize(runes)
+	rest := 0
+	if len(runes) > maxEstimateLength {
+		rest = len(runes) - maxEstimateLength
+		runes = runes[:maxEstimateLength]
+	}
+
+	matches := findMatches(runes, words, pool)
+	entropy, sequence := cheapest(runes, matches, pool)
+	entropy += float64(rest) * math.Log2(pool)
+
+	// guesses of long passwords don't fit in float64
+	guesses := math.Min(math.Pow(2, entropy), math.MaxFloat64)
+	strength := Strength{
+		Guesses:   guesses,
+		Entropy:   entropy,
+		CrackTime: guesses / guessRate,
+	}
+	for _, threshold := range scoreGuesses {
+		if strength.Guesses > threshold {
+			strength.Score++
+		}
+	}
+
+	for _, m := range sequence {
+		strength.Patterns = append(strength.Patterns, m.pattern)
+	}
+
+	return feedback(strength, sequence)
+}
+
+// poolSize gets number of characters in classes used by password.
+// It returns 1 if password contains no allowed characters.
+func poolSize(runes []rune) float64 {
+	classes := make(map[string]bool)
+	for _, r := range runes {
+		if class := classOf(r); class != "" {
+			classes[class] = true
+		}
+	}
+
+	var pool float64
+	for class := range classes {
+		pool += poolSizes[class]
+	}
+	if pool == 0 {
+		return 1
+	}
+
+	return pool
+}
+
+// findMatches runs all matchers on password.
+// It returns slice of matches.
+func findMatches(runes []rune, words []string, pool float64) []match {
+	var matches []match
+	matches = append(matches, dictionaryMatches(runes, words)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, repeatMatches(runes, pool)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	return matches
+}
+
+// cheapest finds combination of non-overlapping matches and random parts
+// with the least number of guesses.
+// Guesses of k parts are multiplied by k! because order of parts is unknown.
+// It returns binary logarithm of guesses and matches of combination.
+func cheapest(runes []rune, matches []match, pool float64) (float64, []match) {
+	n := len(runes)
+	byEnd := make([][]match, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	// best[k][j] is the least entropy of first j runes split into k parts,
+	// last part starts at start[k][j] and is random if last[k][j] is nil
+	best := make([][]float64, n+1)
+	start := make([][]int, n+1)
+	last := make([][]*match, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		start[k] = make([]int, n+1)
+		last[k] = make([]*match, n+1)
+		for j := range best[k] {
+			best[k][j] = math.Inf(1)
+		}
+	}
+	best[0][0] = 0
+
+	bits := math.Log2(pool)
+	for j := 1; j <= n; j++ {
+		for k := 1; k <= j; k++ {
+			for i := 0; i < j; i++ {
+				if cost := best[k-1][i] + float64(j-i)*bits; cost < best[k][j] {
+					best[k][j], start[k][j], last[k][j] = cost, i, nil
+				}
+			}
+			for idx := range byEnd[j-1] {
+				m := &byEnd[j-1][idx]
+				if cost := best[k-1][m.i] + math.Log2(m.guesses); cost < best[k][j] {
+					best[k][j], start[k][j], last[k][j] = cost, m.i, m
+				}
+			}
+		}
+	}
+
+	entropy, parts := math.Inf(1), 0
+	for k := 1; k <= n; k++ {
+		lgamma, _ := math.Lgamma(float64(k + 1))
+		if cost := best[k][n] + lgamma/math.Ln2; cost < entropy {
+			entropy, parts = cost, k
+		}
+	}
+
+	sequence := make([]match, parts)
+	for k, j := parts, n; k > 0; k-- {
+		i := start[k][j]
+		if m := last[k][j]; m != nil {
+			sequence[k-1] = *m
+		} else {
+			sequence[k-1] = match{pattern: PatternRandom, i: i, j: j - 1, token: string(runes[i:j])}
+		}
+		j = i
+	}
+
+	return entropy, sequence
+}
+
+// dictionaryMatches finds common passwords and words regardless of case.
+// Words are ranked in the given order before common passwords.
+// It returns slice of matches.
+func dictionaryMatches(runes []rune, words []string) []match {
+	ranks := make(map[string]int, len(words))
+	for i, word := range words {
+		if word = foldWord(word); word != "" {
+			if _, ok := ranks[word]; !ok {
+				ranks[word] = i + 1
+			}
+		}
+	}
+
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != len(runes) {
+		return nil
+	}
+
+	var matches []match
+	for i := range lower {
+		for j := i + minWordLength - 1; j < len(lower); j++ {
+			token := string(lower[i : j+1])
+			rank, user := ranks[token], true
+			if rank == 0 {
+				rank, user = dictionary[token], false
+			}
+			if rank == 0 {
+				continue
+			}
+			guesses := float64(rank) * caseVariations(runes[i:j+1])
+			matches = append(matches, match{pattern: PatternDictionary, i: i, j: j, guesses: guesses, token: token, rank: rank, user: user})
+		}
+	}
+
+	return matches
+}
+
+// caseVariations gets number of ways letters of word can be capitalized
+// by attacker before the given one is tried.
+func caseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0 || (upper == 1 && (unicode.IsUpper(word[0]) || unicode.IsUpper(word[len(word)-1]))):
+		return 2
+	}
+
+	var variations float64
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+// keyDirection gets direction from key at a to adjacent key at b.
+// Row below is shifted by half key to the right.
+// It returns zero keyPosition if keys aren't adjacent.
+func keyDirection(a, b keyPosition) keyPosition {
+	dr, dc := b.row-a.row, b.col-a.col
+	switch {
+	case dr == 0 && (dc == 1 || dc == -1):
+	case dr == 1 && (dc == 0 || dc == -1):
+	case dr == -1 && (dc == 0 || dc == 1):
+	default:
+		return keyPosition{}
+	}
+	return keyPosition{dr, dc}
+}
+
+// keyboardMatches finds runs of adjacent keys on QWERTY keyboard.
+// Guesses depend on length and number of turns like zxcvbn estimates them.
+// It returns slice of matches.
+func keyboardMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes)-1; {
+		j, turns := i, 0
+		var direction keyPosition
+		for j+1 < len(runes) {
+			a, okA := keyboard[runes[j]]
+			b, okB := keyboard[runes[j+1]]
+			if !okA || !okB {
+				break
+			}
+			d := keyDirection(a, b)
+			if d == (keyPosition{}) {
+				break
+			}
+			if d != direction {
+				turns++
+				direction = d
+			}
+			j++
+		}
+
+		if j-i+1 >= minPatternLength {
+			length := j - i + 1
+			var guesses float64
+			for l := 2; l <= length; l++ {
+				for t := 1; t <= min(turns, l-1); t++ {
+					guesses += binomial(l-1, t-1) * keyboardStarts * math.Pow(keyboardDegree, float64(t))
+				}
+			}
+			matches = append(matches, match{pattern: PatternKeyboard, i: i, j: j, guesses: guesses, token: string(runes[i : j+1]), turns: turns})
+		}
+
+		if j > i {
+			i = j
+		} else {
+			i++
+		}
+	}
+
+	return matches
+}
+
+// repeatMatches finds runs of repeated characters or substrings.
+// Guesses are guesses of repeated substring multiplied by number of repeats.
+// It returns slice of matches.
+func repeatMatches(runes []rune, pool float64) []match {
+	var matches []match
+	for i := range runes {
+		for size := 1; i+2*size <= len(runes); size++ {
+			base := runes[i : i+size]
+			count := 1
+			for i+(count+1)*size <= len(runes) && string(runes[i+count*size:i+(count+1)*size]) == string(base) {
+				count++
+			}
+			if count < 2 || count*size < minPatternLength {
+				continue
+			}
+
+			guesses := math.Pow(pool, float64(size))
+			for _, m := range findMatches(base, nil, pool) {
+				if m.i == 0 && m.j == size-1 && m.guesses < guesses {
+					guesses = m.guesses
+				}
+			}
+			j := i + count*size - 1
+			matches = append(matches, match{pattern: PatternRepeat, i: i, j: j, guesses: guesses * float64(count), token: string(runes[i : j+1]), base: string(base)})
+		}
+	}
+
+	return matches
+}
+
+// sequenceMatches finds runs of characters with constant code point step,
+// e.g. "abcd", "7531" or "zyx".
+// It returns slice of matches.
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes)-1; {
+		delta := runes[i+1] - runes[i]
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+
+		if length := j - i + 1; length >= minPatternLength && delta != 0 && delta >= -5 && delta <= 5 {
+			var base float64
+			switch first := runes[i]; {
+			case strings.ContainsRune("aAzZ019", first):
+				base = 4
+			case unicode.IsDigit(first):
+				base = 10
+			default:
+				base = 26
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{pattern: PatternSequence, i: i, j: j, guesses: base * float64(length), token: string(runes[i : j+1])})
+		}
+
+		i = j
+	}
+
+	return matches
+}
+
+// binomial gets number of k-combinations of n elements.
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// feedback sets warning about the worst pattern and suggestions
+// to improve password if score is less than 3.
+// It returns Strength with feedback.
+func feedback(strength Strength, sequence []match) Strength {
+	if strength.Score > 2 {
+		return strength
+	}
+
+	strength.Suggestions = []string{"Use a few words, avoid common phrases"}
+	if len(sequence) == 0 {
+		return strength
+	}
+
+	worst := sequence[0]
+	for _, m := range sequence[1:] {
+		if m.j-m.i > worst.j-worst.i {
+			worst = m
+		}
+	}
+
+	switch worst.pattern {
+	case PatternDictionary:
+		switch {
+		case worst.user:
+			strength.Warning = "Names and blocked words are easy to guess"
+		case worst.rank <= 10:
+			strength.Warning = "This is a top-10 common password"
+		case worst.rank <= 100:
+			strength.Warning = "This is a top-100 common password"
+		default:
+			strength.Warning = "This is a very common password"
+		}
+		strength.Suggestions = append(strength.Suggestions, "Add another word or two, uncommon words are better")
+		if worst.guesses > float64(worst.rank) {
+			strength.Suggestions = append(strength.Suggestions, "Capitalization doesn't help very much")
+		}
+	case PatternKeyboard:
+		if worst.turns == 1 {
+			strength.Warning = "Straight rows of keys are easy to guess"
+		} else {
+			strength.Warning = "Short keyboard patterns are easy to guess"
+		}
+		strength.Suggestions = append(strength.Suggestions, "Use a longer keyboard pattern with more turns")
+	case PatternRepeat:
+		if utf8.RuneCountInString(worst.base) == 1 {
+			strength.Warning = fmt.Sprintf("Repeats like %q are easy to guess", worst.token)
+		} else {
+			strength.Warning = fmt.Sprintf("Repeats like %q are only slightly harder to guess than %q", worst.token, worst.base)
+		}
+		strength.Suggestions = append(strength.Suggestions, "Avoid repeated words and characters")
+	case PatternSequence:
+		strength.Warning = "Sequences like abc or 6543 are easy to guess"
+		strength.Suggestions = append(strength.Suggestions, "Avoid sequences")
+	default:
+		strength.Suggestions = append(strength.Suggestions, "Add another word or two, uncommon words are better")
+	}
+
+	return strength
+}
+
+// CrackTimeDisplay gets human-readable estimated time to crack password.
+func (s Strength) CrackTimeDisplay() string {
+	const (
+		minute  = 60
+		hour    = 60 * minute
+		day     = 24 * hour
+		month   = 31 * day
+		year    = 12 * month
+		century = 100 * year
+	)
+
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"year", year},
+		{"month", month},
+		{"day", day},
+		{"hour", hour},
+		{"minute", minute},
+		{"second", 1},
+	}
+
+	switch {
+	case s.CrackTime < 1:
+		return "less than a second"
+	case s.CrackTime >= century:
+		return "centuries"
+	}
+
+	for _, unit := range units {
+		if s.CrackTime >= unit.seconds {
+			n := int(math.Round(s.CrackTime / unit.seconds))
+			if n == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", n, unit.name)
+		}
+	}
+
+	return "less than a second"
+}
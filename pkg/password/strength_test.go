@@ -0,0 +1,101 @@
+package password
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		words       []string
+		wantScore   int
+		wantPattern string
+	}{
+		{"Empty", "", nil, 0, ""},
+		{"Common", "password", nil, 0, PatternDictionary},
+		{"CommonCapitalized", "Password", nil, 0, PatternDictionary},
+		{"Keyboard", "zxcvbnm,./", nil, 1, PatternKeyboard},
+		{"Repeat", "aaaaaaaaaaaaaaaaaaaa", nil, 0, PatternRepeat},
+		{"RepeatWord", "abcabcabc", nil, 0, PatternRepeat},
+		{"Sequence", "13579", nil, 0, PatternSequence},
+		{"Word", "alice", []string{"alice"}, 0, PatternDictionary},
+		{"Random", "kX9#mP2$vL", nil, 4, PatternRandom},
+		{"Passphrase", "correct horse battery staple", nil, 4, PatternRandom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Estimate([]byte(tt.password), tt.words...)
+			if got.Score != tt.wantScore {
+				t.Errorf("Estimate() score = %d, want %d", got.Score, tt.wantScore)
+			}
+			if tt.wantPattern != "" && !slices.Contains(got.Patterns, tt.wantPattern) {
+				t.Errorf("Estimate() patterns = %v, want %s", got.Patterns, tt.wantPattern)
+			}
+			if (got.Score < 3) != (len(got.Suggestions) > 0) {
+				t.Errorf("Estimate() suggestions = %v, score %d", got.Suggestions, got.Score)
+			}
+		})
+	}
+}
+
+func TestEstimate_order(t *testing.T) {
+	tests := []struct {
+		name   string
+		weaker string
+		strong string
+	}{
+		{"Capitalization", "password", "Password"},
+		{"Length", "Tr0ub4dor", "Tr0ub4dor&3"},
+		{"CommonRank", "123456", "sunday"},
+		{"RepeatCount", "abcabc", "abcabcabcabc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weaker, stronger := Estimate([]byte(tt.weaker)), Estimate([]byte(tt.strong))
+			if weaker.Guesses >= stronger.Guesses {
+				t.Errorf("Estimate() guesses of %q = %v, not less than %q = %v", tt.weaker, weaker.Guesses, tt.strong, stronger.Guesses)
+			}
+		})
+	}
+}
+
+func Test_policy_Estimate(t *testing.T) {
+	p, err := NewPolicy(PolicyParams{1, 128, nil, 0, []string{"acme"}, 0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := []string{"alice"}
+	plain := Estimate([]byte("acme2024alice"))
+	got := p.Estimate([]byte("acme2024alice"), words...)
+	if got.Guesses >= plain.Guesses {
+		t.Errorf("policy.Estimate() guesses = %v, want less than %v", got.Guesses, plain.Guesses)
+	}
+	if len(words) != 1 {
+		t.Errorf("policy.Estimate() changed words = %v", words)
+	}
+}
+
+func TestStrength_CrackTimeDisplay(t *testing.T) {
+	tests := []struct {
+		name      string
+		crackTime float64
+		want      string
+	}{
+		{"Instant", 0.5, "less than a second"},
+		{"Second", 1, "1 second"},
+		{"Minutes", 150, "3 minutes"},
+		{"Hours", 7200, "2 hours"},
+		{"Years", 3 * 12 * 31 * 86400, "3 years"},
+		{"Centuries", 1e12, "centuries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Strength{CrackTime: tt.crackTime}).CrackTimeDisplay(); got != tt.want {
+				t.Errorf("Strength.CrackTimeDisplay() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Package profile provides structures to validate custom user attributes
+// against schema.
+package profile
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"unicode/utf8"
+)
+
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+	TypeURL     = "url"
+)
+
+const (
+	ReasonUnknown   = "unknown"
+	ReasonReadOnly  = "read_only"
+	ReasonType      = "type"
+	ReasonMaxLength = "max_length"
+	ReasonRequired  = "required"
+)
+
+const (
+	// maxNameLength is max number of characters in attribute name.
+	maxNameLength = 64
+
+	// maxValueLength is max number of characters in string attribute.
+	maxValueLength = 4096
+
+	// maxSafeInteger is max integer that can be stored in float64 without precision loss.
+	maxSafeInteger = 1<<53 - 1
+)
+
+var ErrSchemaInvalid = errors.New("schema is invalid")
+
+// Field represents schema of attribute.
+// MaxLength is max number of characters in string and URL attributes.
+// Required attributes can't be removed, they must be editable by user.
+// Editable attributes can be changed by user, others only by administrator.
+// Claim attributes are included in access token.
+type Field struct {
+	Type      string
+	MaxLength int
+	Required  bool
+	Editable  bool
+	Claim     bool
+}
+
+// Schema maps attribute names to their fields.
+type Schema map[string]Field
+
+// Validate checks that names consist of lowercase letters, digits and underscores,
+// types are known, max lengths of string attributes are in range [1,4096]
+// and required attributes are editable.
+// It returns error if at least one of fields is invalid.
+func (s Schema) Validate() error {
+	for name, field := range s {
+		if !validName(name) {
+			return ErrSchemaInvalid
+		}
+		switch field.Type {
+		case TypeString, TypeURL:
+			if field.MaxLength < 1 || field.MaxLength > maxValueLength {
+				return ErrSchemaInvalid
+			}
+		case TypeNumber, TypeInteger, TypeBoolean:
+		default:
+			return ErrSchemaInvalid
+		}
+		if field.Required && !field.Editable {
+			return ErrSchemaInvalid
+		}
+	}
+	return nil
+}
+
+// validName checks whether attribute name is non-empty and
+// consists of lowercase ASCII letters, digits and underscores.
+func validName(name string) bool {
+	if name == "" || len(name) > maxNameLength {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// Error represents error that occurs if attributes violate schema.
+// Fields maps names of invalid attributes to reasons.
+type Error struct {
+	Fields map[string]string
+}
+
+// Error returns string representation of Error.
+func (e *Error) Error() string {
+	return "attributes are invalid"
+}
+
+// Apply merges patch into copy of attributes like JSON merge patch does,
+// null values remove attributes. Only editable attributes can be changed
+// unless admin is set.
+// It returns merged attributes or Error listing invalid attributes.
+func (s Schema) Apply(attributes map[string]any, patch map[string]any, admin bool) (map[string]any, error) {
+	result := make(map[string]any, len(attributes)+len(patch))
+	for name, value := range attributes {
+		result[name] = value
+	}
+
+	invalid := make(map[string]string)
+	for name, value := range patch {
+		field, ok := s[name]
+		if !ok {
+			invalid[name] = ReasonUnknown
+			continue
+		}
+
+		if !admin && !field.Editable {
+			invalid[name] = ReasonReadOnly
+			continue
+		}
+
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+
+		if reason := field.check(value); reason != "" {
+			invalid[name] = reason
+			continue
+		}
+
+		result[name] = value
+	}
+
+	for name, field := range s {
+		if _, ok := result[name]; field.Required && !ok {
+			if _, ok := invalid[name]; !ok {
+				invalid[name] = ReasonRequired
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, &Error{invalid}
+	}
+
+	return result, nil
+}
+
+// check checks value decoded from JSON against field.
+// It returns reason if value is invalid or empty string otherwise.
+func (f Field) check(value any) string {
+	switch f.Type {
+	case TypeString, TypeURL:
+		s, ok := value.(string)
+		if !ok || !utf8.ValidString(s) {
+			return ReasonType
+		}
+		if utf8.RuneCountInString(s) > f.MaxLength {
+			return ReasonMaxLength
+		}
+		if f.Type == TypeURL {
+			u, err := url.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return ReasonType
+			}
+		}
+	case TypeNumber:
+		if _, ok := value.(float64); !ok {
+			return ReasonType
+		}
+	case TypeInteger:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) || math.Abs(n) > maxSafeInteger {
+			return ReasonType
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return ReasonType
+		}
+	}
+	return ""
+}
+
+// Claims gets attributes that are included in access token.
+// It returns nil if there are no such attributes.
+func (s Schema) Claims(attributes map[string]any) map[string]any {
+	var claims map[string]any
+	for name, value := range attributes {
+		if field, ok := s[name]; ok && field.Claim {
+			if claims == nil {
+				claims = make(map[string]any)
+			}
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+// HasClaims checks whether at least one attribute is included in access token.
+func (s Schema) HasClaims() bool {
+	for _, field := range s {
+		if field.Claim {
+			return true
+		}
+	}
+	return false
+}
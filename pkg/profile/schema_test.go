@@ -0,0 +1,94 @@
+package profile
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testSchema = Schema{
+	"display_name": {TypeString, 8, true, true, true},
+	"avatar_url":   {TypeURL, 64, false, true, false},
+	"age":          {TypeInteger, 0, false, true, false},
+	"rating":       {TypeNumber, 0, false, true, false},
+	"verified":     {TypeBoolean, 0, false, false, true},
+}
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  Schema
+		wantErr bool
+	}{
+		{"ValidSchema", testSchema, false},
+		{"EmptySchema", Schema{}, false},
+		{"InvalidName", Schema{"Display Name": {TypeString, 8, false, true, false}}, true},
+		{"UnknownType", Schema{"name": {"date", 0, false, true, false}}, true},
+		{"ZeroMaxLength", Schema{"name": {TypeString, 0, false, true, false}}, true},
+		{"LargeMaxLength", Schema{"name": {TypeURL, 4097, false, true, false}}, true},
+		{"RequiredReadOnly", Schema{"name": {TypeString, 8, true, false, false}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schema.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Schema.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_Apply(t *testing.T) {
+	current := map[string]any{"display_name": "Alice", "age": float64(30)}
+
+	tests := []struct {
+		name    string
+		patch   map[string]any
+		admin   bool
+		want    map[string]any
+		wantErr map[string]string
+	}{
+		{"Set", map[string]any{"avatar_url": "https://example.org/a.png", "rating": 4.5}, false, map[string]any{"display_name": "Alice", "age": float64(30), "avatar_url": "https://example.org/a.png", "rating": 4.5}, nil},
+		{"Remove", map[string]any{"age": nil}, false, map[string]any{"display_name": "Alice"}, nil},
+		{"AdminReadOnly", map[string]any{"verified": true}, true, map[string]any{"display_name": "Alice", "age": float64(30), "verified": true}, nil},
+		{"ReadOnly", map[string]any{"verified": true}, false, nil, map[string]string{"verified": ReasonReadOnly}},
+		{"Unknown", map[string]any{"nickname": "al"}, false, nil, map[string]string{"nickname": ReasonUnknown}},
+		{"Type", map[string]any{"age": "30", "rating": true}, false, nil, map[string]string{"age": ReasonType, "rating": ReasonType}},
+		{"Fraction", map[string]any{"age": 30.5}, false, nil, map[string]string{"age": ReasonType}},
+		{"MaxLength", map[string]any{"display_name": "Алиса Иванова"}, false, nil, map[string]string{"display_name": ReasonMaxLength}},
+		{"URLScheme", map[string]any{"avatar_url": "javascript:alert(1)"}, false, nil, map[string]string{"avatar_url": ReasonType}},
+		{"Required", map[string]any{"display_name": nil}, false, nil, map[string]string{"display_name": ReasonRequired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testSchema.Apply(current, tt.patch, tt.admin)
+			if tt.wantErr != nil {
+				e, ok := err.(*Error)
+				if !ok || !reflect.DeepEqual(e.Fields, tt.wantErr) {
+					t.Fatalf("Schema.Apply() error = %v, want fields %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Schema.Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Schema.Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if len(current) != 2 {
+		t.Errorf("Schema.Apply() changed attributes = %v", current)
+	}
+}
+
+func TestSchema_Claims(t *testing.T) {
+	got := testSchema.Claims(map[string]any{"display_name": "Alice", "age": float64(30), "verified": true, "removed": "x"})
+	want := map[string]any{"display_name": "Alice", "verified": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Schema.Claims() = %v, want %v", got, want)
+	}
+
+	if got := (Schema{}).Claims(map[string]any{"age": float64(30)}); got != nil {
+		t.Errorf("Schema.Claims() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,131 @@
+package pwned
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Build converts text dataset to data file.
+// Each line of r is hexadecimal SHA-1 hash optionally followed by ":" and count,
+// lines must be sorted by hash as in HIBP "ordered by hash" downloads.
+// Hashes with count less than minCount are skipped to compact data file,
+// duplicate hashes are merged.
+// It returns number of written records.
+func Build(w io.Writer, r io.Reader, minCount uint32) (int, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return 0, err
+	}
+
+	index := make([]uint64, indexLength)
+	var (
+		records int
+		last    []byte
+		count   uint64
+	)
+
+	// flush writes the last hash if its count reaches minCount
+	flush := func() error {
+		if last == nil || count < uint64(minCount) {
+			return nil
+		}
+
+		if count > math.MaxUint32 {
+			count = math.MaxUint32
+		}
+
+		record := make([]byte, recordSize)
+		copy(record, last[prefixSize:])
+		binary.BigEndian.PutUint32(record[suffixSize:], uint32(count))
+		if _, err := bw.Write(record); err != nil {
+			return err
+		}
+
+		index[prefix(last)+1]++
+		records++
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		hash, lineCount, err := parseLine(line)
+		if err != nil {
+			return records, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		if last != nil {
+			switch cmp := bytes.Compare(hash, last); {
+			case cmp < 0:
+				return records, fmt.Errorf("line %d: %w", n, ErrLineUnsorted)
+			case cmp == 0:
+				count += lineCount
+				continue
+			}
+		}
+
+		if err := flush(); err != nil {
+			return records, err
+		}
+		last, count = hash, lineCount
+	}
+	if err := scanner.Err(); err != nil {
+		return records, err
+	}
+
+	if err := flush(); err != nil {
+		return records, err
+	}
+
+	// index is converted from numbers of records per prefix to cumulative numbers
+	for i := 1; i < indexLength; i++ {
+		index[i] += index[i-1]
+	}
+
+	buf := make([]byte, 8)
+	for _, value := range index {
+		binary.BigEndian.PutUint64(buf, value)
+		if _, err := bw.Write(buf); err != nil {
+			return records, err
+		}
+	}
+
+	return records, bw.Flush()
+}
+
+// parseLine parses hash and optional count of text dataset line.
+// Count is 1 if it's omitted.
+// It returns hash bytes and count.
+func parseLine(line []byte) ([]byte, uint64, error) {
+	hexHash, rawCount, found := bytes.Cut(line, []byte(":"))
+	if len(hexHash) != 2*sha1.Size {
+		return nil, 0, ErrLineInvalid
+	}
+
+	hash := make([]byte, sha1.Size)
+	if _, err := hex.Decode(hash, hexHash); err != nil {
+		return nil, 0, ErrLineInvalid
+	}
+
+	if !found {
+		return hash, 1, nil
+	}
+
+	count, err := strconv.ParseUint(string(rawCount), 10, 64)
+	if err != nil {
+		return nil, 0, ErrLineInvalid
+	}
+
+	return hash, count, nil
+}
@@ -0,0 +1,128 @@
+package pwned
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+)
+
+// Dataset is interface implemented by types
+// that can count occurrences of password in breaches.
+type Dataset interface {
+	// Count gets number of times password was seen in breaches.
+	// It returns zero if password isn't found.
+	Count(password []byte) (int, error)
+
+	// Close closes underlying data file.
+	Close() error
+}
+
+// dataset implements Dataset interface.
+// Index is kept in memory and records are read from file on demand,
+// so that lookup takes few small reads regardless of file size.
+type dataset struct {
+	file  *os.File
+	index []uint64
+}
+
+// Open opens data file created by Build and reads its index.
+// It returns pointer to a dataset instance or nil if file is invalid.
+func Open(path string) (*dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := readIndex(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &dataset{file, index}, nil
+}
+
+// readIndex checks header and size of data file and reads its index.
+// It returns slice of cumulative numbers of records per prefix.
+func readIndex(file *os.File) ([]uint64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	if size < int64(headerSize+indexSize) {
+		return nil, ErrFileInvalid
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if string(header) != magic {
+		return nil, ErrFileInvalid
+	}
+
+	buf := make([]byte, indexSize)
+	if _, err := file.ReadAt(buf, size-int64(indexSize)); err != nil {
+		return nil, err
+	}
+
+	index := make([]uint64, indexLength)
+	for i := range index {
+		index[i] = binary.BigEndian.Uint64(buf[i*8:])
+		if i > 0 && index[i] < index[i-1] {
+			return nil, ErrFileInvalid
+		}
+	}
+
+	if int64(headerSize)+int64(index[indexLength-1])*recordSize+int64(indexSize) != size {
+		return nil, ErrFileInvalid
+	}
+
+	return index, nil
+}
+
+// Count hashes password using SHA-1 and searches hash
+// among records with the same prefix.
+// It returns number of times password was seen in breaches.
+func (d *dataset) Count(password []byte) (int, error) {
+	hash := sha1.Sum(password)
+	p := prefix(hash[:])
+	start, end := d.index[p], d.index[p+1]
+	suffix := hash[prefixSize:]
+
+	record := make([]byte, recordSize)
+	var readErr error
+
+	// read reads record by number
+	read := func(i int) []byte {
+		offset := int64(headerSize) + (int64(start)+int64(i))*recordSize
+		if _, err := d.file.ReadAt(record, offset); err != nil && err != io.EOF {
+			readErr = err
+		}
+		return record
+	}
+
+	n := int(end - start)
+	i := sort.Search(n, func(i int) bool {
+		return readErr != nil || bytes.Compare(read(i)[:suffixSize], suffix) >= 0
+	})
+	if readErr != nil {
+		return 0, readErr
+	}
+
+	if i == n || !bytes.Equal(read(i)[:suffixSize], suffix) {
+		return 0, readErr
+	}
+
+	return int(binary.BigEndian.Uint32(record[suffixSize:])), readErr
+}
+
+// Close closes underlying data file.
+func (d *dataset) Close() error {
+	return d.file.Close()
+}
@@ -0,0 +1,147 @@
+package pwned
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// line creates text dataset line of password hash and count.
+func line(password string, count string) string {
+	hash := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(hash[:])) + ":" + count
+}
+
+// sorted sorts text dataset lines by hash.
+func sorted(lines ...string) string {
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		min     uint32
+		want    int
+		wantErr error
+	}{
+		{"Valid", sorted(line("password", "10"), line("123456", "20"), line("qwerty", "5")), 1, 3, nil},
+		{"MinCount", sorted(line("password", "10"), line("123456", "20"), line("qwerty", "5")), 10, 2, nil},
+		{"Duplicates", sorted(line("password", "10"), line("password", "3")), 1, 1, nil},
+		{"NoCount", strings.Split(line("password", "1"), ":")[0], 1, 1, nil},
+		{"Lowercase", strings.ToLower(line("password", "1")), 1, 1, nil},
+		{"EmptyLines", "\n" + line("password", "1") + "\n\n", 1, 1, nil},
+		{"Empty", "", 1, 0, nil},
+		{"Unsorted", strings.Repeat("F", 40) + ":1\n" + strings.Repeat("0", 40) + ":1", 1, 0, ErrLineUnsorted},
+		{"ShortHash", "5BAA61E4C9B93F3F:1", 1, 0, ErrLineInvalid},
+		{"InvalidHex", strings.Repeat("Z", 40) + ":1", 1, 0, ErrLineInvalid},
+		{"InvalidCount", strings.Split(line("password", "1"), ":")[0] + ":x", 1, 0, ErrLineInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			got, err := Build(&w, strings.NewReader(tt.input), tt.min)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Build() = %v, want %v", got, tt.want)
+			}
+			if err == nil && w.Len() != headerSize+got*recordSize+indexSize {
+				t.Errorf("Build() wrote %d bytes, want %d", w.Len(), headerSize+got*recordSize+indexSize)
+			}
+		})
+	}
+}
+
+func Test_dataset_Count(t *testing.T) {
+	input := sorted(
+		line("password", "10"),
+		line("password", "5"),
+		line("123456", "20"),
+		line("qwerty", "3"),
+		line("Test123$", "1"),
+	)
+
+	path := filepath.Join(t.TempDir(), "pwned.bin")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Build(file, strings.NewReader(input), 2); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	d, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{"Merged", "password", 15},
+		{"Found", "123456", 20},
+		{"BelowMinCount", "Test123$", 0},
+		{"NotFound", "correct horse battery staple", 0},
+		{"CaseSensitive", "Password", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.Count([]byte(tt.password))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("dataset.Count() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	var valid bytes.Buffer
+	if _, err := Build(&valid, strings.NewReader(line("password", "1")), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"Valid", valid.Bytes(), false},
+		{"Empty", nil, true},
+		{"Truncated", valid.Bytes()[:valid.Len()-1], true},
+		{"InvalidMagic", append([]byte("NOTPWNED"), valid.Bytes()[headerSize:]...), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			d, err := Open(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if d != nil {
+				d.Close()
+			}
+		})
+	}
+}
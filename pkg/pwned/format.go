@@ -0,0 +1,34 @@
+// Package pwned provides structures to look up passwords
+// in local copy of breached password hashes (HIBP "pwned passwords" SHA-1 dataset).
+package pwned
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+)
+
+// Data file consists of header, records sorted by hash and index.
+// Record is SHA-1 hash without 2-byte prefix followed by big-endian uint32 count.
+// Index contains number of records before each 2-byte prefix
+// and total number of records as big-endian uint64 values.
+const (
+	magic       = "PWNED\x00\x00\x01"
+	headerSize  = len(magic)
+	prefixSize  = 2
+	suffixSize  = sha1.Size - prefixSize
+	recordSize  = suffixSize + 4
+	indexLength = 1<<(8*prefixSize) + 1
+	indexSize   = indexLength * 8
+)
+
+var (
+	ErrFileInvalid  = errors.New("data file is invalid")
+	ErrLineInvalid  = errors.New("line is invalid")
+	ErrLineUnsorted = errors.New("lines are not sorted by hash")
+)
+
+// prefix gets index of 2-byte prefix of hash.
+func prefix(hash []byte) int {
+	return int(binary.BigEndian.Uint16(hash[:prefixSize]))
+}
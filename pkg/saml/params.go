@@ -0,0 +1,53 @@
+// Package saml provides structures and functions to act as SAML 2.0 service provider:
+// build metadata and authentication requests, parse and validate IdP responses.
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+)
+
+var (
+	ErrParamsInvalid      = errors.New("params are invalid")
+	ErrCertificateInvalid = errors.New("certificate is invalid")
+)
+
+// Params represents params of service provider and identity provider.
+type Params struct {
+	EntityID       string
+	ACSURL         string
+	IDPEntityID    string
+	IDPSSOURL      string
+	IDPCertificate *x509.Certificate
+}
+
+// Validate checks that all parameters are set.
+// It returns error if at least one of parameters is empty.
+func (p Params) Validate() error {
+	if p.EntityID == "" || p.ACSURL == "" || p.IDPEntityID == "" || p.IDPSSOURL == "" || p.IDPCertificate == nil {
+		return ErrParamsInvalid
+	}
+	return nil
+}
+
+// ParseCertificate parses X.509 certificate encoded in PEM format.
+// It returns pointer to a x509.Certificate or nil if parsing failed.
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrCertificateInvalid
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// ReadCertificate reads X.509 certificate and parses it.
+// It returns pointer to a x509.Certificate or nil if reading/parsing failed.
+func ReadCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificate(data)
+}
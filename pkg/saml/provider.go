@@ -0,0 +1,188 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/url"
+	"time"
+
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	assertionNS = "urn:oasis:names:tc:SAML:2.0:assertion"
+	protocolNS  = "urn:oasis:names:tc:SAML:2.0:protocol"
+
+	bindingPOST     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	nameIDFormat    = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	statusSuccess   = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	methodBearer    = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	timeFormat      = "2006-01-02T15:04:05Z"
+	requestIDLength = 20
+)
+
+// ServiceProvider is interface implemented by types
+// that can act as SAML 2.0 service provider.
+type ServiceProvider interface {
+	// Metadata creates service provider metadata.
+	// It returns XML document bytes.
+	Metadata() ([]byte, error)
+
+	// AuthnRequest creates a new authentication request
+	// encoded for HTTP-Redirect binding.
+	// It returns request ID and URL to redirect user to.
+	AuthnRequest(relayState string) (string, string, error)
+
+	// ParseResponse decodes response received using HTTP-POST binding,
+	// verifies its signature and validates assertion.
+	// It returns pointer to an Assertion instance.
+	ParseResponse(response string, requestID string) (*Assertion, error)
+}
+
+// serviceProvider implements ServiceProvider interface.
+type serviceProvider struct {
+	params    Params
+	validator *dsig.ValidationContext
+}
+
+// NewServiceProvider validates params and creates a new serviceProvider.
+// It returns pointer to a serviceProvider instance or nil if params are invalid.
+func NewServiceProvider(params Params) (*serviceProvider, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	store := &dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{params.IDPCertificate}}
+	return &serviceProvider{params, dsig.NewDefaultValidationContext(store)}, nil
+}
+
+// entityDescriptor represents SAML metadata root element.
+type entityDescriptor struct {
+	XMLName         xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata SPSSODescriptor"`
+}
+
+// spSSODescriptor represents SAML metadata element describing service provider.
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool     `xml:",attr"`
+	WantAssertionsSigned       bool     `xml:",attr"`
+	ProtocolSupportEnumeration string   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string   `xml:"urn:oasis:names:tc:SAML:2.0:metadata NameIDFormat"`
+	AssertionConsumerService   endpoint `xml:"urn:oasis:names:tc:SAML:2.0:metadata AssertionConsumerService"`
+}
+
+// endpoint represents SAML metadata indexed endpoint.
+type endpoint struct {
+	Binding  string `xml:",attr"`
+	Location string `xml:",attr"`
+	Index    int    `xml:"index,attr"`
+}
+
+// Metadata creates service provider metadata.
+// It returns XML document bytes.
+func (s *serviceProvider) Metadata() ([]byte, error) {
+	metadata := entityDescriptor{
+		EntityID: s.params.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: protocolNS,
+			NameIDFormat:               nameIDFormat,
+			AssertionConsumerService:   endpoint{bindingPOST, s.params.ACSURL, 0},
+		},
+	}
+
+	data, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// authnRequest represents SAML authentication request.
+type authnRequest struct {
+	XMLName                     xml.Name     `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string       `xml:",attr"`
+	Version                     string       `xml:",attr"`
+	IssueInstant                string       `xml:",attr"`
+	Destination                 string       `xml:",attr"`
+	AssertionConsumerServiceURL string       `xml:",attr"`
+	ProtocolBinding             string       `xml:",attr"`
+	Issuer                      string       `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	NameIDPolicy                nameIDPolicy `xml:"urn:oasis:names:tc:SAML:2.0:protocol NameIDPolicy"`
+}
+
+// nameIDPolicy represents SAML name identifier policy.
+type nameIDPolicy struct {
+	Format      string `xml:",attr"`
+	AllowCreate bool   `xml:",attr"`
+}
+
+// newRequestID generates random identifier that is valid xsd:ID.
+// It returns string starting with "id-" followed by hex characters.
+func newRequestID() (string, error) {
+	b := make([]byte, requestIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "id-" + hex.EncodeToString(b), nil
+}
+
+// AuthnRequest creates a new authentication request
+// encoded for HTTP-Redirect binding.
+// It returns request ID and URL to redirect user to.
+func (s *serviceProvider) AuthnRequest(relayState string) (string, string, error) {
+	id, err := newRequestID()
+	if err != nil {
+		return "", "", err
+	}
+
+	request := authnRequest{
+		ID:                          id,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(timeFormat),
+		Destination:                 s.params.IDPSSOURL,
+		AssertionConsumerServiceURL: s.params.ACSURL,
+		ProtocolBinding:             bindingPOST,
+		Issuer:                      s.params.EntityID,
+		NameIDPolicy:                nameIDPolicy{nameIDFormat, true},
+	}
+
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(s.params.IDPSSOURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+
+	return id, u.String(), nil
+}
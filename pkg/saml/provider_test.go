@@ -0,0 +1,98 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNewServiceProvider(t *testing.T) {
+	idp := newIdentity(t)
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"ValidParams", Params{"https://sp", "https://sp/acs", "https://idp", "https://idp/sso", idp.cert}, false},
+		{"EmptyEntityID", Params{"", "https://sp/acs", "https://idp", "https://idp/sso", idp.cert}, true},
+		{"EmptyCertificate", Params{"https://sp", "https://sp/acs", "https://idp", "https://idp/sso", nil}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewServiceProvider(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewServiceProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got == nil {
+				t.Errorf("NewServiceProvider() = %v, want not nil", got)
+			}
+		})
+	}
+}
+
+func Test_serviceProvider_Metadata(t *testing.T) {
+	idp := newIdentity(t)
+	sp, _ := NewServiceProvider(Params{"https://sp", "https://sp/acs", "https://idp", "https://idp/sso", idp.cert})
+
+	data, err := sp.Metadata()
+	if err != nil {
+		t.Errorf("serviceProvider.Metadata() error = %v, wantErr %v", err, false)
+		return
+	}
+
+	var got entityDescriptor
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Errorf("serviceProvider.Metadata() returned invalid XML: %v", err)
+		return
+	}
+	if got.EntityID != "https://sp" || got.SPSSODescriptor.AssertionConsumerService.Location != "https://sp/acs" {
+		t.Errorf("serviceProvider.Metadata() = %s", data)
+	}
+}
+
+func Test_serviceProvider_AuthnRequest(t *testing.T) {
+	idp := newIdentity(t)
+	sp, _ := NewServiceProvider(Params{"https://sp", "https://sp/acs", "https://idp", "https://idp/sso?tenant=1", idp.cert})
+
+	id, redirect, err := sp.AuthnRequest("state")
+	if err != nil {
+		t.Errorf("serviceProvider.AuthnRequest() error = %v, wantErr %v", err, false)
+		return
+	}
+
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Errorf("serviceProvider.AuthnRequest() returned invalid URL: %v", err)
+		return
+	}
+	query := u.Query()
+	if !strings.HasPrefix(redirect, "https://idp/sso?") || query.Get("tenant") != "1" || query.Get("RelayState") != "state" {
+		t.Errorf("serviceProvider.AuthnRequest() url = %s", redirect)
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(query.Get("SAMLRequest"))
+	if err != nil {
+		t.Errorf("serviceProvider.AuthnRequest() returned invalid encoding: %v", err)
+		return
+	}
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Errorf("serviceProvider.AuthnRequest() returned invalid compression: %v", err)
+		return
+	}
+
+	var got authnRequest
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Errorf("serviceProvider.AuthnRequest() returned invalid XML: %v", err)
+		return
+	}
+	if got.ID != id || got.Issuer != "https://sp" || got.AssertionConsumerServiceURL != "https://sp/acs" {
+		t.Errorf("serviceProvider.AuthnRequest() request = %s", data)
+	}
+}
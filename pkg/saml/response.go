@@ -0,0 +1,244 @@
+package saml
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// clockSkew is allowed difference between IdP and SP clocks.
+const clockSkew = 90 * time.Second
+
+var (
+	ErrResponseInvalid  = errors.New("response is invalid")
+	ErrStatusInvalid    = errors.New("response status is not success")
+	ErrSignatureInvalid = errors.New("signature is invalid")
+	ErrIssuerInvalid    = errors.New("issuer is invalid")
+	ErrAudienceInvalid  = errors.New("audience is invalid")
+	ErrConditionsFailed = errors.New("conditions are not met")
+	ErrSubjectInvalid   = errors.New("subject confirmation is invalid")
+)
+
+// Assertion represents validated SAML assertion.
+type Assertion struct {
+	ID         string
+	NameID     string
+	Attributes map[string][]string
+	ExpiresAt  time.Time
+}
+
+// response represents SAML response fields used by service provider.
+type response struct {
+	XMLName     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol Response"`
+	Destination string   `xml:",attr"`
+	Status      struct {
+		StatusCode struct {
+			Value string `xml:",attr"`
+		} `xml:"StatusCode"`
+	} `xml:"Status"`
+}
+
+// assertion represents SAML assertion fields used by service provider.
+type assertion struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	ID      string   `xml:",attr"`
+	Issuer  string   `xml:"Issuer"`
+	Subject struct {
+		NameID               string `xml:"NameID"`
+		SubjectConfirmations []struct {
+			Method string `xml:",attr"`
+			Data   struct {
+				NotOnOrAfter time.Time `xml:",attr"`
+				Recipient    string    `xml:",attr"`
+				InResponseTo string    `xml:",attr"`
+			} `xml:"SubjectConfirmationData"`
+		} `xml:"SubjectConfirmation"`
+	} `xml:"Subject"`
+	Conditions struct {
+		NotBefore            time.Time `xml:",attr"`
+		NotOnOrAfter         time.Time `xml:",attr"`
+		AudienceRestrictions []struct {
+			Audiences []string `xml:"Audience"`
+		} `xml:"AudienceRestriction"`
+	} `xml:"Conditions"`
+	Attributes []struct {
+		Name   string   `xml:",attr"`
+		Values []string `xml:"AttributeValue"`
+	} `xml:"AttributeStatement>Attribute"`
+}
+
+// ParseResponse decodes response received using HTTP-POST binding,
+// verifies its signature and validates assertion.
+// Either response or assertion must be signed by IdP certificate.
+// It returns pointer to an Assertion instance or nil if response is invalid.
+func (s *serviceProvider) ParseResponse(data string, requestID string) (*Assertion, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	root := doc.Root()
+	if root == nil {
+		return nil, ErrResponseInvalid
+	}
+
+	// signed response includes signed assertion,
+	// otherwise assertion must be signed itself
+	signed := false
+	if validated, err := s.validator.Validate(root); err == nil {
+		root, signed = validated, true
+	} else if !errors.Is(err, dsig.ErrMissingSignature) {
+		return nil, ErrSignatureInvalid
+	}
+
+	var resp response
+	if err := etreeutils.NSUnmarshalElement(etreeutils.NewDefaultNSContext(), root, &resp); err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	if resp.Status.StatusCode.Value != statusSuccess {
+		return nil, ErrStatusInvalid
+	}
+
+	if resp.Destination != "" && resp.Destination != s.params.ACSURL {
+		return nil, ErrResponseInvalid
+	}
+
+	el, err := s.findAssertion(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !signed {
+		if el, err = s.validator.Validate(el); err != nil {
+			return nil, ErrSignatureInvalid
+		}
+	}
+
+	var a assertion
+	if err := etreeutils.NSUnmarshalElement(etreeutils.NewDefaultNSContext(), el, &a); err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	return s.validate(&a, requestID, time.Now())
+}
+
+// findAssertion finds the only assertion that is a direct child of response
+// and detaches it with namespaces declared in ancestors.
+// It returns error if response contains no or several assertions.
+func (s *serviceProvider) findAssertion(root *etree.Element) (*etree.Element, error) {
+	var found *etree.Element
+	for _, child := range root.ChildElements() {
+		if child.Tag == "EncryptedAssertion" {
+			return nil, ErrResponseInvalid
+		}
+		if child.Tag != "Assertion" {
+			continue
+		}
+		if found != nil {
+			return nil, ErrResponseInvalid
+		}
+		found = child
+	}
+
+	if found == nil {
+		return nil, ErrResponseInvalid
+	}
+
+	ctx, err := etreeutils.NSBuildParentContext(found)
+	if err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	ctx, err = ctx.SubContext(found)
+	if err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	if space, err := ctx.LookupPrefix(found.Space); err != nil || space != assertionNS {
+		return nil, ErrResponseInvalid
+	}
+
+	el, err := etreeutils.NSDetatch(ctx, found)
+	if err != nil {
+		return nil, ErrResponseInvalid
+	}
+
+	return el, nil
+}
+
+// validate checks issuer, conditions and subject confirmation of assertion.
+// It returns pointer to an Assertion instance or nil if assertion is invalid.
+func (s *serviceProvider) validate(a *assertion, requestID string, now time.Time) (*Assertion, error) {
+	if a.ID == "" {
+		return nil, ErrResponseInvalid
+	}
+
+	if a.Issuer != s.params.IDPEntityID {
+		return nil, ErrIssuerInvalid
+	}
+
+	conditions := a.Conditions
+	if !conditions.NotBefore.IsZero() && now.Add(clockSkew).Before(conditions.NotBefore) {
+		return nil, ErrConditionsFailed
+	}
+	if conditions.NotOnOrAfter.IsZero() || !now.Add(-clockSkew).Before(conditions.NotOnOrAfter) {
+		return nil, ErrConditionsFailed
+	}
+
+	// each audience restriction must include service provider
+	if len(conditions.AudienceRestrictions) == 0 {
+		return nil, ErrAudienceInvalid
+	}
+	for _, restriction := range conditions.AudienceRestrictions {
+		found := false
+		for _, audience := range restriction.Audiences {
+			if audience == s.params.EntityID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrAudienceInvalid
+		}
+	}
+
+	// at least one bearer subject confirmation must be valid
+	confirmed := false
+	for _, confirmation := range a.Subject.SubjectConfirmations {
+		data := confirmation.Data
+		if confirmation.Method != methodBearer ||
+			data.Recipient != s.params.ACSURL ||
+			data.InResponseTo == "" || data.InResponseTo != requestID ||
+			data.NotOnOrAfter.IsZero() || !now.Add(-clockSkew).Before(data.NotOnOrAfter) {
+			continue
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed || a.Subject.NameID == "" {
+		return nil, ErrSubjectInvalid
+	}
+
+	attributes := make(map[string][]string, len(a.Attributes))
+	for _, attr := range a.Attributes {
+		attributes[attr.Name] = append(attributes[attr.Name], attr.Values...)
+	}
+
+	return &Assertion{
+		ID:         a.ID,
+		NameID:     a.Subject.NameID,
+		Attributes: attributes,
+		ExpiresAt:  conditions.NotOnOrAfter.Add(clockSkew),
+	}, nil
+}
@@ -0,0 +1,280 @@
+package saml
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// identity represents locally generated IdP key and certificate.
+type identity struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newIdentity(t *testing.T) *identity {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &identity{key, cert}
+}
+
+// sign signs element using enveloped signature and exclusive canonicalization.
+func (i *identity) sign(t *testing.T, el *etree.Element) *etree.Element {
+	t.Helper()
+	ctx, err := dsig.NewSigningContext(i.key, [][]byte{i.cert.Raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	signed, err := ctx.SignEnveloped(el)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// fixture represents values substituted into testdata/response.xml.
+type fixture struct {
+	ResponseID   string
+	AssertionID  string
+	IssueInstant string
+	Destination  string
+	InResponseTo string
+	Issuer       string
+	Status       string
+	NameID       string
+	NotBefore    string
+	NotOnOrAfter string
+	Recipient    string
+	Audience     string
+}
+
+func newFixture() fixture {
+	now := time.Now().UTC()
+	return fixture{
+		ResponseID:   "id-response",
+		AssertionID:  "id-assertion",
+		IssueInstant: now.Format(timeFormat),
+		Destination:  "https://sp.example.com/v1/saml/acs",
+		InResponseTo: "id-request",
+		Issuer:       "https://idp.example.com",
+		Status:       statusSuccess,
+		NameID:       "alice",
+		NotBefore:    now.Add(-time.Minute).Format(timeFormat),
+		NotOnOrAfter: now.Add(5 * time.Minute).Format(timeFormat),
+		Recipient:    "https://sp.example.com/v1/saml/acs",
+		Audience:     "https://sp.example.com",
+	}
+}
+
+// response renders fixture, signs assertion or whole response
+// and applies tamper function to signed document.
+// It returns response encoded for HTTP-POST binding.
+func (f fixture) response(t *testing.T, signer *identity, signResponse bool, tamper func(root *etree.Element)) string {
+	t.Helper()
+	tmpl := template.Must(template.ParseFiles("testdata/response.xml"))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Root()
+
+	if signResponse {
+		root = signer.sign(t, root)
+		doc.SetRoot(root)
+	} else if signer != nil {
+		el := root.SelectElement("Assertion")
+		ctx, err := etreeutils.NSBuildParentContext(el)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, err = ctx.SubContext(el)
+		if err != nil {
+			t.Fatal(err)
+		}
+		detached, err := etreeutils.NSDetatch(ctx, el)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root.InsertChildAt(el.Index(), signer.sign(t, detached))
+		root.RemoveChild(el)
+	}
+
+	if tamper != nil {
+		tamper(root)
+	}
+
+	data, err := doc.WriteToBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func Test_serviceProvider_ParseResponse(t *testing.T) {
+	idp := newIdentity(t)
+	other := newIdentity(t)
+
+	sp, err := NewServiceProvider(Params{
+		EntityID:       "https://sp.example.com",
+		ACSURL:         "https://sp.example.com/v1/saml/acs",
+		IDPEntityID:    "https://idp.example.com",
+		IDPSSOURL:      "https://idp.example.com/sso",
+		IDPCertificate: idp.cert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := newFixture()
+
+	wrongAudience := newFixture()
+	wrongAudience.Audience = "https://other.example.com"
+
+	wrongIssuer := newFixture()
+	wrongIssuer.Issuer = "https://other.example.com"
+
+	wrongRecipient := newFixture()
+	wrongRecipient.Recipient = "https://other.example.com/acs"
+
+	expired := newFixture()
+	expired.NotBefore = time.Now().Add(-time.Hour).UTC().Format(timeFormat)
+	expired.NotOnOrAfter = time.Now().Add(-10 * time.Minute).UTC().Format(timeFormat)
+
+	notYetValid := newFixture()
+	notYetValid.NotBefore = time.Now().Add(10 * time.Minute).UTC().Format(timeFormat)
+
+	failed := newFixture()
+	failed.Status = "urn:oasis:names:tc:SAML:2.0:status:Requester"
+
+	tamperName := func(root *etree.Element) {
+		for _, el := range root.FindElements("//NameID") {
+			el.SetText("mallory")
+		}
+	}
+	wrapAssertion := func(root *etree.Element) {
+		el := root.SelectElement("Assertion").Copy()
+		el.CreateAttr("ID", "id-evil")
+		root.AddChild(el)
+	}
+
+	type args struct {
+		response  string
+		requestID string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{"SignedAssertion", args{valid.response(t, idp, false, nil), "id-request"}, nil},
+		{"SignedResponse", args{valid.response(t, idp, true, nil), "id-request"}, nil},
+		{"Unsigned", args{valid.response(t, nil, false, nil), "id-request"}, ErrSignatureInvalid},
+		{"UntrustedCertificate", args{valid.response(t, other, false, nil), "id-request"}, ErrSignatureInvalid},
+		{"TamperedAssertion", args{valid.response(t, idp, false, tamperName), "id-request"}, ErrSignatureInvalid},
+		{"TamperedResponse", args{valid.response(t, idp, true, tamperName), "id-request"}, ErrSignatureInvalid},
+		{"WrappedAssertion", args{valid.response(t, idp, false, wrapAssertion), "id-request"}, ErrResponseInvalid},
+		{"WrongRequestID", args{valid.response(t, idp, false, nil), "id-other"}, ErrSubjectInvalid},
+		{"Unsolicited", args{valid.response(t, idp, false, nil), ""}, ErrSubjectInvalid},
+		{"WrongAudience", args{wrongAudience.response(t, idp, false, nil), "id-request"}, ErrAudienceInvalid},
+		{"WrongIssuer", args{wrongIssuer.response(t, idp, false, nil), "id-request"}, ErrIssuerInvalid},
+		{"WrongRecipient", args{wrongRecipient.response(t, idp, false, nil), "id-request"}, ErrSubjectInvalid},
+		{"Expired", args{expired.response(t, idp, false, nil), "id-request"}, ErrConditionsFailed},
+		{"NotYetValid", args{notYetValid.response(t, idp, false, nil), "id-request"}, ErrConditionsFailed},
+		{"FailedStatus", args{failed.response(t, idp, false, nil), "id-request"}, ErrStatusInvalid},
+		{"InvalidEncoding", args{"%%%", "id-request"}, ErrResponseInvalid},
+		{"InvalidXML", args{base64.StdEncoding.EncodeToString([]byte("<Response")), "id-request"}, ErrResponseInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sp.ParseResponse(tt.args.response, tt.args.requestID)
+			if err != tt.wantErr {
+				t.Errorf("serviceProvider.ParseResponse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			want := map[string][]string{
+				"username": {"alice"},
+				"groups":   {"admins", "developers"},
+			}
+			if got.ID != "id-assertion" || got.NameID != "alice" || !reflect.DeepEqual(got.Attributes, want) {
+				t.Errorf("serviceProvider.ParseResponse() = %+v", got)
+			}
+			if !got.ExpiresAt.After(time.Now()) {
+				t.Errorf("serviceProvider.ParseResponse() ExpiresAt = %v", got.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestParseCertificate(t *testing.T) {
+	idp := newIdentity(t)
+	var pemData strings.Builder
+	pemData.WriteString("-----BEGIN CERTIFICATE-----\n")
+	pemData.WriteString(base64.StdEncoding.EncodeToString(idp.cert.Raw))
+	pemData.WriteString("\n-----END CERTIFICATE-----\n")
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"ValidData", []byte(pemData.String()), false},
+		{"InvalidData", []byte{0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCertificate(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCertificate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got.Equal(idp.cert) {
+				t.Errorf("ParseCertificate() = %v, want %v", got, idp.cert)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SecretSize is number of random bytes in generated secret.
+// It is equal to output size of HMAC-SHA1 as recommended by RFC 4226.
+const SecretSize = 20
+
+var ErrCodeInvalid = errors.New("code is invalid")
+
+// encoding is base32 encoding without padding used by authenticator applications.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Generator is interface implemented by types
+// that can generate and validate one-time passwords.
+type Generator interface {
+	// Secret generates a new random secret.
+	// It returns secret byte slice.
+	Secret() ([]byte, error)
+
+	// URI creates key URI used to enroll secret in authenticator application.
+	// It returns otpauth:// URI string.
+	URI(account string, secret []byte) string
+
+	// Code creates one-time password for time t.
+	// It returns code string.
+	Code(secret []byte, t time.Time) string
+
+	// Validate compares code with codes for time steps around time t.
+	// It returns counter of matched time step.
+	Validate(secret []byte, code string, t time.Time) (int64, error)
+}
+
+// generator implements Generator interface
+// using HMAC-SHA1 algorithm.
+type generator struct {
+	params Params
+}
+
+// NewGenerator validates params and creates a new generator.
+// It returns pointer to a generator instance or nil if params are invalid.
+func NewGenerator(params Params) (*generator, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &generator{params}, nil
+}
+
+// Secret generates a new random secret of SecretSize bytes.
+// It returns secret byte slice or nil if random source failed.
+func (g *generator) Secret() ([]byte, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// URI creates key URI in format of Google Authenticator.
+// It returns otpauth:// URI string.
+func (g *generator) URI(account string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", Encode(secret))
+	query.Set("issuer", g.params.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(g.params.Digits))
+	query.Set("period", strconv.Itoa(int(g.params.Period/time.Second)))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + g.params.Issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return uri.String()
+}
+
+// counter returns number of time steps since Unix epoch.
+func (g *generator) counter(t time.Time) int64 {
+	return t.Unix() / int64(g.params.Period/time.Second)
+}
+
+// hotp creates HMAC-based one-time password for counter as described in RFC 4226.
+func (g *generator) hotp(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < g.params.Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", g.params.Digits, value%mod)
+}
+
+// Code creates one-time password for time t.
+// It returns code string.
+func (g *generator) Code(secret []byte, t time.Time) string {
+	return g.hotp(secret, g.counter(t))
+}
+
+// Validate compares code with codes for time steps in range [-Skew,+Skew] around time t.
+// Caller must reject counters that were used before to prevent code replay.
+// It returns counter of matched time step or ErrCodeInvalid if nothing matched.
+func (g *generator) Validate(secret []byte, code string, t time.Time) (int64, error) {
+	if len(code) != g.params.Digits {
+		return 0, ErrCodeInvalid
+	}
+
+	current := g.counter(t)
+	for counter := current - int64(g.params.Skew); counter <= current+int64(g.params.Skew); counter++ {
+		if subtle.ConstantTimeCompare([]byte(g.hotp(secret, counter)), []byte(code)) == 1 {
+			return counter, nil
+		}
+	}
+
+	return 0, ErrCodeInvalid
+}
+
+// Encode encodes secret using base32 without padding.
+// It returns encoded secret string.
+func Encode(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
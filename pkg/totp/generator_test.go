@@ -0,0 +1,108 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+var rfcSecret = []byte("12345678901234567890")
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"ValidParams", Params{"auth", 6, 30 * time.Second, 1}, false},
+		{"EightDigits", Params{"auth", 8, 30 * time.Second, 0}, false},
+		{"EmptyIssuer", Params{"", 6, 30 * time.Second, 1}, true},
+		{"InvalidDigits", Params{"auth", 7, 30 * time.Second, 1}, true},
+		{"ZeroPeriod", Params{"auth", 6, 0, 1}, true},
+		{"FractionalPeriod", Params{"auth", 6, 1500 * time.Millisecond, 1}, true},
+		{"NegativeSkew", Params{"auth", 6, 30 * time.Second, -1}, true},
+		{"LargeSkew", Params{"auth", 6, 30 * time.Second, 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_generator_Code(t *testing.T) {
+	g, err := NewGenerator(Params{"auth", 8, 30 * time.Second, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// test vectors from RFC 6238 appendix B
+	tests := []struct {
+		name string
+		time int64
+		want string
+	}{
+		{"Time59", 59, "94287082"},
+		{"Time1111111109", 1111111109, "07081804"},
+		{"Time1111111111", 1111111111, "14050471"},
+		{"Time1234567890", 1234567890, "89005924"},
+		{"Time2000000000", 2000000000, "69279037"},
+		{"Time20000000000", 20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Code(rfcSecret, time.Unix(tt.time, 0)); got != tt.want {
+				t.Errorf("generator.Code() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generator_Validate(t *testing.T) {
+	g, err := NewGenerator(Params{"auth", 6, 30 * time.Second, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1111111111, 0)
+	counter := now.Unix() / 30
+
+	tests := []struct {
+		name    string
+		code    string
+		want    int64
+		wantErr bool
+	}{
+		{"CurrentStep", g.Code(rfcSecret, now), counter, false},
+		{"PreviousStep", g.Code(rfcSecret, now.Add(-30*time.Second)), counter - 1, false},
+		{"NextStep", g.Code(rfcSecret, now.Add(30*time.Second)), counter + 1, false},
+		{"OutsideSkew", g.Code(rfcSecret, now.Add(-60*time.Second)), 0, true},
+		{"WrongLength", "12345", 0, true},
+		{"WrongCode", "000000", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Validate(rfcSecret, tt.code, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("generator.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generator_URI(t *testing.T) {
+	g, err := NewGenerator(Params{"auth", 6, 30 * time.Second, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "otpauth://totp/auth:test?algorithm=SHA1&digits=6&issuer=auth&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	if got := g.URI("test", rfcSecret); got != want {
+		t.Errorf("generator.URI() = %v, want %v", got, want)
+	}
+}
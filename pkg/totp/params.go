@@ -0,0 +1,40 @@
+// Package totp provides structures to generate and validate
+// time-based one-time passwords described in RFC 6238.
+package totp
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrParamsInvalid = errors.New("params are invalid")
+
+// Params represents params of one-time passwords.
+// Issuer is shown by authenticator applications next to account name.
+// Skew is number of time steps before and after current one
+// codes of which are also accepted.
+type Params struct {
+	Issuer string
+	Digits int
+	Period time.Duration
+	Skew   int
+}
+
+// Validate checks that issuer is set, number of digits is 6 or 8,
+// period is whole number of seconds and skew is in range [0,2].
+// It returns error if at least one of parameters is invalid.
+func (p Params) Validate() error {
+	if p.Issuer == "" {
+		return ErrParamsInvalid
+	}
+	if p.Digits != 6 && p.Digits != 8 {
+		return ErrParamsInvalid
+	}
+	if p.Period < time.Second || p.Period%time.Second != 0 {
+		return ErrParamsInvalid
+	}
+	if p.Skew < 0 || p.Skew > 2 {
+		return ErrParamsInvalid
+	}
+	return nil
+}
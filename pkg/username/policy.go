@@ -0,0 +1,140 @@
+package username
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxLength is max number of characters in name.
+const maxLength = 64
+
+// compatibleScripts contains scripts that can be mixed with each other in one name.
+var compatibleScripts = map[string]bool{
+	"Han":      true,
+	"Hiragana": true,
+	"Katakana": true,
+}
+
+// Params represents params of username policy.
+// MinLength and MaxLength are numbers of characters in normalized name.
+// Scripts are names of Unicode scripts letters are allowed from, e.g. Latin or Cyrillic.
+// Reserved contains names that can't be used regardless of case.
+type Params struct {
+	MinLength int
+	MaxLength int
+	Scripts   []string
+	Reserved  []string
+}
+
+// Validate checks that lengths are in range [1,64]
+// and at least one script is set and all scripts are known.
+// It returns error if at least one of parameters is invalid.
+func (p Params) Validate() error {
+	if p.MinLength < 1 || p.MaxLength < p.MinLength || p.MaxLength > maxLength {
+		return ErrParamsInvalid
+	}
+	if len(p.Scripts) == 0 {
+		return ErrParamsInvalid
+	}
+	for _, script := range p.Scripts {
+		if _, ok := unicode.Scripts[script]; !ok {
+			return ErrParamsInvalid
+		}
+	}
+	return nil
+}
+
+// Policy is interface implemented by types
+// that can check names against set of rules.
+type Policy interface {
+	// Check checks normalized name against rules.
+	// It returns error describing the first failed rule or nil if name satisfies policy.
+	Check(name string) error
+}
+
+// policy implements Policy interface.
+type policy struct {
+	params   Params
+	scripts  map[string]*unicode.RangeTable
+	reserved map[string]bool
+}
+
+// NewPolicy validates params and creates a new policy.
+// It returns pointer to a policy instance or nil if params are invalid.
+func NewPolicy(params Params) (*policy, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	scripts := make(map[string]*unicode.RangeTable, len(params.Scripts))
+	for _, script := range params.Scripts {
+		scripts[script] = unicode.Scripts[script]
+	}
+
+	reserved := make(map[string]bool, len(params.Reserved))
+	for _, name := range params.Reserved {
+		if name != "" {
+			reserved[Fold(name)] = true
+		}
+	}
+
+	return &policy{params, scripts, reserved}, nil
+}
+
+// Check checks normalized name against rules.
+// Name can contain letters of allowed scripts with combining marks,
+// ASCII digits and underscores. Letters of different scripts can't be mixed
+// except Han, Hiragana and Katakana, so that names can't imitate other names.
+// It returns error describing the first failed rule or nil if name satisfies policy.
+func (p *policy) Check(name string) error {
+	name = Normalize(name)
+	if !utf8.ValidString(name) {
+		return ErrCharacters
+	}
+
+	if length := utf8.RuneCountInString(name); length < p.params.MinLength || length > p.params.MaxLength {
+		return ErrLength
+	}
+
+	var used string
+	for _, r := range name {
+		if r == '_' || (r >= '0' && r <= '9') {
+			continue
+		}
+
+		if unicode.IsMark(r) && unicode.Is(unicode.Inherited, r) {
+			continue
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+			return ErrCharacters
+		}
+
+		script := p.scriptOf(r)
+		if script == "" {
+			return ErrCharacters
+		}
+
+		if used != "" && used != script && !(compatibleScripts[used] && compatibleScripts[script]) {
+			return ErrMixedScripts
+		}
+		used = script
+	}
+
+	if p.reserved[Fold(name)] {
+		return ErrReserved
+	}
+
+	return nil
+}
+
+// scriptOf gets name of allowed script rune belongs to.
+// It returns empty string if script isn't allowed.
+func (p *policy) scriptOf(r rune) string {
+	for script, table := range p.scripts {
+		if unicode.Is(table, r) {
+			return script
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package username
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"ValidParams", Params{4, 20, []string{"Latin"}, []string{"admin"}}, false},
+		{"SeveralScripts", Params{4, 20, []string{"Latin", "Cyrillic"}, nil}, false},
+		{"ZeroMinLength", Params{0, 20, []string{"Latin"}, nil}, true},
+		{"MaxLessThanMin", Params{4, 3, []string{"Latin"}, nil}, true},
+		{"LargeMaxLength", Params{4, 65, []string{"Latin"}, nil}, true},
+		{"NoScripts", Params{4, 20, nil, nil}, true},
+		{"UnknownScript", Params{4, 20, []string{"Klingon"}, nil}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_policy_Check(t *testing.T) {
+	p, err := NewPolicy(Params{4, 20, []string{"Latin", "Cyrillic", "Han", "Hiragana"}, []string{"Admin", "root"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		want     error
+	}{
+		{"ASCII", "test_user1", nil},
+		{"Digits", "1234", nil},
+		{"Accented", "José_1", nil},
+		{"Decomposed", "José", nil},
+		{"Cyrillic", "Пользователь", nil},
+		{"Japanese", "ゆうた山田", nil},
+		{"Fullwidth", "ｔｅｓｔ", nil},
+		{"Short", "abc", ErrLength},
+		{"Long", "abcdefghijklmnopqrstu", ErrLength},
+		{"Space", "test user", ErrCharacters},
+		{"Symbol", "test$", ErrCharacters},
+		{"NotAllowedScript", "Ελληνικά", ErrCharacters},
+		{"MixedScripts", "pаypal", ErrMixedScripts},
+		{"Reserved", "admin", ErrReserved},
+		{"ReservedCase", "ROOT", ErrReserved},
+		{"ReservedFullwidth", "ａｄｍｉｎ", ErrReserved},
+		{"InvalidUTF8", "test\xff", ErrCharacters},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Check(tt.username); !errors.Is(err, tt.want) {
+				t.Errorf("policy.Check() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+	}{
+		{"Lower", "alice", "alice"},
+		{"Upper", "ALICE", "alice"},
+		{"Fullwidth", "Ａｌｉｃｅ", "alice"},
+		{"Decomposed", "José", "josé"},
+		{"SharpS", "Straße", "strasse"},
+		{"Cyrillic", "Иван", "иван"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fold(tt.username); got != tt.want {
+				t.Errorf("Fold() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+// Package username provides functions to normalize usernames
+// and structures to check them against set of rules.
+package username
+
+import (
+	"errors"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrParamsInvalid = errors.New("params are invalid")
+	ErrLength        = errors.New("name length is out of allowed range")
+	ErrCharacters    = errors.New("name contains disallowed characters")
+	ErrMixedScripts  = errors.New("name mixes letters of different scripts")
+	ErrReserved      = errors.New("name is reserved")
+)
+
+// Normalize converts name to Unicode NFKC form,
+// so that compatibility characters like fullwidth letters are replaced.
+// It returns name in the form it's stored and displayed.
+func Normalize(name string) string {
+	return norm.NFKC.String(name)
+}
+
+// Fold normalizes name and folds its case,
+// so that names differing only in case or compatibility characters are equal.
+// It returns name in the form it's compared.
+func Fold(name string) string {
+	return norm.NFKC.String(cases.Fold().String(norm.NFKC.String(name)))
+}
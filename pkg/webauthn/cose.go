@@ -0,0 +1,134 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	ErrKeyUnsupported   = errors.New("public key is unsupported")
+	ErrSignatureInvalid = errors.New("signature is invalid")
+)
+
+// COSE algorithms supported by relying party.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// COSE key types and curves.
+const (
+	ktyOKP     = 1
+	ktyEC2     = 2
+	ktyRSA     = 3
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// publicKey represents credential public key with its algorithm.
+type publicKey struct {
+	alg int
+	key crypto.PublicKey
+}
+
+// parsePublicKey parses public key encoded as COSE_Key.
+// It returns pointer to a publicKey instance
+// or nil if key is malformed or its algorithm isn't supported.
+func parsePublicKey(data []byte) (*publicKey, error) {
+	var params map[int]cbor.RawMessage
+	if err := cbor.Unmarshal(data, &params); err != nil {
+		return nil, ErrKeyUnsupported
+	}
+
+	var kty, alg int
+	if err := cbor.Unmarshal(params[1], &kty); err != nil {
+		return nil, ErrKeyUnsupported
+	}
+	if err := cbor.Unmarshal(params[3], &alg); err != nil {
+		return nil, ErrKeyUnsupported
+	}
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		var crv int
+		var x, y []byte
+		if cbor.Unmarshal(params[-1], &crv) != nil || cbor.Unmarshal(params[-2], &x) != nil || cbor.Unmarshal(params[-3], &y) != nil {
+			return nil, ErrKeyUnsupported
+		}
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrKeyUnsupported
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, ErrKeyUnsupported
+		}
+		return &publicKey{alg, key}, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		var n, e []byte
+		if cbor.Unmarshal(params[-1], &n) != nil || cbor.Unmarshal(params[-2], &e) != nil {
+			return nil, ErrKeyUnsupported
+		}
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrKeyUnsupported
+		}
+
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return &publicKey{alg, key}, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		var crv int
+		var x []byte
+		if cbor.Unmarshal(params[-1], &crv) != nil || cbor.Unmarshal(params[-2], &x) != nil {
+			return nil, ErrKeyUnsupported
+		}
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrKeyUnsupported
+		}
+		return &publicKey{alg, ed25519.PublicKey(x)}, nil
+	}
+
+	return nil, ErrKeyUnsupported
+}
+
+// verify verifies signature of message using key algorithm.
+// It returns nil if signature is valid.
+func (k *publicKey) verify(message []byte, sig []byte) error {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		if k.alg != AlgES256 {
+			return ErrKeyUnsupported
+		}
+		hash := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(key, hash[:], sig) {
+			return ErrSignatureInvalid
+		}
+	case *rsa.PublicKey:
+		if k.alg != AlgRS256 {
+			return ErrKeyUnsupported
+		}
+		hash := sha256.Sum256(message)
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) != nil {
+			return ErrSignatureInvalid
+		}
+	case ed25519.PublicKey:
+		if k.alg != AlgEdDSA {
+			return ErrKeyUnsupported
+		}
+		if !ed25519.Verify(key, message, sig) {
+			return ErrSignatureInvalid
+		}
+	default:
+		return ErrKeyUnsupported
+	}
+
+	return nil
+}
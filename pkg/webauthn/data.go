@@ -0,0 +1,183 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	ErrClientDataInvalid        = errors.New("client data is invalid")
+	ErrAuthenticatorDataInvalid = errors.New("authenticator data is invalid")
+	ErrUserNotVerified          = errors.New("user is not verified")
+)
+
+// flags of authenticator data.
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedData     = 0x40
+	flagExtensionPresent = 0x80
+)
+
+// client data types of ceremonies.
+const (
+	typeCreate = "webauthn.create"
+	typeGet    = "webauthn.get"
+)
+
+// Base64 represents binary data encoded using base64url without padding in JSON.
+type Base64 []byte
+
+// MarshalJSON encodes data to JSON string.
+func (b Base64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes data from JSON string.
+// Padding is accepted for compatibility with some clients.
+func (b *Base64) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// clientData represents collected client data signed by authenticator.
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// verifyClientData parses client data and compares its type,
+// challenge and origin with expected values.
+// It returns error if client data is malformed or doesn't match.
+func (r *relyingParty) verifyClientData(raw []byte, typ string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return ErrClientDataInvalid
+	}
+
+	if data.Type != typ {
+		return ErrClientDataInvalid
+	}
+
+	received, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(data.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return ErrClientDataInvalid
+	}
+
+	for _, origin := range r.params.Origins {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+
+	return ErrClientDataInvalid
+}
+
+// authenticatorData represents data produced by authenticator.
+// Credential ID and public key are set only if attested credential data is included.
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// parseAuthenticatorData parses authenticator data in binary format.
+// It returns pointer to an authenticatorData instance
+// or nil if data is malformed.
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrAuthenticatorDataInvalid
+	}
+
+	authData := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	rest := data[37:]
+	if authData.Flags&flagAttestedData != 0 {
+		// AAGUID (16 bytes) and credential ID length (2 bytes)
+		if len(rest) < 18 {
+			return nil, ErrAuthenticatorDataInvalid
+		}
+
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, ErrAuthenticatorDataInvalid
+		}
+		authData.CredentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		var key cbor.RawMessage
+		var err error
+		if rest, err = cbor.UnmarshalFirst(rest, &key); err != nil {
+			return nil, ErrAuthenticatorDataInvalid
+		}
+		authData.PublicKey = key
+	}
+
+	if authData.Flags&flagExtensionPresent != 0 {
+		var extensions cbor.RawMessage
+		var err error
+		if rest, err = cbor.UnmarshalFirst(rest, &extensions); err != nil {
+			return nil, ErrAuthenticatorDataInvalid
+		}
+	}
+
+	if len(rest) != 0 {
+		return nil, ErrAuthenticatorDataInvalid
+	}
+
+	return authData, nil
+}
+
+// verifyAuthenticatorData compares relying party ID hash with expected one
+// and checks that user was present and verified if verification is required.
+// It returns error if authenticator data doesn't match.
+func (r *relyingParty) verifyAuthenticatorData(data *authenticatorData, userVerification bool) error {
+	hash := sha256.Sum256([]byte(r.params.RPID))
+	if subtle.ConstantTimeCompare(data.RPIDHash, hash[:]) != 1 {
+		return ErrAuthenticatorDataInvalid
+	}
+
+	if data.Flags&flagUserPresent == 0 {
+		return ErrAuthenticatorDataInvalid
+	}
+
+	if userVerification && data.Flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+
+	return nil
+}
+
+// signedData concatenates authenticator data and hash of client data
+// as it is signed by authenticator.
+func signedData(authData []byte, clientDataJSON []byte) []byte {
+	hash := sha256.Sum256(clientDataJSON)
+	data := make([]byte, 0, len(authData)+len(hash))
+	data = append(data, authData...)
+	return append(data, hash[:]...)
+}
@@ -0,0 +1,33 @@
+// Package webauthn provides structures and functions to act as WebAuthn relying party:
+// build ceremony options, verify attestation and assertion responses.
+package webauthn
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrParamsInvalid = errors.New("params are invalid")
+
+// Params represents params of relying party.
+// RPID is effective domain credentials are scoped to,
+// Origins are origins of pages ceremonies can be performed on.
+type Params struct {
+	RPID    string
+	RPName  string
+	Origins []string
+	Timeout time.Duration
+}
+
+// Validate checks that relying party ID, name and origins are set
+// and timeout is at least one second.
+// It returns error if at least one of parameters is invalid.
+func (p Params) Validate() error {
+	if p.RPID == "" || p.RPName == "" || len(p.Origins) == 0 {
+		return ErrParamsInvalid
+	}
+	if p.Timeout < time.Second {
+		return ErrParamsInvalid
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ChallengeSize is number of random bytes in ceremony challenge.
+const ChallengeSize = 32
+
+var (
+	ErrAttestationInvalid     = errors.New("attestation is invalid")
+	ErrAttestationUnsupported = errors.New("attestation format is unsupported")
+	ErrCredentialInvalid      = errors.New("credential is invalid")
+	ErrCounterInvalid         = errors.New("sign counter is invalid")
+)
+
+// User represents user account credential is registered for.
+type User struct {
+	ID          []byte
+	Name        string
+	DisplayName string
+}
+
+// Credential represents registered public key credential.
+// PublicKey is encoded as COSE_Key.
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+}
+
+// CredentialDescriptor represents credential allowed or excluded in ceremony.
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   Base64 `json:"id"`
+}
+
+// RPEntity represents relying party in creation options.
+type RPEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity represents user in creation options.
+type UserEntity struct {
+	ID          Base64 `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameters represents credential type and algorithm
+// relying party accepts.
+type CredentialParameters struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// AuthenticatorSelection represents requirements to authenticator.
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions represents options of registration ceremony
+// passed to navigator.credentials.create() as publicKey.
+type CreationOptions struct {
+	Challenge              Base64                 `json:"challenge"`
+	RP                     RPEntity               `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameters `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions represents options of authentication ceremony
+// passed to navigator.credentials.get() as publicKey.
+type RequestOptions struct {
+	Challenge        Base64                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse represents public key credential
+// returned by navigator.credentials.create().
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    Base64 `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Base64 `json:"clientDataJSON"`
+		AttestationObject Base64 `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// AuthenticationResponse represents public key credential
+// returned by navigator.credentials.get().
+type AuthenticationResponse struct {
+	ID       string `json:"id"`
+	RawID    Base64 `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    Base64 `json:"clientDataJSON"`
+		AuthenticatorData Base64 `json:"authenticatorData"`
+		Signature         Base64 `json:"signature"`
+		UserHandle        Base64 `json:"userHandle"`
+	} `json:"response"`
+}
+
+// attestationObject represents attestation object returned by authenticator.
+type attestationObject struct {
+	Fmt      string                     `cbor:"fmt"`
+	AttStmt  map[string]cbor.RawMessage `cbor:"attStmt"`
+	AuthData []byte                     `cbor:"authData"`
+}
+
+// RelyingParty is interface implemented by types
+// that can perform WebAuthn ceremonies.
+type RelyingParty interface {
+	// Challenge generates a new random ceremony challenge.
+	// It returns challenge byte slice.
+	Challenge() ([]byte, error)
+
+	// CreationOptions creates options of registration ceremony for user.
+	// Excluded credentials cannot be registered again.
+	// It returns CreationOptions instance.
+	CreationOptions(user User, challenge []byte, exclude [][]byte) CreationOptions
+
+	// RequestOptions creates options of authentication ceremony.
+	// Any discoverable credential can be used if allow is empty.
+	// It returns RequestOptions instance.
+	RequestOptions(challenge []byte, allow [][]byte, userVerification bool) RequestOptions
+
+	// Register verifies response of registration ceremony.
+	// It returns pointer to a new Credential instance.
+	Register(challenge []byte, response RegistrationResponse) (*Credential, error)
+
+	// Login verifies response of authentication ceremony using stored credential.
+	// It returns new sign counter of credential.
+	Login(challenge []byte, credential Credential, response AuthenticationResponse, userVerification bool) (uint32, error)
+}
+
+// relyingParty implements RelyingParty interface.
+type relyingParty struct {
+	params Params
+}
+
+// NewRelyingParty validates params and creates a new relying party.
+// It returns pointer to a relyingParty instance or nil if params are invalid.
+func NewRelyingParty(params Params) (*relyingParty, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &relyingParty{params}, nil
+}
+
+// Challenge generates a new random challenge of ChallengeSize bytes.
+// It returns challenge byte slice or nil if random source failed.
+func (r *relyingParty) Challenge() ([]byte, error) {
+	challenge := make([]byte, ChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// descriptors creates credential descriptors by credential IDs.
+func descriptors(ids [][]byte) []CredentialDescriptor {
+	list := make([]CredentialDescriptor, len(ids))
+	for i, id := range ids {
+		list[i] = CredentialDescriptor{"public-key", id}
+	}
+	return list
+}
+
+// CreationOptions creates options of registration ceremony
+// preferring discoverable credentials, so that they can be used without username.
+// It returns CreationOptions instance.
+func (r *relyingParty) CreationOptions(user User, challenge []byte, exclude [][]byte) CreationOptions {
+	return CreationOptions{
+		Challenge: challenge,
+		RP:        RPEntity{r.params.RPID, r.params.RPName},
+		User:      UserEntity{user.ID, user.Name, user.DisplayName},
+		PubKeyCredParams: []CredentialParameters{
+			{"public-key", AlgES256},
+			{"public-key", AlgEdDSA},
+			{"public-key", AlgRS256},
+		},
+		Timeout:                r.params.Timeout.Milliseconds(),
+		ExcludeCredentials:     descriptors(exclude),
+		AuthenticatorSelection: AuthenticatorSelection{"preferred", "preferred"},
+		Attestation:            "none",
+	}
+}
+
+// RequestOptions creates options of authentication ceremony.
+// It returns RequestOptions instance.
+func (r *relyingParty) RequestOptions(challenge []byte, allow [][]byte, userVerification bool) RequestOptions {
+	verification := "preferred"
+	if userVerification {
+		verification = "required"
+	}
+
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          r.params.Timeout.Milliseconds(),
+		RPID:             r.params.RPID,
+		AllowCredentials: descriptors(allow),
+		UserVerification: verification,
+	}
+}
+
+// Register verifies client data, authenticator data and attestation statement
+// of registration response. Formats "none" and "packed" are supported.
+// It returns pointer to a new Credential instance or nil if response is invalid.
+func (r *relyingParty) Register(challenge []byte, response RegistrationResponse) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, ErrCredentialInvalid
+	}
+
+	if err := r.verifyClientData(response.Response.ClientDataJSON, typeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	var object attestationObject
+	if err := cbor.Unmarshal(response.Response.AttestationObject, &object); err != nil {
+		return nil, ErrAttestationInvalid
+	}
+
+	authData, err := parseAuthenticatorData(object.AuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.verifyAuthenticatorData(authData, false); err != nil {
+		return nil, err
+	}
+
+	if authData.CredentialID == nil || !bytes.Equal(authData.CredentialID, response.RawID) {
+		return nil, ErrCredentialInvalid
+	}
+
+	key, err := parsePublicKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyAttestation(object, key, response.Response.ClientDataJSON); err != nil {
+		return nil, err
+	}
+
+	return &Credential{authData.CredentialID, authData.PublicKey, authData.SignCount}, nil
+}
+
+// verifyAttestation verifies attestation statement of "none" or "packed" format.
+// Packed statement is verified with certificate key if certificate is present
+// or with credential key otherwise (self attestation).
+// Certificate chain isn't validated since attestation isn't requested.
+// It returns nil if statement is valid.
+func verifyAttestation(object attestationObject, key *publicKey, clientDataJSON []byte) error {
+	switch object.Fmt {
+	case "none":
+		if len(object.AttStmt) != 0 {
+			return ErrAttestationInvalid
+		}
+		return nil
+	case "packed":
+		var alg int
+		var sig []byte
+		if cbor.Unmarshal(object.AttStmt["alg"], &alg) != nil || cbor.Unmarshal(object.AttStmt["sig"], &sig) != nil {
+			return ErrAttestationInvalid
+		}
+
+		signer := key
+		if raw, ok := object.AttStmt["x5c"]; ok {
+			var chain [][]byte
+			if err := cbor.Unmarshal(raw, &chain); err != nil || len(chain) == 0 {
+				return ErrAttestationInvalid
+			}
+
+			cert, err := x509.ParseCertificate(chain[0])
+			if err != nil {
+				return ErrAttestationInvalid
+			}
+			signer = &publicKey{alg, cert.PublicKey}
+		} else if alg != key.alg {
+			return ErrAttestationInvalid
+		}
+
+		if err := signer.verify(signedData(object.AuthData, clientDataJSON), sig); err != nil {
+			return ErrAttestationInvalid
+		}
+		return nil
+	}
+
+	return ErrAttestationUnsupported
+}
+
+// Login verifies client data, authenticator data and signature of authentication response
+// using stored credential. Sign counter must increase unless authenticator doesn't support it,
+// otherwise credential may be cloned.
+// It returns new sign counter of credential.
+func (r *relyingParty) Login(challenge []byte, credential Credential, response AuthenticationResponse, userVerification bool) (uint32, error) {
+	if response.Type != "public-key" || !bytes.Equal(credential.ID, response.RawID) {
+		return 0, ErrCredentialInvalid
+	}
+
+	if err := r.verifyClientData(response.Response.ClientDataJSON, typeGet, challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := parseAuthenticatorData(response.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.verifyAuthenticatorData(authData, userVerification); err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := key.verify(signedData(response.Response.AuthenticatorData, response.Response.ClientDataJSON), response.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	if (authData.SignCount != 0 || credential.SignCount != 0) && authData.SignCount <= credential.SignCount {
+		return 0, ErrCounterInvalid
+	}
+
+	return authData.SignCount, nil
+}
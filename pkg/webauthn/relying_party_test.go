@@ -0,0 +1,276 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	testRPID   = "example.org"
+	testOrigin = "https://example.org"
+)
+
+var testParams = Params{testRPID, "Example", []string{testOrigin}, time.Minute}
+
+// authenticator represents software authenticator used to create fixtures.
+type authenticator struct {
+	t      *testing.T
+	id     []byte
+	signer crypto.Signer
+	count  uint32
+}
+
+// newAuthenticator creates authenticator with ES256 or EdDSA key.
+func newAuthenticator(t *testing.T, alg int) *authenticator {
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case AlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &authenticator{t, id, signer, 0}
+}
+
+func (a *authenticator) coseKey() []byte {
+	var key map[int]any
+	switch public := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		key = map[int]any{1: ktyEC2, 3: AlgES256, -1: crvP256, -2: public.X.FillBytes(make([]byte, 32)), -3: public.Y.FillBytes(make([]byte, 32))}
+	case ed25519.PublicKey:
+		key = map[int]any{1: ktyOKP, 3: AlgEdDSA, -1: crvEd25519, -2: []byte(public)}
+	}
+
+	data, err := cbor.Marshal(key)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return data
+}
+
+func (a *authenticator) alg() int {
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		return AlgEdDSA
+	}
+	return AlgES256
+}
+
+func (a *authenticator) sign(message []byte) []byte {
+	var sig []byte
+	var err error
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		sig, err = a.signer.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		hash := sha256.Sum256(message)
+		sig, err = a.signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return sig
+}
+
+func (a *authenticator) authData(rpID string, flags byte, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, hash[:]...)
+	if attested {
+		flags |= flagAttestedData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.count)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func clientDataJSON(typ string, challenge []byte, origin string) []byte {
+	data, _ := json.Marshal(clientData{typ, base64.RawURLEncoding.EncodeToString(challenge), origin})
+	return data
+}
+
+func (a *authenticator) register(typ string, challenge []byte, origin string, rpID string, format string) RegistrationResponse {
+	clientData := clientDataJSON(typ, challenge, origin)
+	authData := a.authData(rpID, flagUserPresent|flagUserVerified, true)
+
+	stmt := map[string]any{}
+	if format == "packed" {
+		stmt["alg"] = a.alg()
+		stmt["sig"] = a.sign(signedData(authData, clientData))
+	}
+
+	object, err := cbor.Marshal(map[string]any{"fmt": format, "attStmt": stmt, "authData": authData})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	var response RegistrationResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(a.id)
+	response.RawID = a.id
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = clientData
+	response.Response.AttestationObject = object
+	return response
+}
+
+func (a *authenticator) login(challenge []byte, origin string, flags byte) AuthenticationResponse {
+	clientData := clientDataJSON(typeGet, challenge, origin)
+	authData := a.authData(testRPID, flags, false)
+
+	var response AuthenticationResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(a.id)
+	response.RawID = a.id
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = clientData
+	response.Response.AuthenticatorData = authData
+	response.Response.Signature = a.sign(signedData(authData, clientData))
+	return response
+}
+
+func TestParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"ValidParams", testParams, false},
+		{"EmptyRPID", Params{"", "Example", []string{testOrigin}, time.Minute}, true},
+		{"EmptyRPName", Params{testRPID, "", []string{testOrigin}, time.Minute}, true},
+		{"EmptyOrigins", Params{testRPID, "Example", nil, time.Minute}, true},
+		{"ShortTimeout", Params{testRPID, "Example", []string{testOrigin}, time.Millisecond}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Params.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_relyingParty_Register(t *testing.T) {
+	rp, err := NewRelyingParty(testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	challenge, err := rp.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	es256 := newAuthenticator(t, AlgES256)
+	eddsa := newAuthenticator(t, AlgEdDSA)
+
+	mismatched := es256.register(typeCreate, challenge, testOrigin, testRPID, "none")
+	mismatched.RawID = eddsa.id
+
+	tests := []struct {
+		name     string
+		response RegistrationResponse
+		wantErr  error
+	}{
+		{"NoneES256", es256.register(typeCreate, challenge, testOrigin, testRPID, "none"), nil},
+		{"PackedES256", es256.register(typeCreate, challenge, testOrigin, testRPID, "packed"), nil},
+		{"PackedEdDSA", eddsa.register(typeCreate, challenge, testOrigin, testRPID, "packed"), nil},
+		{"WrongType", es256.register(typeGet, challenge, testOrigin, testRPID, "none"), ErrClientDataInvalid},
+		{"WrongChallenge", es256.register(typeCreate, []byte("challenge"), testOrigin, testRPID, "none"), ErrClientDataInvalid},
+		{"WrongOrigin", es256.register(typeCreate, challenge, "https://example.com", testRPID, "none"), ErrClientDataInvalid},
+		{"WrongRPID", es256.register(typeCreate, challenge, testOrigin, "example.com", "none"), ErrAuthenticatorDataInvalid},
+		{"MismatchedID", mismatched, ErrCredentialInvalid},
+		{"UnsupportedFormat", es256.register(typeCreate, challenge, testOrigin, testRPID, "fido-u2f"), ErrAttestationUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rp.Register(challenge, tt.response)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("relyingParty.Register() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && string(got.ID) != string(tt.response.RawID) {
+				t.Errorf("relyingParty.Register() ID = %v, want %v", got.ID, tt.response.RawID)
+			}
+		})
+	}
+}
+
+func Test_relyingParty_Login(t *testing.T) {
+	rp, err := NewRelyingParty(testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	challenge, err := rp.Challenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := newAuthenticator(t, AlgES256)
+	other := newAuthenticator(t, AlgES256)
+	other.id = a.id
+
+	credential, err := rp.Register(challenge, a.register(typeCreate, challenge, testOrigin, testRPID, "none"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.count = 5
+	credential.SignCount = 4
+	other.count = 5
+
+	type args struct {
+		response         AuthenticationResponse
+		signCount        uint32
+		userVerification bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    uint32
+		wantErr error
+	}{
+		{"ValidResponse", args{a.login(challenge, testOrigin, flagUserPresent|flagUserVerified), 4, true}, 5, nil},
+		{"NotVerified", args{a.login(challenge, testOrigin, flagUserPresent), 4, true}, 0, ErrUserNotVerified},
+		{"VerificationNotRequired", args{a.login(challenge, testOrigin, flagUserPresent), 4, false}, 5, nil},
+		{"NotPresent", args{a.login(challenge, testOrigin, flagUserVerified), 4, false}, 0, ErrAuthenticatorDataInvalid},
+		{"WrongChallenge", args{a.login([]byte("challenge"), testOrigin, flagUserPresent), 4, false}, 0, ErrClientDataInvalid},
+		{"WrongKey", args{other.login(challenge, testOrigin, flagUserPresent), 4, false}, 0, ErrSignatureInvalid},
+		{"CounterNotIncreased", args{a.login(challenge, testOrigin, flagUserPresent), 5, false}, 0, ErrCounterInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := *credential
+			stored.SignCount = tt.args.signCount
+			got, err := rp.Login(challenge, stored, tt.args.response, tt.args.userVerification)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("relyingParty.Login() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("relyingParty.Login() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}